@@ -9,13 +9,25 @@ import (
 )
 
 type Configs struct {
-	Service  ServiceConfigs
-	Database DatabaseConfigs
-	Redis    RedisConfigs
+	Service     ServiceConfigs
+	Database    DatabaseConfigs
+	Redis       RedisConfigs
+	Password    PasswordConfigs
+	Auth        AuthConfigs
+	Outbox      OutboxConfigs
+	Events      EventsConfigs
+	Webhooks    WebhooksConfigs
+	Pages       PagesConfigs
+	Search      SearchConfigs
+	Purge       PurgeConfigs
+	Idempotency IdempotencyConfigs
 }
 
 type ServiceConfigs struct {
-	Port string
+	Port     string
+	GRPCPort string `mapstructure:"grpc_port"`
+	// TrustedProxies are the addresses or CIDRs of the proxies the X-Forwarded-For header is taken from, none by default
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 type DatabaseConfigs struct {
@@ -27,6 +39,8 @@ type DatabaseConfigs struct {
 	Driver       string `mapstructure:"driver"`
 	RootUser     string `mapstructure:"root_user"`
 	RootPassword string `mapstructure:"root_pass"`
+	// AutoMigrate applies the pending migrations on start, otherwise the service refuses to start with any
+	AutoMigrate bool `mapstructure:"auto_migrate"`
 }
 
 type RedisConfigs struct {
@@ -37,6 +51,114 @@ type RedisConfigs struct {
 	WriteTimeout        int64    `mapstructure:"write_timeout_in_seconds"`
 }
 
+type PasswordConfigs struct {
+	Algorithm  string        `mapstructure:"algorithm"`
+	Argon2     Argon2Configs `mapstructure:"argon2"`
+	BcryptCost int           `mapstructure:"bcrypt_cost"`
+}
+
+type Argon2Configs struct {
+	Memory      uint32 `mapstructure:"memory_in_kib"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+type AuthConfigs struct {
+	// Algorithm is either RS256 or EdDSA
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKey     string `mapstructure:"private_key"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	Issuer         string `mapstructure:"issuer"`
+	TokenTTL       int64  `mapstructure:"token_ttl_in_seconds"`
+}
+
+type OutboxConfigs struct {
+	PollInterval int64 `mapstructure:"poll_interval_in_ms"`
+	BatchSize    int64 `mapstructure:"batch_size"`
+	MinBackoff   int64 `mapstructure:"min_backoff_in_ms"`
+	MaxBackoff   int64 `mapstructure:"max_backoff_in_seconds"`
+	Retention    int64 `mapstructure:"retention_in_hours"`
+	// MaxAttempts is the number of failed attempts after which an event is dead
+	MaxAttempts int `mapstructure:"max_attempts"`
+}
+
+type EventsConfigs struct {
+	// Sink is either redis, nats, webhook or file
+	Sink    string               `mapstructure:"sink"`
+	Redis   RedisEventsConfigs   `mapstructure:"redis"`
+	NATS    NATSEventsConfigs    `mapstructure:"nats"`
+	Webhook WebhookEventsConfigs `mapstructure:"webhook"`
+	File    FileEventsConfigs    `mapstructure:"file"`
+}
+
+type RedisEventsConfigs struct {
+	Stream string `mapstructure:"stream"`
+	MaxLen int64  `mapstructure:"max_len"`
+	// LegacyList keeps pushing the bare user IDs into the deprecated user-changes list
+	LegacyList bool `mapstructure:"legacy_list"`
+}
+
+type NATSEventsConfigs struct {
+	URL           string `mapstructure:"url"`
+	SubjectPrefix string `mapstructure:"subject_prefix"`
+	Timeout       int64  `mapstructure:"timeout_in_ms"`
+}
+
+type WebhookEventsConfigs struct {
+	URL     string `mapstructure:"url"`
+	Timeout int64  `mapstructure:"timeout_in_ms"`
+}
+
+type FileEventsConfigs struct {
+	Path string `mapstructure:"path"`
+}
+
+type WebhooksConfigs struct {
+	PollInterval int64 `mapstructure:"poll_interval_in_ms"`
+	BatchSize    int64 `mapstructure:"batch_size"`
+	Timeout      int64 `mapstructure:"timeout_in_ms"`
+	// MaxAttempts is the number of failed attempts after which a delivery is dead
+	MaxAttempts int   `mapstructure:"max_attempts"`
+	MinBackoff  int64 `mapstructure:"min_backoff_in_ms"`
+	MaxBackoff  int64 `mapstructure:"max_backoff_in_seconds"`
+	Retention   int64 `mapstructure:"retention_in_hours"`
+	// AllowPrivateNetworks lets the subscriptions post to loopback, link-local and private addresses
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
+
+type PagesConfigs struct {
+	// CursorSecret signs the cursors of the pages, it must be the same in all the replicas
+	CursorSecret string `mapstructure:"cursor_secret"`
+}
+
+type SearchConfigs struct {
+	// Path is the directory of the full-text index of the users on the local disk
+	Path string `mapstructure:"path"`
+	// PollInterval is how often every replica reads the new events in the outbox to update its index
+	PollInterval int64 `mapstructure:"poll_interval_in_ms"`
+	BatchSize    int64 `mapstructure:"batch_size"`
+	// Settle is how long an event is left in the outbox before it's indexed, it must be longer than the transactions writing the events
+	Settle int64 `mapstructure:"settle_in_seconds"`
+}
+
+type PurgeConfigs struct {
+	PollInterval int64 `mapstructure:"poll_interval_in_seconds"`
+	BatchSize    int64 `mapstructure:"batch_size"`
+	// Retention is how long the removed users can be restored before they are deleted for good, 0 keeps them forever
+	Retention int64 `mapstructure:"retention_in_hours"`
+}
+
+type IdempotencyConfigs struct {
+	// TTL is how long the response of a request is replayed for its retries with the same key
+	TTL int64 `mapstructure:"ttl_in_hours"`
+	// LockTTL is how long a request can hold its key, the concurrent requests with the same key wait for it at most this long
+	LockTTL int64 `mapstructure:"lock_ttl_in_seconds"`
+	// MaxBodySize is the largest body of a request with a key, the larger ones are rejected
+	MaxBodySize int64 `mapstructure:"max_body_size_in_bytes"`
+}
+
 func Init() *Configs {
 	_, b, _, _ := runtime.Caller(0)
 	basePath := filepath.Dir(b)
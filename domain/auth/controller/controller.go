@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"faceit/domain/auth/service"
+	"faceit/infrastructure/server"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IAuthController interface {
+	Login(c *gin.Context)
+	Authenticate(c *gin.Context)
+}
+
+type AuthController struct {
+	service service.IAuthService
+}
+
+// NewAuthController - Creates a new auth controller with dependency injection
+func NewAuthController(service service.IAuthService) *AuthController {
+	return &AuthController{service: service}
+}
+
+// RegisterRoutes - Sets up the http routes of the authentication
+func (a *AuthController) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1")
+	{
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/login", a.Login)
+		}
+	}
+}
+
+// Login - Handler to issue an access token for the user with the given credentials
+func (a *AuthController) Login(c *gin.Context) {
+	var request loginRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	token, err := a.service.Login(c.Request.Context(), request.Login, request.Password)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, token)
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"context"
+	"faceit/domain/auth/entity"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAuthenticate - gRPC interceptor which validates the bearer token in the authorization metadata and puts its principal into the context.
+// The calls without a token go through without a principal, the services reject them where a principal is needed.
+func (a *AuthController) UnaryAuthenticate(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return handler(ctx, request)
+	}
+
+	header := values[0]
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return nil, status.Error(codes.Unauthenticated, "the authorization metadata must be a bearer token")
+	}
+
+	principal, err := a.service.Authenticate(ctx, header[len(bearerPrefix):])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(entity.ContextWithPrincipal(ctx, principal), request)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"faceit/domain/auth/entity"
+	"faceit/domain/constants"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authenticate - Middleware which validates the bearer token of the request and puts its principal into the request context
+func (a *AuthController) Authenticate(c *gin.Context) {
+	header := c.GetHeader("Authorization")
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		c.Header("WWW-Authenticate", "Bearer")
+		_ = c.Error(constants.ErrUnauthenticated)
+		c.Abort()
+		return
+	}
+
+	principal, err := a.service.Authenticate(c.Request.Context(), header[len(bearerPrefix):])
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(entity.ContextWithPrincipal(c.Request.Context(), principal))
+	c.Next()
+}
@@ -0,0 +1,7 @@
+package controller
+
+type loginRequest struct {
+	// Login is either the email or the nickname of the user
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
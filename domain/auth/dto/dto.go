@@ -0,0 +1,11 @@
+package dto
+
+import (
+	"time"
+)
+
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
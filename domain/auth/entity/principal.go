@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"context"
+)
+
+// Principal - The authenticated user on whose behalf a request is made
+type Principal struct {
+	UserID   int64
+	NickName string
+	// Roles - The roles of the user when the token was issued, the permissions are checked against the current roles
+	Roles []string
+}
+
+// HasRole - Checks if the role is assigned to the principal
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal - Returns a copy of the context which carries the principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext - Returns the principal carried by the context, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
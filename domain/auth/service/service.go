@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"faceit/domain/auth/dto"
+	"faceit/domain/auth/entity"
+	"faceit/domain/auth/token"
+	"faceit/domain/constants"
+	rbacService "faceit/domain/rbac/service"
+	userService "faceit/domain/user/service"
+	"log"
+)
+
+const tokenTypeBearer = "Bearer"
+
+type IAuthService interface {
+	Login(ctx context.Context, login, password string) (*dto.Token, error)
+	Authenticate(ctx context.Context, accessToken string) (*entity.Principal, error)
+}
+
+type AuthService struct {
+	userService userService.IUserService
+	rbacService rbacService.IRBACService
+	tokens      token.IManager
+}
+
+func NewAuthService(userService userService.IUserService, rbacService rbacService.IRBACService, tokens token.IManager) *AuthService {
+	return &AuthService{userService: userService, rbacService: rbacService, tokens: tokens}
+}
+
+// Login - Verifies the credentials of the user and issues an access token for them.
+// The login can either be the email or the nickname of the user.
+func (a *AuthService) Login(ctx context.Context, login, password string) (*dto.Token, error) {
+	user, err := a.userService.Authenticate(ctx, login, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// the user is authenticated from here on, so they act on their own behalf to read their roles
+	ctx = entity.ContextWithPrincipal(ctx, &entity.Principal{UserID: user.ID, NickName: user.NickName})
+	roles, err := a.rbacService.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := a.tokens.Issue(user.ID, user.NickName, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.Token{
+		AccessToken: accessToken,
+		TokenType:   tokenTypeBearer,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+// Authenticate - Validates the access token and returns the principal it was issued for
+func (a *AuthService) Authenticate(ctx context.Context, accessToken string) (*entity.Principal, error) {
+	claims, err := a.tokens.Parse(accessToken)
+	if err != nil {
+		log.Printf("rejected access token: %s", err)
+		return nil, constants.ErrUnauthenticated
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		log.Printf("rejected access token: invalid subject %s", claims.Subject)
+		return nil, constants.ErrUnauthenticated
+	}
+
+	return &entity.Principal{
+		UserID:   userID,
+		NickName: claims.NickName,
+		Roles:    claims.Roles,
+	}, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/auth/dto"
+	"faceit/domain/auth/entity"
+	"faceit/domain/auth/token"
+	"faceit/domain/constants"
+	userDTO "faceit/domain/user/dto"
+	tokenMocks "faceit/mocks/domain/auth/token"
+	rbacMocks "faceit/mocks/domain/rbac/service"
+	userServiceMocks "faceit/mocks/domain/user/service"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+}
+
+func (s *ServiceTestSuite) TestLogin() {
+	expiresAt := time.Now().Add(time.Minute)
+	testCases := []struct {
+		login             string
+		password          string
+		authenticatedUser *userDTO.User
+		authenticateError error
+		roles             []string
+		expectedToken     *dto.Token
+		expectedError     error
+	}{
+		{
+			login:    "test@gmail.com",
+			password: "pass",
+			authenticatedUser: &userDTO.User{
+				ID:       1,
+				NickName: "test",
+				Email:    "test@gmail.com",
+			},
+			roles: []string{"admin"},
+			expectedToken: &dto.Token{
+				AccessToken: "token",
+				TokenType:   "Bearer",
+				ExpiresAt:   expiresAt,
+			},
+			expectedError: nil,
+		},
+		{
+			login:             "test",
+			password:          "wrong",
+			authenticateError: constants.ErrInvalidCredentials,
+			expectedToken:     nil,
+			expectedError:     constants.ErrInvalidCredentials,
+		},
+	}
+
+	userServiceMock := userServiceMocks.IUserService{}
+	rbacServiceMock := rbacMocks.IRBACService{}
+	tokenManagerMock := tokenMocks.IManager{}
+	for _, tc := range testCases {
+		userServiceMock.On("Authenticate", mock.Anything, tc.login, tc.password).Return(tc.authenticatedUser, tc.authenticateError)
+		if tc.authenticatedUser != nil {
+			// the roles are read on behalf of the authenticated user
+			rbacServiceMock.On("GetUserRoles", mock.MatchedBy(func(ctx context.Context) bool {
+				principal, ok := entity.PrincipalFromContext(ctx)
+				return ok && principal.UserID == tc.authenticatedUser.ID
+			}), tc.authenticatedUser.ID).Return(tc.roles, nil)
+			tokenManagerMock.On("Issue", tc.authenticatedUser.ID, tc.authenticatedUser.NickName, tc.roles).Return("token", expiresAt, nil)
+		}
+
+		authService := NewAuthService(&userServiceMock, &rbacServiceMock, &tokenManagerMock)
+		token, err := authService.Login(context.Background(), tc.login, tc.password)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedToken, token)
+	}
+}
+
+func (s *ServiceTestSuite) TestAuthenticate() {
+	testCases := []struct {
+		accessToken       string
+		claims            *token.Claims
+		parseError        error
+		expectedPrincipal *entity.Principal
+		expectedError     error
+	}{
+		{
+			accessToken: "valid",
+			claims: &token.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{Subject: "1"},
+				NickName:         "test",
+				Roles:            []string{"admin"},
+			},
+			expectedPrincipal: &entity.Principal{
+				UserID:   1,
+				NickName: "test",
+				Roles:    []string{"admin"},
+			},
+			expectedError: nil,
+		},
+		{
+			accessToken:       "expired",
+			parseError:        errors.New("token is expired"),
+			expectedPrincipal: nil,
+			expectedError:     constants.ErrUnauthenticated,
+		},
+		{
+			accessToken: "invalid-subject",
+			claims: &token.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{Subject: "test"},
+			},
+			expectedPrincipal: nil,
+			expectedError:     constants.ErrUnauthenticated,
+		},
+	}
+
+	tokenManagerMock := tokenMocks.IManager{}
+	for _, tc := range testCases {
+		tokenManagerMock.On("Parse", tc.accessToken).Return(tc.claims, tc.parseError)
+
+		authService := NewAuthService(&userServiceMocks.IUserService{}, &rbacMocks.IRBACService{}, &tokenManagerMock)
+		principal, err := authService.Authenticate(context.Background(), tc.accessToken)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedPrincipal, principal)
+	}
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
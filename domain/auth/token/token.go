@@ -0,0 +1,173 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Claims - The claims of the access tokens
+type Claims struct {
+	jwt.RegisteredClaims
+	NickName string   `json:"nick_name"`
+	Roles    []string `json:"roles"`
+}
+
+// UserID - Returns the ID of the user the token was issued for
+func (c *Claims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+// IManager - The interface for issuing and parsing access tokens
+type IManager interface {
+	Issue(userID int64, nickName string, roles []string) (string, time.Time, error)
+	Parse(accessToken string) (*Claims, error)
+}
+
+// Manager - Issues and parses JWT access tokens signed with an RSA or Ed25519 key
+type Manager struct {
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	issuer     string
+	ttl        time.Duration
+}
+
+// NewManager - Creates a new token manager with the PEM encoded private key.
+// If no key is given, an ephemeral one is generated, so the issued tokens are only valid until the service restarts.
+func NewManager(algorithm string, privateKeyPEM []byte, issuer string, ttl time.Duration) (*Manager, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("invalid access token ttl: %s", ttl)
+	}
+
+	var method jwt.SigningMethod
+	switch algorithm {
+	case AlgorithmRS256:
+		method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unknown token signing algorithm: %s", algorithm)
+	}
+
+	var privateKey crypto.Signer
+	var err error
+	if len(privateKeyPEM) == 0 {
+		log.Println("No private key is configured for signing the access tokens, using an ephemeral one")
+		privateKey, err = generatePrivateKey(algorithm)
+	} else {
+		privateKey, err = parsePrivateKey(algorithm, privateKeyPEM)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		method:     method,
+		privateKey: privateKey,
+		issuer:     issuer,
+		ttl:        ttl,
+	}, nil
+}
+
+// Issue - Issues a signed access token for the user and returns it with its expiry time
+func (m *Manager) Issue(userID int64, nickName string, roles []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.ttl)
+
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   strconv.FormatInt(userID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		NickName: nickName,
+		Roles:    roles,
+	}
+
+	accessToken, err := jwt.NewWithClaims(m.method, claims).SignedString(m.privateKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return accessToken, expiresAt, nil
+}
+
+// Parse - Verifies the signature and the validity of the access token and returns its claims
+func (m *Manager) Parse(accessToken string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		// only accept the configured algorithm, otherwise the token could choose how it is verified
+		if token.Method.Alg() != m.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing algorithm: %s", token.Method.Alg())
+		}
+		return m.privateKey.Public(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+
+	if !claims.VerifyIssuer(m.issuer, true) {
+		return nil, fmt.Errorf("invalid access token: unexpected issuer %s", claims.Issuer)
+	}
+
+	return claims, nil
+}
+
+func generatePrivateKey(algorithm string) (crypto.Signer, error) {
+	if algorithm == AlgorithmEdDSA {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	}
+
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+func parsePrivateKey(algorithm string, privateKeyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key: no PEM data found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %s", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if algorithm == AlgorithmRS256 {
+			return key, nil
+		}
+	case ed25519.PrivateKey:
+		if algorithm == AlgorithmEdDSA {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the private key can't be used with the %s algorithm", algorithm)
+}
@@ -0,0 +1,110 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TokenTestSuite struct {
+	suite.Suite
+}
+
+func (t *TokenTestSuite) TestIssueAndParse() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t.T(), err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t.T(), err)
+
+	testCases := []struct {
+		algorithm     string
+		privateKeyPEM []byte
+	}{
+		{algorithm: AlgorithmRS256, privateKeyPEM: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})},
+		{algorithm: AlgorithmRS256, privateKeyPEM: pkcs8PEM(t.T(), rsaKey)},
+		{algorithm: AlgorithmEdDSA, privateKeyPEM: pkcs8PEM(t.T(), ed25519Key)},
+		{algorithm: AlgorithmEdDSA, privateKeyPEM: nil},
+	}
+
+	for _, tc := range testCases {
+		manager, err := NewManager(tc.algorithm, tc.privateKeyPEM, "test", time.Minute)
+		assert.Nil(t.T(), err)
+
+		accessToken, expiresAt, err := manager.Issue(1, "test", []string{"admin"})
+		assert.Nil(t.T(), err)
+		assert.WithinDuration(t.T(), time.Now().Add(time.Minute), expiresAt, time.Second)
+
+		claims, err := manager.Parse(accessToken)
+		assert.Nil(t.T(), err)
+		userID, err := claims.UserID()
+		assert.Nil(t.T(), err)
+		assert.Equal(t.T(), int64(1), userID)
+		assert.Equal(t.T(), "test", claims.NickName)
+		assert.Equal(t.T(), []string{"admin"}, claims.Roles)
+		assert.Equal(t.T(), tc.algorithm, manager.method.Alg())
+	}
+}
+
+func (t *TokenTestSuite) TestParseInvalidToken() {
+	manager, err := NewManager(AlgorithmEdDSA, nil, "test", time.Minute)
+	assert.Nil(t.T(), err)
+	otherManager, err := NewManager(AlgorithmEdDSA, nil, "test", time.Minute)
+	assert.Nil(t.T(), err)
+	otherIssuerManager, err := NewManager(AlgorithmEdDSA, nil, "other", time.Minute)
+	assert.Nil(t.T(), err)
+	otherIssuerManager.privateKey = manager.privateKey
+	rsaManager, err := NewManager(AlgorithmRS256, nil, "test", time.Minute)
+	assert.Nil(t.T(), err)
+
+	signedByOtherKey, _, err := otherManager.Issue(1, "test", nil)
+	assert.Nil(t.T(), err)
+	signedByOtherIssuer, _, err := otherIssuerManager.Issue(1, "test", nil)
+	assert.Nil(t.T(), err)
+	signedWithOtherAlgorithm, _, err := rsaManager.Issue(1, "test", nil)
+	assert.Nil(t.T(), err)
+	manager.ttl = -time.Minute
+	expired, _, err := manager.Issue(1, "test", nil)
+	assert.Nil(t.T(), err)
+
+	for _, accessToken := range []string{"", "not-a-token", signedByOtherKey, signedByOtherIssuer, signedWithOtherAlgorithm, expired} {
+		claims, err := manager.Parse(accessToken)
+		assert.NotNil(t.T(), err)
+		assert.Nil(t.T(), claims)
+	}
+}
+
+func (t *TokenTestSuite) TestNewManager() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t.T(), err)
+
+	_, err = NewManager("HS256", nil, "test", time.Minute)
+	assert.NotNil(t.T(), err)
+
+	_, err = NewManager(AlgorithmEdDSA, nil, "test", 0)
+	assert.NotNil(t.T(), err)
+
+	_, err = NewManager(AlgorithmEdDSA, []byte("not a pem"), "test", time.Minute)
+	assert.NotNil(t.T(), err)
+
+	// the key doesn't match the algorithm
+	_, err = NewManager(AlgorithmEdDSA, pkcs8PEM(t.T(), rsaKey), "test", time.Minute)
+	assert.NotNil(t.T(), err)
+}
+
+func pkcs8PEM(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestTokenTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenTestSuite))
+}
@@ -1,9 +1,118 @@
 package constants
 
-import "fmt"
+import "strings"
+
+// Kind - The category of a domain error, the transports decide how to report an error by its kind
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindUnauthenticated
+	KindForbidden
+	// KindPreconditionFailed - A condition of the request, e.g. the If-Match header, doesn't hold
+	KindPreconditionFailed
+	// KindPreconditionRequired - The request must be conditional, e.g. an update without the version it's based on
+	KindPreconditionRequired
+	// KindTooLarge - The request is larger than the server accepts
+	KindTooLarge
+)
+
+// Error - A domain error with a stable machine-readable code for the clients.
+// Field is the field of the request which caused the error, if there is one.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is - An error of a field is the error of its code without a field, e.g. ErrEmailExists is ErrUserExists
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Field == "" && t.Kind == e.Kind && t.Code == e.Code
+}
+
+func newError(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func newFieldError(kind Kind, code, field, message string) *Error {
+	return &Error{Kind: kind, Code: code, Field: field, Message: message}
+}
 
 var (
-	ErrUserNotFound = fmt.Errorf("user not found")
-	ErrHasNoChanges = fmt.Errorf("the information has no changes")
-	ErrUserExists   = fmt.Errorf("user already exists")
+	ErrUserNotFound  = newError(KindNotFound, "user_not_found", "user not found")
+	ErrHasNoChanges  = newError(KindInvalid, "no_changes", "the information has no changes")
+	ErrUserExists    = newError(KindConflict, "user_exists", "user already exists")
+	ErrTooManyIDs    = newFieldError(KindInvalid, "too_many_ids", "ids", "too many user IDs are given at once")
+	ErrInvalidSort   = newFieldError(KindInvalid, "invalid_sort", "sort", "the users can only be sorted by id, nick_name, country, created_at and updated_at, each at most once")
+	ErrInvalidCursor = newFieldError(KindInvalid, "invalid_cursor", "cursor", "the cursor is invalid or belongs to another order")
+
+	ErrPasswordTooLong = newFieldError(KindInvalid, "password_too_long", "password", "the password must be at most 72 bytes")
+
+	ErrEmailExists    = newFieldError(KindConflict, "user_exists", "email", "a user with the email already exists")
+	ErrNickNameExists = newFieldError(KindConflict, "user_exists", "nick_name", "a user with the nickname already exists")
+
+	ErrVersionConflict      = newFieldError(KindConflict, "version_conflict", "version", "the user has been changed since the given version")
+	ErrPreconditionFailed   = newError(KindPreconditionFailed, "precondition_failed", "the user has been changed since the version in If-Match")
+	ErrPreconditionRequired = newError(KindPreconditionRequired, "precondition_required", "the version of the user must be given in If-Match or the version field")
+
+	ErrIdempotencyKeyReused = newFieldError(KindInvalid, "idempotency_key_reused", "Idempotency-Key", "the idempotency key is already used for another request")
+	ErrIdempotencyKeyInUse  = newError(KindConflict, "idempotency_key_in_use", "a request with the same idempotency key is in progress")
+	ErrRequestTooLarge      = newError(KindTooLarge, "request_too_large", "the body of the request is too large")
+
+	ErrInvalidCredentials = newError(KindUnauthenticated, "invalid_credentials", "invalid credentials")
+	ErrUnauthenticated    = newError(KindUnauthenticated, "unauthenticated", "authentication required")
+	ErrForbidden          = newError(KindForbidden, "forbidden", "permission denied")
+
+	ErrRoleNotFound       = newError(KindNotFound, "role_not_found", "role not found")
+	ErrRoleExists         = newError(KindConflict, "role_exists", "role already exists")
+	ErrRoleNotAssigned    = newError(KindNotFound, "role_not_assigned", "role is not assigned to the user")
+	ErrPermissionNotFound = newFieldError(KindInvalid, "permission_not_found", "permissions", "permission not found")
+
+	ErrWebhookNotFound       = newError(KindNotFound, "webhook_not_found", "webhook subscription not found")
+	ErrInvalidWebhookURL     = newFieldError(KindInvalid, "invalid_webhook_url", "url", "the webhook url must be an absolute http or https url")
+	ErrPrivateWebhookURL     = newFieldError(KindInvalid, "private_webhook_url", "url", "the webhook url must not be a loopback, link-local or private address")
+	ErrInvalidEventType      = newFieldError(KindInvalid, "invalid_event_type", "event_types", "unknown event type")
+	ErrWebhookSecretTooShort = newFieldError(KindInvalid, "webhook_secret_too_short", "secret", "the webhook secret must be at least 16 characters")
+	ErrInvalidDeliveryStatus = newFieldError(KindInvalid, "invalid_delivery_status", "status", "unknown delivery status")
+	ErrDeadDeliveryNotFound  = newError(KindNotFound, "dead_delivery_not_found", "dead webhook delivery not found")
 )
+
+// FieldError - A problem with a field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError - The request couldn't be read, or its fields are invalid
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+// NewValidationError - Creates a validation error with the problems of the fields
+func NewValidationError(message string, fields ...FieldError) *ValidationError {
+	return &ValidationError{Message: message, Fields: fields}
+}
+
+func (v *ValidationError) Error() string {
+	if len(v.Fields) == 0 {
+		return v.Message
+	}
+
+	fields := make([]string, 0, len(v.Fields))
+	for _, field := range v.Fields {
+		fields = append(fields, field.Field+" "+field.Message)
+	}
+
+	return v.Message + ": " + strings.Join(fields, ", ")
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"faceit/domain/rbac/dto"
+	"faceit/domain/rbac/entity"
+	"faceit/domain/rbac/service"
+	"faceit/infrastructure/server"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IRBACController interface {
+	GetRoles(c *gin.Context)
+	CreateRole(c *gin.Context)
+	RemoveRole(c *gin.Context)
+	GetPermissions(c *gin.Context)
+	GetUserRoles(c *gin.Context)
+	AssignRole(c *gin.Context)
+	RevokeRole(c *gin.Context)
+}
+
+type RBACController struct {
+	service      service.IRBACService
+	authorizer   service.IAuthorizer
+	authenticate gin.HandlerFunc
+}
+
+// NewRBACController - Creates a new RBAC controller with dependency injection
+func NewRBACController(service service.IRBACService, authorizer service.IAuthorizer, authenticate gin.HandlerFunc) *RBACController {
+	return &RBACController{service: service, authorizer: authorizer, authenticate: authenticate}
+}
+
+// RegisterRoutes - Sets up the http routes to manage the roles
+func (r *RBACController) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/v1", r.authenticate)
+	{
+		manage := r.RequirePermission(entity.PermissionRolesManage)
+
+		roles := v1.Group("/roles", manage)
+		{
+			roles.GET("", r.GetRoles)
+			roles.POST("", r.CreateRole)
+			roles.DELETE("/:name", r.RemoveRole)
+		}
+
+		v1.GET("/permissions", manage, r.GetPermissions)
+
+		user := v1.Group("/users/:id/roles")
+		{
+			user.GET("", r.GetUserRoles)
+			user.PUT("/:role", manage, r.AssignRole)
+			user.DELETE("/:role", manage, r.RevokeRole)
+		}
+	}
+}
+
+// GetRoles - Handler to list the roles with their permissions
+func (r *RBACController) GetRoles(c *gin.Context) {
+	roles, err := r.service.GetRoles(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, roles)
+}
+
+// CreateRole - Handler to create a role with the given permissions
+func (r *RBACController) CreateRole(c *gin.Context) {
+	var request createRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	role, err := r.service.CreateRole(c.Request.Context(), &dto.Role{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: request.Permissions,
+	})
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusCreated, role)
+}
+
+// RemoveRole - Handler to remove a role and revoke it from all the users
+func (r *RBACController) RemoveRole(c *gin.Context) {
+	if err := r.service.RemoveRole(c.Request.Context(), c.Param("name")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, nil)
+}
+
+// GetPermissions - Handler to list the permissions which can be granted to the roles
+func (r *RBACController) GetPermissions(c *gin.Context) {
+	permissions, err := r.service.GetPermissions(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, permissions)
+}
+
+// GetUserRoles - Handler to list the roles assigned to the user
+func (r *RBACController) GetUserRoles(c *gin.Context) {
+	userID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	roles, err := r.service.GetUserRoles(c.Request.Context(), userID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, roles)
+}
+
+// AssignRole - Handler to assign the role to the user
+func (r *RBACController) AssignRole(c *gin.Context) {
+	userID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := r.service.AssignRole(c.Request.Context(), userID, c.Param("role")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, nil)
+}
+
+// RevokeRole - Handler to revoke the role from the user
+func (r *RBACController) RevokeRole(c *gin.Context) {
+	userID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := r.service.RevokeRole(c.Request.Context(), userID, c.Param("role")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, nil)
+}
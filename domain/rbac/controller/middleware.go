@@ -0,0 +1,17 @@
+package controller
+
+import "github.com/gin-gonic/gin"
+
+// RequirePermission - Middleware which only lets the requests of the principals with the permission through.
+// It must be applied after the authentication middleware.
+func (r *RBACController) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := r.authorizer.Authorize(c.Request.Context(), permission); err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
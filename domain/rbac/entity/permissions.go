@@ -0,0 +1,14 @@
+package entity
+
+// The permissions checked by the services, they are seeded in the permissions table by the migrations
+const (
+	PermissionUsersRead    = "users:read"
+	PermissionUsersReadPII = "users:read:pii"
+	PermissionUsersUpdate  = "users:update"
+	PermissionUsersDelete  = "users:delete"
+	PermissionUsersRestore = "users:restore"
+	PermissionUsersAudit   = "users:audit"
+	PermissionRolesManage  = "roles:manage"
+
+	PermissionWebhooksManage = "webhooks:manage"
+)
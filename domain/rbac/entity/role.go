@@ -0,0 +1,12 @@
+package entity
+
+type Role struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+type Permission struct {
+	Name        string
+	Description string
+}
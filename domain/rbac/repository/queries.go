@@ -0,0 +1,36 @@
+package repository
+
+const (
+	rolesTableName           = "roles"
+	permissionsTableName     = "permissions"
+	rolePermissionsTableName = "role_permissions"
+	userRolesTableName       = "user_roles"
+	usersTableName           = "users"
+)
+
+const (
+	getRoles = `SELECT r.name, r.description, rp.permission FROM ` + rolesTableName + ` r LEFT JOIN ` + rolePermissionsTableName + ` rp ON rp.role = r.name ORDER BY r.name, rp.permission`
+
+	getRole = `SELECT r.name, r.description, rp.permission FROM ` + rolesTableName + ` r LEFT JOIN ` + rolePermissionsTableName + ` rp ON rp.role = r.name WHERE r.name = ? ORDER BY rp.permission`
+
+	createRole = `INSERT INTO ` + rolesTableName + ` SET name = ?, description = ?`
+
+	createRolePermission = `INSERT INTO ` + rolePermissionsTableName + ` SET role = ?, permission = ?`
+
+	deleteRole = `DELETE FROM ` + rolesTableName + ` WHERE name = ?`
+
+	deleteRoleAssignments = `DELETE FROM ` + userRolesTableName + ` WHERE role = ?`
+
+	getPermissions = `SELECT name, description FROM ` + permissionsTableName + ` ORDER BY name`
+
+	// assignRole - IGNORE would turn the violated foreign keys into warnings as well, so only the duplicate is skipped
+	assignRole = `INSERT INTO ` + userRolesTableName + ` SET user_id = ?, role = ? ON DUPLICATE KEY UPDATE role = role`
+
+	revokeRole = `DELETE FROM ` + userRolesTableName + ` WHERE user_id = ? AND role = ?`
+
+	// getUserRoles - a removed user has no roles until it's restored, so its access tokens don't grant anything
+	getUserRoles = `SELECT ur.role FROM ` + userRolesTableName + ` ur JOIN ` + usersTableName + ` u ON u.id = ur.user_id WHERE ur.user_id = ? AND u.deleted_at IS NULL ORDER BY ur.role`
+)
+
+// hasPermission - the IN clause is completed with a placeholder for each role
+const hasPermission = `SELECT count(*) FROM ` + rolePermissionsTableName + ` WHERE permission = ? AND role IN `
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/rbac/entity"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// mysqlDuplicateEntry - The number of the MySQL error of a violated unique key
+	mysqlDuplicateEntry = 1062
+	// mysqlNoReferencedRow - The number of the MySQL error of a violated foreign key, i.e. the user or the role doesn't exist
+	mysqlNoReferencedRow = 1452
+)
+
+type IRolesRepository interface {
+	GetRoles(ctx context.Context) ([]*entity.Role, error)
+	GetRole(ctx context.Context, name string) (*entity.Role, error)
+	CreateRole(ctx context.Context, role *entity.Role) error
+	RemoveRole(ctx context.Context, name string) error
+	GetPermissions(ctx context.Context) ([]*entity.Permission, error)
+	AssignRole(ctx context.Context, userID int64, role string) error
+	RevokeRole(ctx context.Context, userID int64, role string) error
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	HasPermission(ctx context.Context, roles []string, permission string) (bool, error)
+}
+
+type RolesRepository struct {
+	db *sql.DB
+}
+
+func NewRolesRepository(db *sql.DB) *RolesRepository {
+	return &RolesRepository{db: db}
+}
+
+// GetRoles - gets all the roles with their permissions
+func (r *RolesRepository) GetRoles(ctx context.Context) ([]*entity.Role, error) {
+	results, err := r.db.QueryContext(ctx, getRoles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	return scanRoles(results)
+}
+
+// GetRole - gets the role with the given name and its permissions
+func (r *RolesRepository) GetRole(ctx context.Context, name string) (*entity.Role, error) {
+	results, err := r.db.QueryContext(ctx, getRole, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	roles, err := scanRoles(results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(roles) == 0 {
+		return nil, constants.ErrRoleNotFound
+	}
+
+	return roles[0], nil
+}
+
+// CreateRole - creates the role with its permissions, it returns constants.ErrRoleExists if the name is taken
+func (r *RolesRepository) CreateRole(ctx context.Context, role *entity.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	if _, err := tx.ExecContext(ctx, createRole, role.Name, role.Description); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+			return constants.ErrRoleExists
+		}
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	for _, permission := range role.Permissions {
+		if _, err := tx.ExecContext(ctx, createRolePermission, role.Name, permission); err != nil {
+			return fmt.Errorf("failed to grant permission to role: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveRole - removes the role and revokes it from all the users
+func (r *RolesRepository) RemoveRole(ctx context.Context, name string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	if _, err := tx.ExecContext(ctx, deleteRoleAssignments, name); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, deleteRole, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		return constants.ErrRoleNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetPermissions - gets all the permissions which can be granted to the roles
+func (r *RolesRepository) GetPermissions(ctx context.Context) ([]*entity.Permission, error) {
+	results, err := r.db.QueryContext(ctx, getPermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var permissions []*entity.Permission
+	for results.Next() {
+		permission := new(entity.Permission)
+		if err := results.Scan(&permission.Name, &permission.Description); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
+// AssignRole - assigns the role to the user, assigning a role twice has no effect.
+// It returns constants.ErrUserNotFound or constants.ErrRoleNotFound if the user or the role doesn't exist.
+func (r *RolesRepository) AssignRole(ctx context.Context, userID int64, role string) error {
+	if _, err := r.db.ExecContext(ctx, assignRole, userID, role); err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlNoReferencedRow {
+			// the message names the foreign key which is violated
+			if strings.Contains(mysqlErr.Message, "FOREIGN KEY (`role`)") {
+				return constants.ErrRoleNotFound
+			}
+			return constants.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeRole - revokes the role from the user
+func (r *RolesRepository) RevokeRole(ctx context.Context, userID int64, role string) error {
+	result, err := r.db.ExecContext(ctx, revokeRole, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		return constants.ErrRoleNotAssigned
+	}
+
+	return nil
+}
+
+// GetUserRoles - gets the names of the roles assigned to the user, none if the user is removed
+func (r *RolesRepository) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	results, err := r.db.QueryContext(ctx, getUserRoles, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var roles []string
+	for results.Next() {
+		var role string
+		if err := results.Scan(&role); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+// HasPermission - checks if the permission is granted to any of the roles
+func (r *RolesRepository) HasPermission(ctx context.Context, roles []string, permission string) (bool, error) {
+	if len(roles) == 0 {
+		return false, nil
+	}
+
+	args := make([]interface{}, 0, len(roles)+1)
+	args = append(args, permission)
+	for _, role := range roles {
+		args = append(args, role)
+	}
+	query := hasPermission + "(" + strings.TrimSuffix(strings.Repeat("?, ", len(roles)), ", ") + ")"
+
+	var count uint64
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check permission: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// scanRoles - groups the rows of the roles joined with their permissions by role
+func scanRoles(results *sql.Rows) ([]*entity.Role, error) {
+	var roles []*entity.Role
+	for results.Next() {
+		var name, description string
+		var permission sql.NullString
+		if err := results.Scan(&name, &description, &permission); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		if len(roles) == 0 || roles[len(roles)-1].Name != name {
+			roles = append(roles, &entity.Role{Name: name, Description: description})
+		}
+
+		if permission.Valid {
+			role := roles[len(roles)-1]
+			role.Permissions = append(role.Permissions, permission.String)
+		}
+	}
+
+	return roles, nil
+}
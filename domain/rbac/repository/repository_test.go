@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"faceit/domain/constants"
+	"faceit/domain/rbac/entity"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+func (r *RepositoryTestSuite) TestGetRoles() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	rows := r.mock.NewRows([]string{"name", "description", "permission"}).
+		AddRow("admin", "Manages everyone", "roles:manage").
+		AddRow("admin", "Manages everyone", "users:read").
+		AddRow("empty", "", nil).
+		AddRow("support", "Supports the users", "users:read")
+	r.mock.ExpectQuery("SELECT r.name, r.description, rp.permission FROM roles r LEFT JOIN role_permissions rp").
+		WillReturnRows(rows)
+
+	roles, err := rolesRepository.GetRoles(context.Background())
+	assert.Nil(r.T(), err)
+	assert.Equal(r.T(), []*entity.Role{
+		{Name: "admin", Description: "Manages everyone", Permissions: []string{"roles:manage", "users:read"}},
+		{Name: "empty", Description: ""},
+		{Name: "support", Description: "Supports the users", Permissions: []string{"users:read"}},
+	}, roles)
+}
+
+func (r *RepositoryTestSuite) TestGetRole() {
+	testCases := []struct {
+		name          string
+		rows          *sqlmock.Rows
+		expectedRole  *entity.Role
+		expectedError error
+	}{
+		{
+			name: "support",
+			rows: sqlmock.NewRows([]string{"name", "description", "permission"}).
+				AddRow("support", "Supports the users", "users:read"),
+			expectedRole:  &entity.Role{Name: "support", Description: "Supports the users", Permissions: []string{"users:read"}},
+			expectedError: nil,
+		},
+		{
+			name:          "unknown",
+			rows:          sqlmock.NewRows([]string{"name", "description", "permission"}),
+			expectedRole:  nil,
+			expectedError: constants.ErrRoleNotFound,
+		},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectQuery("SELECT r.name, r.description, rp.permission FROM roles r").
+			WithArgs(tc.name).
+			WillReturnRows(tc.rows)
+		role, err := rolesRepository.GetRole(context.Background(), tc.name)
+		assert.Equal(r.T(), tc.expectedError, err)
+		assert.Equal(r.T(), tc.expectedRole, role)
+	}
+}
+
+func (r *RepositoryTestSuite) TestCreateRole() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	role := &entity.Role{Name: "support", Description: "Supports the users", Permissions: []string{"users:read", "users:update"}}
+	r.mock.ExpectBegin()
+	r.mock.ExpectExec("INSERT INTO roles").
+		WithArgs(role.Name, role.Description).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, permission := range role.Permissions {
+		r.mock.ExpectExec("INSERT INTO role_permissions").
+			WithArgs(role.Name, permission).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	r.mock.ExpectCommit()
+
+	err := rolesRepository.CreateRole(context.Background(), role)
+	assert.Nil(r.T(), err)
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func (r *RepositoryTestSuite) TestCreateRoleExists() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	// a concurrent request has created the role
+	r.mock.ExpectBegin()
+	r.mock.ExpectExec("INSERT INTO roles").
+		WithArgs("support", "").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'support' for key 'roles.PRIMARY'"})
+	r.mock.ExpectRollback()
+
+	err := rolesRepository.CreateRole(context.Background(), &entity.Role{Name: "support"})
+	assert.Equal(r.T(), constants.ErrRoleExists, err)
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func (r *RepositoryTestSuite) TestRemoveRole() {
+	testCases := []struct {
+		name          string
+		rowsAffected  int64
+		expectedError error
+	}{
+		{name: "support", rowsAffected: 1, expectedError: nil},
+		{name: "unknown", rowsAffected: 0, expectedError: constants.ErrRoleNotFound},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectBegin()
+		r.mock.ExpectExec("DELETE FROM user_roles").
+			WithArgs(tc.name).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		r.mock.ExpectExec("DELETE FROM roles").
+			WithArgs(tc.name).
+			WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+		if tc.expectedError == nil {
+			r.mock.ExpectCommit()
+		} else {
+			r.mock.ExpectRollback()
+		}
+
+		err := rolesRepository.RemoveRole(context.Background(), tc.name)
+		assert.Equal(r.T(), tc.expectedError, err)
+	}
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func (r *RepositoryTestSuite) TestAssignAndRevokeRole() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	r.mock.ExpectExec("INSERT INTO user_roles").
+		WithArgs(int64(1), "support").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err := rolesRepository.AssignRole(context.Background(), 1, "support")
+	assert.Nil(r.T(), err)
+
+	// the user or the role is removed by the time the role is assigned
+	r.mock.ExpectExec("INSERT INTO user_roles").
+		WithArgs(int64(2), "support").
+		WillReturnError(&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails " +
+			"(`users`.`user_roles`, CONSTRAINT `user_roles_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE)"})
+	err = rolesRepository.AssignRole(context.Background(), 2, "support")
+	assert.Equal(r.T(), constants.ErrUserNotFound, err)
+
+	r.mock.ExpectExec("INSERT INTO user_roles").
+		WithArgs(int64(1), "support").
+		WillReturnError(&mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails " +
+			"(`users`.`user_roles`, CONSTRAINT `user_roles_ibfk_2` FOREIGN KEY (`role`) REFERENCES `roles` (`name`) ON DELETE CASCADE)"})
+	err = rolesRepository.AssignRole(context.Background(), 1, "support")
+	assert.Equal(r.T(), constants.ErrRoleNotFound, err)
+
+	r.mock.ExpectExec("DELETE FROM user_roles").
+		WithArgs(int64(1), "support").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err = rolesRepository.RevokeRole(context.Background(), 1, "support")
+	assert.Nil(r.T(), err)
+
+	r.mock.ExpectExec("DELETE FROM user_roles").
+		WithArgs(int64(1), "support").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err = rolesRepository.RevokeRole(context.Background(), 1, "support")
+	assert.Equal(r.T(), constants.ErrRoleNotAssigned, err)
+}
+
+func (r *RepositoryTestSuite) TestGetUserRoles() {
+	testCases := []struct {
+		rows          *sqlmock.Rows
+		expectedRoles []string
+	}{
+		{rows: sqlmock.NewRows([]string{"role"}).AddRow("admin").AddRow("support"), expectedRoles: []string{"admin", "support"}},
+		// the roles of a removed user are left out by the query
+		{rows: sqlmock.NewRows([]string{"role"}), expectedRoles: nil},
+	}
+
+	for _, tc := range testCases {
+		r.db, r.mock = databaseMocks.NewDBMock()
+		rolesRepository := NewRolesRepository(r.db)
+
+		r.mock.ExpectQuery(regexp.QuoteMeta(getUserRoles)).
+			WithArgs(int64(1)).
+			WillReturnRows(tc.rows)
+		roles, err := rolesRepository.GetUserRoles(context.Background(), 1)
+		assert.Nil(r.T(), err)
+		assert.Equal(r.T(), tc.expectedRoles, roles)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+	}
+	assert.Contains(r.T(), getUserRoles, "u.deleted_at IS NULL")
+}
+
+func (r *RepositoryTestSuite) TestHasPermission() {
+	testCases := []struct {
+		roles         []string
+		permission    string
+		count         uint64
+		expectedQuery bool
+		expected      bool
+	}{
+		{roles: []string{"admin", "support"}, permission: "users:read", count: 2, expectedQuery: true, expected: true},
+		{roles: []string{"support"}, permission: "roles:manage", count: 0, expectedQuery: true, expected: false},
+		{roles: nil, permission: "users:read", expectedQuery: false, expected: false},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	rolesRepository := NewRolesRepository(r.db)
+
+	for _, tc := range testCases {
+		if tc.expectedQuery {
+			args := []driver.Value{tc.permission}
+			for _, role := range tc.roles {
+				args = append(args, role)
+			}
+			r.mock.ExpectQuery("SELECT count\\(\\*\\) FROM role_permissions WHERE permission = \\? AND role IN \\(\\?(, \\?)*\\)").
+				WithArgs(args...).
+				WillReturnRows(r.mock.NewRows([]string{"count"}).AddRow(tc.count))
+		}
+
+		granted, err := rolesRepository.HasPermission(context.Background(), tc.roles, tc.permission)
+		assert.Nil(r.T(), err)
+		assert.Equal(r.T(), tc.expected, granted)
+	}
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func TestRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
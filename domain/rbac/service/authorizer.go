@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	authEntity "faceit/domain/auth/entity"
+	"faceit/domain/constants"
+	"faceit/domain/rbac/entity"
+	"faceit/domain/rbac/repository"
+)
+
+// IAuthorizer - The interface for checking the permissions of the principal of the request
+type IAuthorizer interface {
+	Authorize(ctx context.Context, permission string) error
+	AuthorizeUser(ctx context.Context, userID int64, permission string) error
+	AuthorizeCredentials(ctx context.Context, userID int64) error
+	HasPermission(ctx context.Context, permission string) (bool, error)
+}
+
+// Authorizer - Checks the permissions granted to the roles of the principal
+type Authorizer struct {
+	repository repository.IRolesRepository
+}
+
+func NewAuthorizer(repository repository.IRolesRepository) *Authorizer {
+	return &Authorizer{repository: repository}
+}
+
+// Authorize - Checks that the principal in the context has the permission
+func (a *Authorizer) Authorize(ctx context.Context, permission string) error {
+	if _, ok := authEntity.PrincipalFromContext(ctx); !ok {
+		return constants.ErrUnauthenticated
+	}
+
+	granted, err := a.HasPermission(ctx, permission)
+	if err != nil {
+		return err
+	}
+
+	if !granted {
+		return constants.ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeUser - Checks that the principal in the context is allowed to access the user with the given ID.
+// Every user can access their own record, accessing the other users needs the permission.
+func (a *Authorizer) AuthorizeUser(ctx context.Context, userID int64, permission string) error {
+	principal, ok := authEntity.PrincipalFromContext(ctx)
+	if !ok {
+		return constants.ErrUnauthenticated
+	}
+
+	if principal.UserID == userID {
+		return nil
+	}
+
+	return a.Authorize(ctx, permission)
+}
+
+// AuthorizeCredentials - Checks that the principal in the context is allowed to change the credentials of the user with the given ID, e.g. the password.
+// Every user can change their own, changing those of the other users needs every permission they have, so nobody can take over a more privileged user.
+func (a *Authorizer) AuthorizeCredentials(ctx context.Context, userID int64) error {
+	principal, ok := authEntity.PrincipalFromContext(ctx)
+	if !ok {
+		return constants.ErrUnauthenticated
+	}
+
+	if principal.UserID == userID {
+		return nil
+	}
+
+	principalRoles, err := a.repository.GetUserRoles(ctx, principal.UserID)
+	if err != nil {
+		return err
+	}
+
+	userRoles, err := a.repository.GetUserRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(userRoles) == 0 {
+		return nil
+	}
+
+	roles, err := a.repository.GetRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	granted := permissionsOf(roles, principalRoles)
+	for permission := range permissionsOf(roles, userRoles) {
+		if !granted[permission] {
+			return constants.ErrForbidden
+		}
+	}
+
+	return nil
+}
+
+// HasPermission - Checks if the permission is granted to any of the roles of the principal in the context.
+// The roles are read from the database rather than the access token, so an assigned or revoked role takes effect on the next request.
+func (a *Authorizer) HasPermission(ctx context.Context, permission string) (bool, error) {
+	principal, ok := authEntity.PrincipalFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	roles, err := a.repository.GetUserRoles(ctx, principal.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return a.repository.HasPermission(ctx, roles, permission)
+}
+
+// permissionsOf - Returns the set of the permissions granted to the given roles
+func permissionsOf(roles []*entity.Role, names []string) map[string]bool {
+	assigned := make(map[string]bool, len(names))
+	for _, name := range names {
+		assigned[name] = true
+	}
+
+	permissions := map[string]bool{}
+	for _, role := range roles {
+		if !assigned[role.Name] {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			permissions[permission] = true
+		}
+	}
+
+	return permissions
+}
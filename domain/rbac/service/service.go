@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"faceit/domain/constants"
+	"faceit/domain/rbac/dto"
+	"faceit/domain/rbac/entity"
+	"faceit/domain/rbac/repository"
+	"faceit/domain/rbac/utils"
+	userRepository "faceit/domain/user/repository"
+)
+
+type IRBACService interface {
+	GetRoles(ctx context.Context) ([]*dto.Role, error)
+	CreateRole(ctx context.Context, role *dto.Role) (*dto.Role, error)
+	RemoveRole(ctx context.Context, name string) error
+	GetPermissions(ctx context.Context) ([]*dto.Permission, error)
+	AssignRole(ctx context.Context, userID int64, role string) error
+	RevokeRole(ctx context.Context, userID int64, role string) error
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+}
+
+type RBACService struct {
+	repository repository.IRolesRepository
+	users      userRepository.IUsersRepository
+	authorizer IAuthorizer
+}
+
+func NewRBACService(repository repository.IRolesRepository, users userRepository.IUsersRepository, authorizer IAuthorizer) *RBACService {
+	return &RBACService{repository: repository, users: users, authorizer: authorizer}
+}
+
+func (r *RBACService) GetRoles(ctx context.Context) ([]*dto.Role, error) {
+	if err := r.authorizer.Authorize(ctx, entity.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+
+	roleEntities, err := r.repository.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleDTOs := make([]*dto.Role, len(roleEntities))
+	for i, roleEntity := range roleEntities {
+		roleDTOs[i] = utils.RoleDTOFromEntity(roleEntity)
+	}
+
+	return roleDTOs, nil
+}
+
+func (r *RBACService) CreateRole(ctx context.Context, role *dto.Role) (*dto.Role, error) {
+	if err := r.authorizer.Authorize(ctx, entity.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+
+	// only the permissions checked by the services can be granted
+	permissions, err := r.repository.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	knownPermissions := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		knownPermissions[permission.Name] = true
+	}
+
+	for _, permission := range role.Permissions {
+		if !knownPermissions[permission] {
+			return nil, constants.ErrPermissionNotFound
+		}
+	}
+
+	// the name is unique in the database, the repository returns constants.ErrRoleExists if it's taken
+	roleEntity := utils.RoleEntityFromDTO(role)
+	if err := r.repository.CreateRole(ctx, roleEntity); err != nil {
+		return nil, err
+	}
+
+	return utils.RoleDTOFromEntity(roleEntity), nil
+}
+
+func (r *RBACService) RemoveRole(ctx context.Context, name string) error {
+	if err := r.authorizer.Authorize(ctx, entity.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	return r.repository.RemoveRole(ctx, name)
+}
+
+func (r *RBACService) GetPermissions(ctx context.Context) ([]*dto.Permission, error) {
+	if err := r.authorizer.Authorize(ctx, entity.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+
+	permissionEntities, err := r.repository.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissionDTOs := make([]*dto.Permission, len(permissionEntities))
+	for i, permissionEntity := range permissionEntities {
+		permissionDTOs[i] = utils.PermissionDTOFromEntity(permissionEntity)
+	}
+
+	return permissionDTOs, nil
+}
+
+func (r *RBACService) AssignRole(ctx context.Context, userID int64, role string) error {
+	if err := r.authorizer.Authorize(ctx, entity.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	// check if the user exists, the removed users can't be given roles either
+	if _, err := r.users.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	// check if the role exists
+	if _, err := r.repository.GetRole(ctx, role); err != nil {
+		return err
+	}
+
+	// the user may still be deleted in the meantime, the repository returns constants.ErrUserNotFound then
+	return r.repository.AssignRole(ctx, userID, role)
+}
+
+func (r *RBACService) RevokeRole(ctx context.Context, userID int64, role string) error {
+	if err := r.authorizer.Authorize(ctx, entity.PermissionRolesManage); err != nil {
+		return err
+	}
+
+	return r.repository.RevokeRole(ctx, userID, role)
+}
+
+// GetUserRoles - Returns the roles of the user, users can always see their own roles
+func (r *RBACService) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	if err := r.authorizer.AuthorizeUser(ctx, userID, entity.PermissionRolesManage); err != nil {
+		return nil, err
+	}
+
+	return r.repository.GetUserRoles(ctx, userID)
+}
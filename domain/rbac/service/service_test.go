@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	authEntity "faceit/domain/auth/entity"
+	"faceit/domain/constants"
+	"faceit/domain/rbac/dto"
+	"faceit/domain/rbac/entity"
+	userEntity "faceit/domain/user/entity"
+	mocks "faceit/mocks/domain/rbac/repository"
+	userMocks "faceit/mocks/domain/user/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+}
+
+var (
+	adminContext   = authEntity.ContextWithPrincipal(context.Background(), &authEntity.Principal{UserID: 1})
+	supportContext = authEntity.ContextWithPrincipal(context.Background(), &authEntity.Principal{UserID: 2})
+	// the token of the user was issued before the admin role was revoked
+	revokedContext = authEntity.ContextWithPrincipal(context.Background(), &authEntity.Principal{UserID: 4, Roles: []string{"admin"}})
+	// the admin was removed after the token was issued, the database doesn't return the roles of the removed users
+	removedContext = authEntity.ContextWithPrincipal(context.Background(), &authEntity.Principal{UserID: 9, Roles: []string{"admin"}})
+)
+
+// mockUserRoles - The roles of the principals in the database
+func mockUserRoles(repositoryMock *mocks.IRolesRepository) {
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(1)).Return([]string{"admin"}, nil)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(2)).Return([]string{"support"}, nil)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(4)).Return(nil, nil)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(9)).Return(nil, nil)
+}
+
+func (s *ServiceTestSuite) TestAuthorizer() {
+	testCases := []struct {
+		ctx           context.Context
+		userID        int64
+		permission    string
+		expectedError error
+	}{
+		{ctx: adminContext, userID: 3, permission: entity.PermissionUsersDelete, expectedError: nil},
+		{ctx: supportContext, userID: 3, permission: entity.PermissionUsersDelete, expectedError: constants.ErrForbidden},
+		{ctx: supportContext, userID: 3, permission: entity.PermissionUsersUpdate, expectedError: nil},
+		// users can always access their own record
+		{ctx: supportContext, userID: 2, permission: entity.PermissionUsersDelete, expectedError: nil},
+		{ctx: context.Background(), userID: 2, permission: entity.PermissionUsersDelete, expectedError: constants.ErrUnauthenticated},
+		// the roles in the token are not trusted
+		{ctx: revokedContext, userID: 3, permission: entity.PermissionUsersDelete, expectedError: constants.ErrForbidden},
+		// a removed admin can't act on the other users or restore itself
+		{ctx: removedContext, userID: 3, permission: entity.PermissionUsersDelete, expectedError: constants.ErrForbidden},
+		{ctx: removedContext, userID: 3, permission: entity.PermissionUsersRestore, expectedError: constants.ErrForbidden},
+	}
+
+	repositoryMock := mocks.IRolesRepository{}
+	mockUserRoles(&repositoryMock)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"admin"}, mock.Anything).Return(true, nil)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"support"}, entity.PermissionUsersUpdate).Return(true, nil)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"support"}, mock.Anything).Return(false, nil)
+	repositoryMock.On("HasPermission", mock.Anything, []string(nil), mock.Anything).Return(false, nil)
+
+	authorizer := NewAuthorizer(&repositoryMock)
+	for _, tc := range testCases {
+		err := authorizer.AuthorizeUser(tc.ctx, tc.userID, tc.permission)
+		assert.Equal(s.T(), tc.expectedError, err)
+	}
+
+	granted, err := authorizer.HasPermission(context.Background(), entity.PermissionUsersRead)
+	assert.Nil(s.T(), err)
+	assert.False(s.T(), granted)
+
+	assert.Equal(s.T(), constants.ErrForbidden, authorizer.Authorize(removedContext, entity.PermissionUsersRestore))
+}
+
+func (s *ServiceTestSuite) TestAuthorizeCredentials() {
+	testCases := []struct {
+		ctx           context.Context
+		userID        int64
+		expectedError error
+	}{
+		{ctx: adminContext, userID: 2, expectedError: nil},
+		{ctx: adminContext, userID: 3, expectedError: nil},
+		{ctx: adminContext, userID: 6, expectedError: nil},
+		{ctx: supportContext, userID: 3, expectedError: nil},
+		{ctx: supportContext, userID: 5, expectedError: nil},
+		// support can't take over an admin, nor a user with any permission support doesn't have
+		{ctx: supportContext, userID: 1, expectedError: constants.ErrForbidden},
+		{ctx: supportContext, userID: 6, expectedError: constants.ErrForbidden},
+		{ctx: supportContext, userID: 7, expectedError: constants.ErrForbidden},
+		// users can always change their own credentials
+		{ctx: supportContext, userID: 2, expectedError: nil},
+		{ctx: context.Background(), userID: 2, expectedError: constants.ErrUnauthenticated},
+	}
+
+	repositoryMock := mocks.IRolesRepository{}
+	mockUserRoles(&repositoryMock)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(3)).Return(nil, nil)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(5)).Return([]string{"support"}, nil)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(6)).Return([]string{"admin", "support"}, nil)
+	repositoryMock.On("GetUserRoles", mock.Anything, int64(7)).Return([]string{"auditor"}, nil)
+	repositoryMock.On("GetRoles", mock.Anything).Return([]*entity.Role{
+		{Name: "admin", Permissions: []string{entity.PermissionUsersRead, entity.PermissionUsersUpdate, entity.PermissionRolesManage}},
+		{Name: "auditor", Permissions: []string{entity.PermissionUsersAudit}},
+		{Name: "support", Permissions: []string{entity.PermissionUsersRead, entity.PermissionUsersUpdate}},
+	}, nil)
+
+	authorizer := NewAuthorizer(&repositoryMock)
+	for _, tc := range testCases {
+		err := authorizer.AuthorizeCredentials(tc.ctx, tc.userID)
+		assert.Equal(s.T(), tc.expectedError, err, tc.userID)
+	}
+}
+
+func (s *ServiceTestSuite) TestCreateRole() {
+	testCases := []struct {
+		ctx           context.Context
+		role          *dto.Role
+		expectedRole  *dto.Role
+		expectedError error
+	}{
+		{
+			ctx:           adminContext,
+			role:          &dto.Role{Name: "auditor", Permissions: []string{entity.PermissionUsersRead}},
+			expectedRole:  &dto.Role{Name: "auditor", Permissions: []string{entity.PermissionUsersRead}},
+			expectedError: nil,
+		},
+		{
+			ctx:           adminContext,
+			role:          &dto.Role{Name: "support"},
+			expectedRole:  nil,
+			expectedError: constants.ErrRoleExists,
+		},
+		{
+			ctx:           adminContext,
+			role:          &dto.Role{Name: "root", Permissions: []string{"everything"}},
+			expectedRole:  nil,
+			expectedError: constants.ErrPermissionNotFound,
+		},
+		{
+			ctx:           supportContext,
+			role:          &dto.Role{Name: "auditor"},
+			expectedRole:  nil,
+			expectedError: constants.ErrForbidden,
+		},
+	}
+
+	repositoryMock := mocks.IRolesRepository{}
+	mockUserRoles(&repositoryMock)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"admin"}, entity.PermissionRolesManage).Return(true, nil)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"support"}, entity.PermissionRolesManage).Return(false, nil)
+	repositoryMock.On("GetPermissions", mock.Anything).Return([]*entity.Permission{{Name: entity.PermissionUsersRead}}, nil)
+	repositoryMock.On("CreateRole", mock.Anything, &entity.Role{Name: "support"}).Return(constants.ErrRoleExists)
+	repositoryMock.On("CreateRole", mock.Anything, mock.Anything).Return(nil)
+
+	rbacService := NewRBACService(&repositoryMock, &userMocks.IUsersRepository{}, NewAuthorizer(&repositoryMock))
+	for _, tc := range testCases {
+		role, err := rbacService.CreateRole(tc.ctx, tc.role)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedRole, role)
+	}
+	repositoryMock.AssertNumberOfCalls(s.T(), "CreateRole", 2)
+}
+
+func (s *ServiceTestSuite) TestAssignRole() {
+	testCases := []struct {
+		userID        int64
+		role          string
+		expectedError error
+	}{
+		{userID: 3, role: "support", expectedError: nil},
+		{userID: 3, role: "unknown", expectedError: constants.ErrRoleNotFound},
+		// the user doesn't exist or is removed
+		{userID: 5, role: "support", expectedError: constants.ErrUserNotFound},
+	}
+
+	repositoryMock := mocks.IRolesRepository{}
+	mockUserRoles(&repositoryMock)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"admin"}, entity.PermissionRolesManage).Return(true, nil)
+	repositoryMock.On("GetRole", mock.Anything, "support").Return(&entity.Role{Name: "support"}, nil)
+	repositoryMock.On("GetRole", mock.Anything, "unknown").Return(nil, constants.ErrRoleNotFound)
+	repositoryMock.On("AssignRole", mock.Anything, int64(3), "support").Return(nil)
+	usersMock := userMocks.IUsersRepository{}
+	usersMock.On("GetByID", mock.Anything, int64(3)).Return(&userEntity.User{ID: 3}, nil)
+	usersMock.On("GetByID", mock.Anything, int64(5)).Return(nil, constants.ErrUserNotFound)
+
+	rbacService := NewRBACService(&repositoryMock, &usersMock, NewAuthorizer(&repositoryMock))
+	for _, tc := range testCases {
+		err := rbacService.AssignRole(adminContext, tc.userID, tc.role)
+		assert.Equal(s.T(), tc.expectedError, err)
+	}
+	repositoryMock.AssertNumberOfCalls(s.T(), "AssignRole", 1)
+}
+
+func (s *ServiceTestSuite) TestGetUserRoles() {
+	testCases := []struct {
+		ctx           context.Context
+		userID        int64
+		expectedRoles []string
+		expectedError error
+	}{
+		{ctx: supportContext, userID: 2, expectedRoles: []string{"support"}, expectedError: nil},
+		{ctx: supportContext, userID: 1, expectedRoles: nil, expectedError: constants.ErrForbidden},
+		{ctx: adminContext, userID: 2, expectedRoles: []string{"support"}, expectedError: nil},
+	}
+
+	repositoryMock := mocks.IRolesRepository{}
+	mockUserRoles(&repositoryMock)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"admin"}, entity.PermissionRolesManage).Return(true, nil)
+	repositoryMock.On("HasPermission", mock.Anything, []string{"support"}, entity.PermissionRolesManage).Return(false, nil)
+
+	rbacService := NewRBACService(&repositoryMock, &userMocks.IUsersRepository{}, NewAuthorizer(&repositoryMock))
+	for _, tc := range testCases {
+		roles, err := rbacService.GetUserRoles(tc.ctx, tc.userID)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedRoles, roles)
+	}
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
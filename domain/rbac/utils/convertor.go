@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"faceit/domain/rbac/dto"
+	"faceit/domain/rbac/entity"
+)
+
+func RoleDTOFromEntity(entity *entity.Role) *dto.Role {
+	return &dto.Role{
+		Name:        entity.Name,
+		Description: entity.Description,
+		Permissions: entity.Permissions,
+	}
+}
+
+func RoleEntityFromDTO(dto *dto.Role) *entity.Role {
+	return &entity.Role{
+		Name:        dto.Name,
+		Description: dto.Description,
+		Permissions: dto.Permissions,
+	}
+}
+
+func PermissionDTOFromEntity(entity *entity.Permission) *dto.Permission {
+	return &dto.Permission{
+		Name:        entity.Name,
+		Description: entity.Description,
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"faceit/domain/user/events"
+	"faceit/domain/user/service"
+	"faceit/infrastructure/server"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IAuditController interface {
+	History(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type AuditController struct {
+	service      service.IAuditService
+	authenticate gin.HandlerFunc
+}
+
+// NewAuditController - Creates a new audit controller with dependency injection
+func NewAuditController(service service.IAuditService, authenticate gin.HandlerFunc) *AuditController {
+	return &AuditController{service: service, authenticate: authenticate}
+}
+
+// RegisterRoutes - Sets up the http routes of the change history of the users
+func (a *AuditController) RegisterRoutes(router *gin.Engine) {
+	router.GET("/v1/users/:id/history", a.authenticate, a.History)
+	router.GET("/v1/audit", a.authenticate, a.List)
+}
+
+// History - Handler for getting a page of the changes made to the user, the latest first
+func (a *AuditController) History(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var request pageRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	page, pageSize := pageOrDefault(request)
+
+	entries, err := a.service.GetHistory(c.Request.Context(), ID, page, pageSize)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	a.respondEntries(c, entries)
+}
+
+// List - Handler for getting a page of the changes made to all the users with the filters in the query parameters, the latest first
+func (a *AuditController) List(c *gin.Context) {
+	var request auditRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	filter, err := auditFilter(&request)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	page, pageSize := pageOrDefault(request.pageRequest)
+
+	entries, err := a.service.Get(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	a.respondEntries(c, entries)
+}
+
+func (a *AuditController) respondEntries(c *gin.Context, entries []*dto.AuditEntry) {
+	type auditResponse struct {
+		Entries []*dto.AuditEntry `json:"entries"`
+	}
+
+	server.Response(c, http.StatusOK, auditResponse{Entries: entries})
+}
+
+// auditFilter - Converts the query of the audit log to its filter, the actions are the comma separated event types
+// and the times are in RFC 3339 or dates in UTC, like the filters of the users
+func auditFilter(request *auditRequest) (*dto.AuditFilter, error) {
+	filter := &dto.AuditFilter{}
+	if request.UserID != 0 {
+		filter.UserID = &request.UserID
+	}
+	if request.ActorID != 0 {
+		filter.ActorID = &request.ActorID
+	}
+
+	if request.Action != "" {
+		for _, action := range strings.Split(request.Action, ",") {
+			if !events.Type(action).IsValid() {
+				return nil, constants.NewValidationError("invalid query parameters",
+					constants.FieldError{Field: "action", Code: "oneof", Message: "must be one of the event types, e.g. user.updated"})
+			}
+			filter.Actions = append(filter.Actions, action)
+		}
+	}
+
+	bounds := []struct {
+		field string
+		value string
+		bound **time.Time
+	}{
+		{field: "from", value: request.From, bound: &filter.CreatedAt.Gte},
+		{field: "to", value: request.To, bound: &filter.CreatedAt.Lt},
+	}
+	for _, bound := range bounds {
+		if bound.value == "" {
+			continue
+		}
+
+		value, fieldErr := parseTime(bound.value)
+		if fieldErr != nil {
+			fieldErr.Field = bound.field
+			return nil, constants.NewValidationError("invalid query parameters", *fieldErr)
+		}
+		*bound.bound = &value
+	}
+
+	return filter, nil
+}
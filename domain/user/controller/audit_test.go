@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"faceit/infrastructure/server"
+	mocks "faceit/mocks/domain/user/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditControllerTestSuite struct {
+	suite.Suite
+	serviceMock *mocks.IAuditService
+	router      *gin.Engine
+}
+
+func (a *AuditControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	a.serviceMock = &mocks.IAuditService{}
+	a.router = gin.New()
+	a.router.Use(server.ErrorHandler())
+	NewAuditController(a.serviceMock, func(*gin.Context) {}).RegisterRoutes(a.router)
+}
+
+func (a *AuditControllerTestSuite) serve(target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	a.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func (a *AuditControllerTestSuite) TestHistory() {
+	actorID := int64(7)
+	createdAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	entries := []*dto.AuditEntry{{ID: 1, UserID: 3, Action: "user.updated", ActorID: &actorID, RequestID: "req-1", SourceIP: "203.0.113.7", CreatedAt: createdAt}}
+	a.serviceMock.On("GetHistory", mock.Anything, int64(3), int64(1), int64(defaultPageSize)).Return(entries, nil).Once()
+	a.serviceMock.On("GetHistory", mock.Anything, int64(3), int64(2), int64(5)).Return([]*dto.AuditEntry{}, nil).Once()
+	a.serviceMock.On("GetHistory", mock.Anything, int64(4), int64(1), int64(defaultPageSize)).Return(nil, constants.ErrForbidden).Once()
+
+	response := a.serve("/v1/users/3/history")
+	assert.Equal(a.T(), http.StatusOK, response.Code)
+	assert.JSONEq(a.T(), `{"status":200,"payload":{"entries":[{"id":1,"user_id":3,"action":"user.updated","actor_id":7,"request_id":"req-1","source_ip":"203.0.113.7","created_at":"2022-10-01T12:00:00Z"}]}}`, response.Body.String())
+
+	assert.Equal(a.T(), http.StatusOK, a.serve("/v1/users/3/history?page=2&page_size=5").Code)
+	assert.Equal(a.T(), http.StatusForbidden, a.serve("/v1/users/4/history").Code)
+	assert.Equal(a.T(), http.StatusBadRequest, a.serve("/v1/users/3/history?page=0&page_size=101").Code)
+	assert.Equal(a.T(), http.StatusBadRequest, a.serve("/v1/users/abc/history").Code)
+	a.serviceMock.AssertExpectations(a.T())
+}
+
+func (a *AuditControllerTestSuite) TestList() {
+	actorID, userID := int64(7), int64(3)
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 2, 1, 12, 0, 0, 0, time.UTC)
+	a.serviceMock.On("Get", mock.Anything, &dto.AuditFilter{}, int64(1), int64(defaultPageSize)).Return([]*dto.AuditEntry{}, nil).Once()
+	a.serviceMock.On("Get", mock.Anything, &dto.AuditFilter{
+		UserID:    &userID,
+		ActorID:   &actorID,
+		Actions:   []string{"user.updated", "user.deleted"},
+		CreatedAt: dto.TimeRange{Gte: &from, Lt: &to},
+	}, int64(1), int64(defaultPageSize)).Return([]*dto.AuditEntry{}, nil).Once()
+
+	assert.Equal(a.T(), http.StatusOK, a.serve("/v1/audit").Code)
+	assert.Equal(a.T(), http.StatusOK, a.serve("/v1/audit?user_id=3&actor_id=7&action=user.updated,user.deleted&from=2022-01-01&to=2022-02-01T12:00:00Z").Code)
+
+	testCases := []string{
+		"/v1/audit?action=user.renamed",
+		"/v1/audit?action=user.updated,",
+		"/v1/audit?from=yesterday",
+		"/v1/audit?to=2022-02-31",
+		"/v1/audit?actor_id=0x7",
+	}
+	for _, target := range testCases {
+		assert.Equal(a.T(), http.StatusBadRequest, a.serve(target).Code, target)
+	}
+	a.serviceMock.AssertExpectations(a.T())
+}
+
+func TestAuditControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditControllerTestSuite))
+}
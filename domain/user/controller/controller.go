@@ -1,125 +1,429 @@
 package controller
 
 import (
+	"errors"
+	"faceit/domain/constants"
 	"faceit/domain/user/dto"
 	"faceit/domain/user/service"
 	"faceit/infrastructure/database"
+	"faceit/infrastructure/server"
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+// defaultPageSize - The page size of the list when it's not given, the maximum is in the binding of listRequest
+const defaultPageSize = 20
+
 type IUsersController interface {
 	Create(c *gin.Context)
-	Update(c *gin.Context)
+	Replace(c *gin.Context)
+	Patch(c *gin.Context)
 	Remove(c *gin.Context)
-	Get(c *gin.Context)
+	Restore(c *gin.Context)
+	List(c *gin.Context)
+	ListDeleted(c *gin.Context)
+	GetByID(c *gin.Context)
+	Lookup(c *gin.Context)
+	BatchGet(c *gin.Context)
+	CreateDeprecated(c *gin.Context)
+	UpdateDeprecated(c *gin.Context)
+	GetDeprecated(c *gin.Context)
 }
 
 type UsersController struct {
-	service service.IUserService
-	store   database.IDatabase
+	service      service.IUserService
+	store        database.IDatabase
+	authenticate gin.HandlerFunc
+	idempotent   gin.HandlerFunc
 }
 
-// NewUserController - Creates a new user controller with dependency injection
-func NewUserController(service service.IUserService, store database.IDatabase) *UsersController {
-	return &UsersController{service: service, store: store}
+// NewUserController - Creates a new user controller with dependency injection.
+// The authenticate middleware is applied to the routes which need a principal,
+// and the idempotent middleware to the creation of the users, so it can be retried with an idempotency key.
+func NewUserController(service service.IUserService, store database.IDatabase, authenticate, idempotent gin.HandlerFunc) *UsersController {
+	return &UsersController{service: service, store: store, authenticate: authenticate, idempotent: idempotent}
 }
 
-// Run - Starts the gin engine and sets up the http routes
-func (u *UsersController) Run(port string) *http.Server {
-	// init gin
-	gin.SetMode(gin.DebugMode)
-	router := gin.New()
-
+// RegisterRoutes - Sets up the http routes of the users
+func (u *UsersController) RegisterRoutes(router *gin.Engine) {
 	router.GET("/health", u.HealthCheck)
 
 	v1 := router.Group("/v1")
 	{
-		user := v1.Group("/users")
+		users := v1.Group("/users")
 		{
-			user.POST("/create", u.Create)
-			user.POST("/update", u.Update)
-			user.DELETE("/:id", u.Remove)
-			user.POST("/get", u.Get)
+			users.GET("", u.authenticate, u.List)
+			users.POST("", u.idempotent, u.Create)
+			users.POST("/batch", u.authenticate, u.BatchGet)
+			users.GET("/lookup", u.authenticate, u.Lookup)
+			users.GET("/search", u.authenticate, u.Search)
+			users.GET("/deleted", u.authenticate, u.ListDeleted)
+			users.GET("/:id", u.authenticate, u.GetByID)
+			users.PUT("/:id", u.authenticate, u.Replace)
+			users.PATCH("/:id", u.authenticate, u.Patch)
+			users.DELETE("/:id", u.authenticate, u.Remove)
+			users.POST("/:id/restore", u.authenticate, u.Restore)
+
+			// the routes before the resource-oriented ones, kept until their clients move
+			users.POST("/create", server.Deprecated("/v1/users"), u.idempotent, u.CreateDeprecated)
+			users.POST("/update", server.Deprecated("/v1/users/{id}"), u.authenticate, u.UpdateDeprecated)
+			users.POST("/get", server.Deprecated("/v1/users"), u.authenticate, u.GetDeprecated)
 		}
 	}
+}
 
-	// gin middleware config
-	router.Use(gin.Recovery())
-	router.Use(cors.New(cors.Config{
-		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "HEAD", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-Requested-With", "Authorization"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+// Create - Handler to create a user with the given user information, it responds with 201 and the location of the user
+func (u *UsersController) Create(c *gin.Context) {
+	var request createRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{"status": false, "data": gin.H{"status": false, "message": fmt.Sprintf("Page not found: %s, method: %s", c.Request.URL, c.Request.Method)}})
-	})
+	createdUserDTO, err := u.service.Create(c.Request.Context(), createRequestDTO(&request), request.Password)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	router.NoMethod(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{"status": false, "data": gin.H{"status": false, "message": "Method not found"}})
-	})
+	createdUserDTO.CreatedAt = time.Now()
+	createdUserDTO.UpdatedAt = time.Now()
+	c.Header("Location", fmt.Sprintf("/v1/users/%d", createdUserDTO.ID))
+	c.Header("ETag", server.ETag(createdUserDTO.Version))
+	server.Response(c, http.StatusCreated, createdUserDTO)
+}
 
-	// Note: we use http server to have graceful shutdown
-	server := &http.Server{
-		Addr:         port,
-		Handler:      router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 20 * time.Second,
-		IdleTimeout:  10 * time.Second,
+// Replace - Handler to replace all the information of the user, the password is only changed if it's given.
+// Replacing the user with the same information succeeds, so the request can be repeated.
+// The version the request is based on must be given, see update.
+func (u *UsersController) Replace(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
 	}
 
-	go func() {
-		log.Printf("Listening and serving HTTP on %s\n", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("gin sever stoped with err: %s \n", err)
-		}
-	}()
+	var request replaceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
 
-	return server
+	u.update(c, &dto.User{
+		ID:        ID,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		NickName:  request.NickName,
+		Email:     request.Email,
+		Country:   request.Country,
+		Version:   request.Version,
+	}, request.Password)
 }
 
-// Create - Handler to create a user with the given user information
-func (u *UsersController) Create(c *gin.Context) {
-	var request createRequest
-	if err := c.BindJSON(&request); err != nil {
-		u.ginResponse(c, http.StatusBadRequest, err.Error())
+// Patch - Handler to change the given fields of the user, the missing or empty fields are kept.
+// The version the request is based on must be given, see update.
+func (u *UsersController) Patch(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	userDTO := &dto.User{
+	var request patchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	u.update(c, &dto.User{
+		ID:        ID,
 		FirstName: request.FirstName,
 		LastName:  request.LastName,
 		NickName:  request.NickName,
 		Email:     request.Email,
 		Country:   request.Country,
+		Version:   request.Version,
+	}, request.Password)
+}
+
+// update - Updates the user and responds with the updated user, an update without changes is not an error.
+// The version of the user is taken from the If-Match header, or the version field of the body, and one of them is required.
+// A stale version is reported as 412 for the If-Match header and 409 for the version field, * in If-Match skips the check.
+func (u *UsersController) update(c *gin.Context, userDTO *dto.User, password string) {
+	version, ifMatch, err := server.IfMatch(c)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if ifMatch {
+		userDTO.Version = version
+	} else if userDTO.Version == 0 {
+		_ = c.Error(constants.ErrPreconditionRequired)
+		return
 	}
 
-	createdUserDTO, err := u.service.Create(c.Request.Context(), userDTO, request.Password)
+	err = u.service.Update(c.Request.Context(), userDTO, password)
+	if ifMatch && errors.Is(err, constants.ErrVersionConflict) {
+		err = constants.ErrPreconditionFailed
+	}
+	if err != nil && !errors.Is(err, constants.ErrHasNoChanges) {
+		_ = c.Error(err)
+		return
+	}
+
+	updatedUserDTO, err := u.service.GetByID(c.Request.Context(), userDTO.ID)
 	if err != nil {
-		u.ginResponse(c, http.StatusInternalServerError, err.Error())
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", server.ETag(updatedUserDTO.Version))
+	server.Response(c, http.StatusOK, updatedUserDTO)
+}
+
+// Remove - Handler to remove a user based on the provided user ID, it responds with 204
+func (u *UsersController) Remove(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := u.service.Remove(c.Request.Context(), ID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Restore - Handler to bring back a removed user before it's purged, it responds with 204
+func (u *UsersController) Restore(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := u.service.Restore(c.Request.Context(), ID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeleted - Handler for getting a page of the removed users which are not purged yet, the most recently removed first
+func (u *UsersController) ListDeleted(c *gin.Context) {
+	var request pageRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	page, pageSize := pageOrDefault(request)
+
+	userDTOs, count, err := u.service.GetDeleted(c.Request.Context(), page, pageSize)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	type deletedResponse struct {
+		Users []*dto.User `json:"users"`
+		Count uint64      `json:"count"`
+	}
+
+	server.Response(c, http.StatusOK, deletedResponse{Users: userDTOs, Count: count})
+}
+
+// List - Handler for getting a page of the users based on the filters and the paging in the query parameters.
+// The pages are reached by their cursors, the page numbers are only used when the page query parameter is given.
+func (u *UsersController) List(c *gin.Context) {
+	var request listRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if request.PageSize == 0 {
+		request.PageSize = defaultPageSize
+	}
+
+	filter, err := parseFilter(c.Request.URL.Query())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	sort, err := parseSort(request.Sort)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if request.Page != 0 {
+		if request.Cursor != "" {
+			_ = c.Error(constants.NewValidationError("invalid query parameters",
+				constants.FieldError{Field: "cursor", Code: "excluded_with", Message: "can't be given with page"}))
+			return
+		}
+
+		u.respondUsers(c, filter, sort, request.Page, request.PageSize)
+		return
+	}
+
+	page, err := u.service.GetPage(c.Request.Context(), filter, sort, request.Cursor, request.PageSize, request.WithCount)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	type pageResponse struct {
+		Users      []*dto.User `json:"users"`
+		NextCursor string      `json:"next_cursor,omitempty"`
+		PrevCursor string      `json:"prev_cursor,omitempty"`
+		Count      *uint64     `json:"count,omitempty"`
+	}
+
+	server.Response(c, http.StatusOK, pageResponse{
+		Users:      page.Users,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+		Count:      page.Count,
+	})
+}
+
+// GetByID - Handler for getting the user with the given ID, the ETag header is its version
+func (u *UsersController) GetByID(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userDTO, err := u.service.GetByID(c.Request.Context(), ID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", server.ETag(userDTO.Version))
+	server.Response(c, http.StatusOK, userDTO)
+}
+
+// Lookup - Handler for getting the user with the exact email or nickname given in the query parameters
+func (u *UsersController) Lookup(c *gin.Context) {
+	var request lookupRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var (
+		userDTO *dto.User
+		err     error
+	)
+	switch {
+	case request.Email != "" && request.NickName == "":
+		userDTO, err = u.service.GetByEmail(c.Request.Context(), request.Email)
+	case request.NickName != "" && request.Email == "":
+		userDTO, err = u.service.GetByNickName(c.Request.Context(), request.NickName)
+	default:
+		err = constants.NewValidationError("invalid query parameters",
+			constants.FieldError{Field: "email", Code: "exactly_one_of", Message: "exactly one of email and nick_name must be given"},
+			constants.FieldError{Field: "nick_name", Code: "exactly_one_of", Message: "exactly one of email and nick_name must be given"})
+	}
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.Header("ETag", server.ETag(userDTO.Version))
+	server.Response(c, http.StatusOK, userDTO)
+}
+
+// Search - Handler for the full-text search of the users, the best matches first
+func (u *UsersController) Search(c *gin.Context) {
+	var request searchRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if request.Limit == 0 {
+		request.Limit = defaultPageSize
+	}
+
+	result, err := u.service.Search(c.Request.Context(), request.Query, request.Limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	type searchResponse struct {
+		Users []*dto.SearchHit `json:"users"`
+		Total uint64           `json:"total"`
+	}
+
+	server.Response(c, http.StatusOK, searchResponse{
+		Users: result.Hits,
+		Total: result.Total,
+	})
+}
+
+// BatchGet - Handler for getting many users by their IDs at once, in the order of the IDs and with the IDs of the missing users
+func (u *UsersController) BatchGet(c *gin.Context) {
+	var request batchGetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	userDTOs, missingIDs, err := u.service.GetByIDs(c.Request.Context(), request.IDs)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	type batchGetResponse struct {
+		Users      []*dto.User `json:"users"`
+		MissingIDs []int64     `json:"missing_ids"`
+	}
+
+	server.Response(c, http.StatusOK, batchGetResponse{Users: userDTOs, MissingIDs: missingIDs})
+}
+
+// CreateDeprecated - Handler to create a user with the given user information.
+// Deprecated: POST /v1/users responds with 201 and the location of the user instead.
+func (u *UsersController) CreateDeprecated(c *gin.Context) {
+	var request createRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	createdUserDTO, err := u.service.Create(c.Request.Context(), createRequestDTO(&request), request.Password)
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
 	createdUserDTO.CreatedAt = time.Now()
 	createdUserDTO.UpdatedAt = time.Now()
-	u.ginResponse(c, http.StatusOK, createdUserDTO)
+	server.Response(c, http.StatusOK, createdUserDTO)
 }
 
-// Update - Handler to update the given user
-func (u *UsersController) Update(c *gin.Context) {
+// UpdateDeprecated - Handler to update the given user at the version in the body, it's rejected with 428 without the version
+// so the changes can't overwrite the concurrent ones.
+// Deprecated: PATCH /v1/users/:id takes the ID from the path instead.
+func (u *UsersController) UpdateDeprecated(c *gin.Context) {
 	var request updateRequest
-	if err := c.BindJSON(&request); err != nil {
-		u.ginResponse(c, http.StatusBadRequest, err.Error())
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if request.Version == 0 {
+		_ = c.Error(constants.ErrPreconditionRequired)
 		return
 	}
 
@@ -130,55 +434,40 @@ func (u *UsersController) Update(c *gin.Context) {
 		NickName:  request.NickName,
 		Email:     request.Email,
 		Country:   request.Country,
+		Version:   request.Version,
 	}
 
 	if err := u.service.Update(c.Request.Context(), userDTO, request.Password); err != nil {
-		u.ginResponse(c, http.StatusInternalServerError, err.Error())
+		_ = c.Error(err)
 		return
 	}
 
-	u.ginResponse(c, http.StatusOK, nil)
+	server.Response(c, http.StatusOK, nil)
 }
 
-// Remove - Handler to remove a user based on the provided user ID
-func (u *UsersController) Remove(c *gin.Context) {
-	ID := c.Param("id")
-	IDint64, err := strconv.ParseInt(ID, 10, 64)
+// GetDeprecated - Handler for getting users based on the provided criteria in the URL parameters and the paging in the body.
+// Deprecated: GET /v1/users takes the paging from the query parameters instead.
+func (u *UsersController) GetDeprecated(c *gin.Context) {
+	filter, err := parseFilter(c.Request.URL.Query())
 	if err != nil {
-		u.ginResponse(c, http.StatusBadRequest, err.Error())
+		_ = c.Error(err)
 		return
 	}
 
-	if err := u.service.Remove(c.Request.Context(), IDint64); err != nil {
-		u.ginResponse(c, http.StatusInternalServerError, err.Error())
+	var request getRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	u.ginResponse(c, http.StatusOK, nil)
+	u.respondUsers(c, filter, nil, request.Page, request.PageSize)
 }
 
-// Get - Handler for getting users based on the provided criteria in the URL parameters
-func (u *UsersController) Get(c *gin.Context) {
-	filter := &dto.Filter{}
-	country, found := c.GetQuery("country")
-	if found {
-		filter.Country = strings.ToUpper(country)
-	}
-
-	nickname, found := c.GetQuery("nick_name")
-	if found {
-		filter.NickName = nickname
-	}
-
-	var request getRequest
-	if err := c.BindJSON(&request); err != nil {
-		u.ginResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	userDTOs, count, err := u.service.Get(c.Request.Context(), filter, request.Page, request.PageSize)
+// respondUsers - Responds with a page of the users and the total count of them
+func (u *UsersController) respondUsers(c *gin.Context, filter *dto.Filter, sort []dto.SortKey, page, pageSize int64) {
+	userDTOs, count, err := u.service.Get(c.Request.Context(), filter, sort, page, pageSize)
 	if err != nil {
-		u.ginResponse(c, http.StatusInternalServerError, err.Error())
+		_ = c.Error(err)
 		return
 	}
 	type getResponse struct {
@@ -190,7 +479,7 @@ func (u *UsersController) Get(c *gin.Context) {
 		Count: count,
 	}
 
-	u.ginResponse(c, http.StatusOK, response)
+	server.Response(c, http.StatusOK, response)
 }
 
 // HealthCheck - Checks database health
@@ -201,27 +490,53 @@ func (u *UsersController) HealthCheck(c *gin.Context) {
 
 	if err := u.store.Ping(); err != nil {
 		health["database"] = "down"
-		u.ginResponse(c, http.StatusInternalServerError, health)
+		server.Response(c, http.StatusInternalServerError, health)
 		return
 	}
 
-	u.ginResponse(c, http.StatusOK, health)
+	server.Response(c, http.StatusOK, health)
+}
+
+// pageOrDefault - The first page of the default size unless they are given
+func pageOrDefault(request pageRequest) (int64, int64) {
+	page, pageSize := request.Page, request.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	return page, pageSize
 }
 
-// ginResponse - A simple helper function to prepare the response structure
-func (u *UsersController) ginResponse(c *gin.Context, status int, payload interface{}) {
-	type Response struct {
-		Status  int         `json:"status"`
-		Payload interface{} `json:"payload"`
+// parseSort - Parses the comma separated sort keys, e.g. -created_at,nick_name. A - prefix sorts the key in the descending order.
+// The keys are checked against the sortable fields by the service.
+func parseSort(value string) ([]dto.SortKey, error) {
+	if value == "" {
+		return nil, nil
 	}
 
-	response := Response{
-		Status:  status,
-		Payload: payload,
+	fields := strings.Split(value, ",")
+	sort := make([]dto.SortKey, len(fields))
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		sort[i] = dto.SortKey{Field: strings.TrimPrefix(field, "-"), Descending: strings.HasPrefix(field, "-")}
+		if sort[i].Field == "" {
+			return nil, constants.NewValidationError("invalid query parameters",
+				constants.FieldError{Field: "sort", Code: "invalid_format", Message: "must be comma separated fields, e.g. -created_at,nick_name"})
+		}
 	}
 
-	c.Header("Content-Type", "application/json")
-	c.Status(status)
+	return sort, nil
+}
 
-	c.JSON(status, response)
+func createRequestDTO(request *createRequest) *dto.User {
+	return &dto.User{
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		NickName:  request.NickName,
+		Email:     request.Email,
+		Country:   request.Country,
+	}
 }
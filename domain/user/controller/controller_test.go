@@ -0,0 +1,395 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"faceit/infrastructure/server"
+	mocks "faceit/mocks/domain/user/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ControllerTestSuite struct {
+	suite.Suite
+	serviceMock *mocks.IUserService
+	router      *gin.Engine
+}
+
+func (c *ControllerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	c.serviceMock = &mocks.IUserService{}
+	c.router = gin.New()
+	c.router.Use(server.ErrorHandler())
+	NewUserController(c.serviceMock, nil, func(*gin.Context) {}, func(*gin.Context) {}).RegisterRoutes(c.router)
+}
+
+func (c *ControllerTestSuite) serve(method, target, body string) *httptest.ResponseRecorder {
+	return c.serveIfMatch(method, target, body, "")
+}
+
+// serveIfMatch - Serves the request with the If-Match header, unless it's empty
+func (c *ControllerTestSuite) serveIfMatch(method, target, body, ifMatch string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		request.Header.Set("If-Match", ifMatch)
+	}
+	recorder := httptest.NewRecorder()
+	c.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (c *ControllerTestSuite) problem(response *httptest.ResponseRecorder) *server.Problem {
+	assert.Equal(c.T(), "application/problem+json", response.Header().Get("Content-Type"))
+	problem := &server.Problem{}
+	c.Require().NoError(json.Unmarshal(response.Body.Bytes(), problem))
+	assert.Equal(c.T(), response.Code, problem.Status)
+	return problem
+}
+
+func (c *ControllerTestSuite) TestCreate() {
+	c.serviceMock.On("Create", mock.Anything, &dto.User{FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK"}, "pass").
+		Return(&dto.User{ID: 7, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK"}, nil).Once()
+	c.serviceMock.On("Create", mock.Anything, mock.Anything, "pass").Return(nil, constants.ErrUserExists).Once()
+
+	body := `{"first_name":"test","last_name":"test","nick_name":"test","email":"test@gmail.com","country":"UK","password":"pass"}`
+	response := c.serve(http.MethodPost, "/v1/users", body)
+	assert.Equal(c.T(), http.StatusCreated, response.Code)
+	assert.Equal(c.T(), "/v1/users/7", response.Header().Get("Location"))
+
+	response = c.serve(http.MethodPost, "/v1/users", body)
+	assert.Equal(c.T(), http.StatusConflict, response.Code)
+	assert.Equal(c.T(), "user_exists", c.problem(response).Code)
+
+	response = c.serve(http.MethodPost, "/v1/users", `{"nick_name":"test","email":1}`)
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+	assert.Equal(c.T(), []constants.FieldError{{Field: "email", Code: "invalid_type", Message: "must be a string"}}, c.problem(response).Errors)
+
+	response = c.serve(http.MethodPost, "/v1/users", `{"nick_name":"test"}`)
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+	problem := c.problem(response)
+	assert.Equal(c.T(), "invalid_request", problem.Code)
+	assert.Contains(c.T(), problem.Errors, constants.FieldError{Field: "first_name", Code: "required", Message: "is required"})
+	assert.NotContains(c.T(), problem.Errors, constants.FieldError{Field: "nick_name", Code: "required", Message: "is required"})
+
+	response = c.serve(http.MethodPost, "/v1/users", strings.Replace(body, `"pass"`, `"`+strings.Repeat("p", 73)+`"`, 1))
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+	assert.Equal(c.T(), []constants.FieldError{{Field: "password", Code: "max", Message: "must be at most 72"}}, c.problem(response).Errors)
+}
+
+func (c *ControllerTestSuite) TestList() {
+	createdAt := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		target           string
+		expectedFilter   *dto.Filter
+		expectedSort     []dto.SortKey
+		expectedPage     int64
+		expectedPageSize int64
+		expectedStatus   int
+	}{
+		{target: "/v1/users?country=uk&nick_name=test&page=3&page_size=50", expectedFilter: &dto.Filter{Countries: []string{"UK"}, NickName: dto.TextFilter{Value: "test", Match: dto.MatchContains}}, expectedPage: 3, expectedPageSize: 50, expectedStatus: http.StatusOK},
+		{target: "/v1/users?page=1", expectedFilter: &dto.Filter{}, expectedPage: 1, expectedPageSize: defaultPageSize, expectedStatus: http.StatusOK},
+		{target: "/v1/users?page=1&sort=-created_at,%20nick_name", expectedFilter: &dto.Filter{}, expectedSort: []dto.SortKey{{Field: "created_at", Descending: true}, {Field: "nick_name"}}, expectedPage: 1, expectedPageSize: defaultPageSize, expectedStatus: http.StatusOK},
+		{target: "/v1/users?page=1&sort=country,,id", expectedStatus: http.StatusBadRequest},
+		{
+			target:           "/v1/users?page=1&country=uk,de&email[prefix]=test&created_at[gte]=2022-01-02",
+			expectedFilter:   &dto.Filter{Countries: []string{"UK", "DE"}, Email: dto.TextFilter{Value: "test", Match: dto.MatchPrefix}, CreatedAt: dto.TimeRange{Gte: &createdAt}},
+			expectedPage:     1,
+			expectedPageSize: defaultPageSize,
+			expectedStatus:   http.StatusOK,
+		},
+		{target: "/v1/users?id[gte]=first", expectedStatus: http.StatusBadRequest},
+		{target: "/v1/users?page_size=101", expectedStatus: http.StatusBadRequest},
+		{target: "/v1/users?page=-1", expectedStatus: http.StatusBadRequest},
+		{target: "/v1/users?page=first", expectedStatus: http.StatusBadRequest},
+		{target: "/v1/users?page=2&cursor=abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		c.SetupTest()
+		if tc.expectedFilter != nil {
+			c.serviceMock.On("Get", mock.Anything, tc.expectedFilter, tc.expectedSort, tc.expectedPage, tc.expectedPageSize).
+				Return([]*dto.User{{ID: 1}}, uint64(1), nil).Once()
+		}
+
+		response := c.serve(http.MethodGet, tc.target, "")
+		assert.Equal(c.T(), tc.expectedStatus, response.Code, tc.target)
+		c.serviceMock.AssertExpectations(c.T())
+	}
+}
+
+func (c *ControllerTestSuite) TestListPage() {
+	count := uint64(42)
+	c.serviceMock.On("GetPage", mock.Anything, &dto.Filter{}, []dto.SortKey(nil), "", int64(defaultPageSize), false).
+		Return(&dto.Page{Users: []*dto.User{{ID: 1}}, NextCursor: "next"}, nil).Once()
+	c.serviceMock.On("GetPage", mock.Anything, &dto.Filter{Countries: []string{"UK"}}, []dto.SortKey{{Field: "country", Descending: true}}, "next", int64(10), true).
+		Return(&dto.Page{Users: []*dto.User{{ID: 2}}, PrevCursor: "prev", Count: &count}, nil).Once()
+	c.serviceMock.On("GetPage", mock.Anything, &dto.Filter{}, []dto.SortKey(nil), "forged", int64(defaultPageSize), false).
+		Return(nil, constants.ErrInvalidCursor).Once()
+
+	response := c.serve(http.MethodGet, "/v1/users", "")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.JSONEq(c.T(), `{"status":200,"payload":{"users":[{"id":1,"first_name":"","last_name":"","nick_name":"","email":"","country":"","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"next_cursor":"next"}}`, response.Body.String())
+
+	response = c.serve(http.MethodGet, "/v1/users?country=uk&sort=-country&cursor=next&page_size=10&with_count=true", "")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	var body struct {
+		Payload struct {
+			PrevCursor string  `json:"prev_cursor"`
+			Count      *uint64 `json:"count"`
+		} `json:"payload"`
+	}
+	c.Require().NoError(json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(c.T(), "prev", body.Payload.PrevCursor)
+	assert.Equal(c.T(), &count, body.Payload.Count)
+
+	response = c.serve(http.MethodGet, "/v1/users?cursor=forged", "")
+	assert.Equal(c.T(), http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(c.T(), "invalid_cursor", c.problem(response).Code)
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func (c *ControllerTestSuite) TestGetByID() {
+	c.serviceMock.On("GetByID", mock.Anything, int64(1)).Return(&dto.User{ID: 1, NickName: "test", Version: 3}, nil).Once()
+	c.serviceMock.On("GetByID", mock.Anything, int64(2)).Return(nil, constants.ErrUserNotFound).Once()
+
+	response := c.serve(http.MethodGet, "/v1/users/1", "")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.Equal(c.T(), `"3"`, response.Header().Get("ETag"))
+	var body struct {
+		Payload dto.User `json:"payload"`
+	}
+	c.Require().NoError(json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(c.T(), "test", body.Payload.NickName)
+
+	response = c.serve(http.MethodGet, "/v1/users/2", "")
+	assert.Equal(c.T(), http.StatusNotFound, response.Code)
+	assert.Equal(c.T(), &server.Problem{
+		Type:     "about:blank",
+		Title:    "Not Found",
+		Status:   http.StatusNotFound,
+		Detail:   "user not found",
+		Instance: "/v1/users/2",
+		Code:     "user_not_found",
+	}, c.problem(response))
+
+	response = c.serve(http.MethodGet, "/v1/users/two", "")
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+	assert.Equal(c.T(), []constants.FieldError{{Field: "id", Code: "invalid_type", Message: "must be an integer"}}, c.problem(response).Errors)
+}
+
+func (c *ControllerTestSuite) TestLookup() {
+	c.serviceMock.On("GetByEmail", mock.Anything, "test+1@gmail.com").Return(&dto.User{ID: 1, Email: "test+1@gmail.com"}, nil).Once()
+	c.serviceMock.On("GetByNickName", mock.Anything, "test").Return(&dto.User{ID: 2, NickName: "test"}, nil).Once()
+	c.serviceMock.On("GetByNickName", mock.Anything, "missing").Return(nil, constants.ErrUserNotFound).Once()
+	c.serviceMock.On("GetByEmail", mock.Anything, "other@gmail.com").Return(nil, constants.ErrForbidden).Once()
+
+	testCases := []struct {
+		target         string
+		expectedStatus int
+		expectedID     int64
+	}{
+		{target: "/v1/users/lookup?email=test%2B1@gmail.com", expectedStatus: http.StatusOK, expectedID: 1},
+		{target: "/v1/users/lookup?nick_name=test", expectedStatus: http.StatusOK, expectedID: 2},
+		{target: "/v1/users/lookup?nick_name=missing", expectedStatus: http.StatusNotFound},
+		{target: "/v1/users/lookup?email=other@gmail.com", expectedStatus: http.StatusForbidden},
+		{target: "/v1/users/lookup", expectedStatus: http.StatusBadRequest},
+		{target: "/v1/users/lookup?email=test@gmail.com&nick_name=test", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		response := c.serve(http.MethodGet, tc.target, "")
+		assert.Equal(c.T(), tc.expectedStatus, response.Code, tc.target)
+		if tc.expectedStatus != http.StatusOK {
+			continue
+		}
+
+		var body struct {
+			Payload dto.User `json:"payload"`
+		}
+		c.Require().NoError(json.Unmarshal(response.Body.Bytes(), &body))
+		assert.Equal(c.T(), tc.expectedID, body.Payload.ID)
+	}
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func (c *ControllerTestSuite) TestBatchGet() {
+	c.serviceMock.On("GetByIDs", mock.Anything, []int64{3, 1, 2}).Return([]*dto.User{{ID: 3}, {ID: 1}}, []int64{2}, nil).Once()
+	c.serviceMock.On("GetByIDs", mock.Anything, mock.Anything).Return(nil, nil, constants.ErrTooManyIDs).Once()
+
+	response := c.serve(http.MethodPost, "/v1/users/batch", `{"ids":[3,1,2]}`)
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	var body struct {
+		Payload struct {
+			Users      []*dto.User `json:"users"`
+			MissingIDs []int64     `json:"missing_ids"`
+		} `json:"payload"`
+	}
+	c.Require().NoError(json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(c.T(), []*dto.User{{ID: 3}, {ID: 1}}, body.Payload.Users)
+	assert.Equal(c.T(), []int64{2}, body.Payload.MissingIDs)
+
+	response = c.serve(http.MethodPost, "/v1/users/batch", `{"ids":[1,2]}`)
+	assert.Equal(c.T(), http.StatusUnprocessableEntity, response.Code)
+	assert.Equal(c.T(), "too_many_ids", c.problem(response).Code)
+
+	response = c.serve(http.MethodPost, "/v1/users/batch", `{"ids":[]}`)
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+	assert.Equal(c.T(), []constants.FieldError{{Field: "ids", Code: "min", Message: "must be at least 1"}}, c.problem(response).Errors)
+}
+
+func (c *ControllerTestSuite) TestSearch() {
+	c.serviceMock.On("Search", mock.Anything, "meh ran", defaultPageSize).
+		Return(&dto.SearchResult{Hits: []*dto.SearchHit{{User: &dto.User{ID: 3, NickName: "mehran"}, Score: 1.5}}, Total: 7}, nil).Once()
+	c.serviceMock.On("Search", mock.Anything, "meh", 5).Return(&dto.SearchResult{Hits: []*dto.SearchHit{}}, nil).Once()
+
+	response := c.serve(http.MethodGet, "/v1/users/search?q=meh%20ran", "")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.JSONEq(c.T(), `{"status":200,"payload":{"users":[{"id":3,"first_name":"","last_name":"","nick_name":"mehran","email":"","country":"","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","score":1.5}],"total":7}}`, response.Body.String())
+
+	response = c.serve(http.MethodGet, "/v1/users/search?q=meh&limit=5", "")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.JSONEq(c.T(), `{"status":200,"payload":{"users":[],"total":0}}`, response.Body.String())
+
+	for _, target := range []string{"/v1/users/search", "/v1/users/search?q=meh&limit=101"} {
+		response = c.serve(http.MethodGet, target, "")
+		assert.Equal(c.T(), http.StatusBadRequest, response.Code, target)
+	}
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func (c *ControllerTestSuite) TestReplaceAndPatch() {
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, FirstName: "a", LastName: "b", NickName: "c", Email: "d@gmail.com", Country: "UK", Version: 3}, "").
+		Return(constants.ErrHasNoChanges).Once()
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new", Version: 3}, "").Return(nil).Once()
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new"}, "").Return(nil).Once()
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new", Version: 2}, "").Return(constants.ErrVersionConflict).Twice()
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 2, NickName: "new", Version: 3}, "").Return(constants.ErrForbidden).Once()
+	c.serviceMock.On("GetByID", mock.Anything, int64(1)).Return(&dto.User{ID: 1, Version: 4}, nil)
+
+	// replacing the user with the same information succeeds
+	response := c.serve(http.MethodPut, "/v1/users/1", `{"first_name":"a","last_name":"b","nick_name":"c","email":"d@gmail.com","country":"UK","version":3}`)
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.Equal(c.T(), `"4"`, response.Header().Get("ETag"))
+
+	response = c.serve(http.MethodPut, "/v1/users/1", `{"nick_name":"new","version":3}`)
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+
+	// the If-Match header is preferred over the version field
+	response = c.serveIfMatch(http.MethodPatch, "/v1/users/1", `{"nick_name":"new","version":1}`, `"3"`)
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.Equal(c.T(), `"4"`, response.Header().Get("ETag"))
+
+	// * matches any version
+	response = c.serveIfMatch(http.MethodPatch, "/v1/users/1", `{"nick_name":"new"}`, "*")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+
+	response = c.serveIfMatch(http.MethodPatch, "/v1/users/1", `{"nick_name":"new"}`, `"2"`)
+	assert.Equal(c.T(), http.StatusPreconditionFailed, response.Code)
+	assert.Equal(c.T(), "precondition_failed", c.problem(response).Code)
+
+	response = c.serve(http.MethodPatch, "/v1/users/1", `{"nick_name":"new","version":2}`)
+	assert.Equal(c.T(), http.StatusConflict, response.Code)
+	assert.Equal(c.T(), "version_conflict", c.problem(response).Code)
+
+	response = c.serve(http.MethodPatch, "/v1/users/1", `{"nick_name":"new"}`)
+	assert.Equal(c.T(), http.StatusPreconditionRequired, response.Code)
+	assert.Equal(c.T(), "precondition_required", c.problem(response).Code)
+
+	response = c.serveIfMatch(http.MethodPatch, "/v1/users/1", `{"nick_name":"new"}`, "3")
+	assert.Equal(c.T(), http.StatusBadRequest, response.Code)
+	assert.Equal(c.T(), "If-Match", c.problem(response).Errors[0].Field)
+
+	response = c.serve(http.MethodPatch, "/v1/users/2", `{"nick_name":"new","version":3}`)
+	assert.Equal(c.T(), http.StatusForbidden, response.Code)
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func (c *ControllerTestSuite) TestRemove() {
+	c.serviceMock.On("Remove", mock.Anything, int64(1)).Return(nil).Once()
+	c.serviceMock.On("Remove", mock.Anything, int64(2)).Return(constants.ErrUserNotFound).Once()
+
+	response := c.serve(http.MethodDelete, "/v1/users/1", "")
+	assert.Equal(c.T(), http.StatusNoContent, response.Code)
+	assert.Empty(c.T(), response.Body.String())
+
+	assert.Equal(c.T(), http.StatusNotFound, c.serve(http.MethodDelete, "/v1/users/2", "").Code)
+
+	// the internal errors are not given to the clients
+	c.serviceMock.On("Remove", mock.Anything, int64(3)).Return(errors.New("Error 1054: Unknown column 'id' in 'where clause'")).Once()
+	response = c.serve(http.MethodDelete, "/v1/users/3", "")
+	assert.Equal(c.T(), http.StatusInternalServerError, response.Code)
+	problem := c.problem(response)
+	assert.Equal(c.T(), "internal_error", problem.Code)
+	assert.Empty(c.T(), problem.Detail)
+}
+
+func (c *ControllerTestSuite) TestRestore() {
+	c.serviceMock.On("Restore", mock.Anything, int64(1)).Return(nil).Once()
+	c.serviceMock.On("Restore", mock.Anything, int64(2)).Return(constants.ErrUserNotFound).Once()
+	c.serviceMock.On("Restore", mock.Anything, int64(3)).Return(constants.ErrUserExists).Once()
+
+	response := c.serve(http.MethodPost, "/v1/users/1/restore", "")
+	assert.Equal(c.T(), http.StatusNoContent, response.Code)
+	assert.Equal(c.T(), http.StatusNotFound, c.serve(http.MethodPost, "/v1/users/2/restore", "").Code)
+	// the email or the nickname is taken since the user was removed
+	assert.Equal(c.T(), http.StatusConflict, c.serve(http.MethodPost, "/v1/users/3/restore", "").Code)
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func (c *ControllerTestSuite) TestListDeleted() {
+	deletedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	users := []*dto.User{{ID: 1, NickName: "test", DeletedAt: &deletedAt}}
+	c.serviceMock.On("GetDeleted", mock.Anything, int64(1), int64(defaultPageSize)).Return(users, uint64(1), nil).Once()
+	c.serviceMock.On("GetDeleted", mock.Anything, int64(2), int64(5)).Return([]*dto.User{}, uint64(1), nil).Once()
+	c.serviceMock.On("GetDeleted", mock.Anything, int64(1), int64(defaultPageSize)).Return(nil, uint64(0), constants.ErrForbidden).Once()
+
+	response := c.serve(http.MethodGet, "/v1/users/deleted", "")
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.Contains(c.T(), response.Body.String(), `"deleted_at":"2022-05-01T10:00:00Z"`)
+	assert.Contains(c.T(), response.Body.String(), `"count":1`)
+
+	assert.Equal(c.T(), http.StatusOK, c.serve(http.MethodGet, "/v1/users/deleted?page=2&page_size=5", "").Code)
+	assert.Equal(c.T(), http.StatusForbidden, c.serve(http.MethodGet, "/v1/users/deleted", "").Code)
+	assert.Equal(c.T(), http.StatusBadRequest, c.serve(http.MethodGet, "/v1/users/deleted?page_size=1000", "").Code)
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func (c *ControllerTestSuite) TestDeprecatedRoutes() {
+	c.serviceMock.On("Get", mock.Anything, &dto.Filter{Countries: []string{"UK"}}, []dto.SortKey(nil), int64(1), int64(10)).Return([]*dto.User{}, uint64(0), nil).Once()
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new", Version: 3}, "").Return(nil).Once()
+	c.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new", Version: 2}, "").Return(constants.ErrVersionConflict).Once()
+
+	response := c.serve(http.MethodPost, "/v1/users/get?country=uk", `{"page":1,"page_size":10}`)
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.Equal(c.T(), "true", response.Header().Get("Deprecation"))
+	assert.Equal(c.T(), `</v1/users>; rel="successor-version"`, response.Header().Get("Link"))
+
+	response = c.serve(http.MethodPost, "/v1/users/update", `{"id":1,"nick_name":"new","version":3}`)
+	assert.Equal(c.T(), http.StatusOK, response.Code)
+	assert.Equal(c.T(), "true", response.Header().Get("Deprecation"))
+
+	response = c.serve(http.MethodPost, "/v1/users/update", `{"id":1,"nick_name":"new","version":2}`)
+	assert.Equal(c.T(), http.StatusConflict, response.Code)
+
+	// the version is needed, so the update can't overwrite the concurrent changes
+	response = c.serve(http.MethodPost, "/v1/users/update", `{"id":1,"nick_name":"new"}`)
+	assert.Equal(c.T(), http.StatusPreconditionRequired, response.Code)
+	assert.Equal(c.T(), "precondition_required", c.problem(response).Code)
+	assert.Equal(c.T(), http.StatusBadRequest, c.serve(http.MethodPost, "/v1/users/update", `{"id":1,"nick_name":"new","version":-1}`).Code)
+	c.serviceMock.AssertExpectations(c.T())
+}
+
+func TestControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ControllerTestSuite))
+}
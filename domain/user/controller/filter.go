@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxFilterValues - The most values a filter with a list of values can have, e.g. the countries
+const maxFilterValues = 100
+
+// filterKey - The query parameter of a filter, the field with an optional operator, e.g. created_at[gte]
+var filterKey = regexp.MustCompile(`^([a-z_]+)(?:\[([a-z]+)\])?$`)
+
+// parseFilter - Parses the filters in the query parameters, the other query parameters are skipped:
+//   - country=UK,DE or country[in]=UK,DE: the user is in one of the countries
+//   - nick_name, email, first_name, last_name: the field equals the value, or matches it with the [eq], [prefix] or [contains] operator.
+//     The nick name contains the value without an operator, as it always has.
+//   - id, created_at, updated_at: the field is in the range of the [gt], [gte], [lt] and [lte] operators,
+//     the times are in RFC 3339, e.g. 2022-01-02T15:04:05Z, or dates, e.g. 2022-01-02
+//
+// All the invalid filters are reported together.
+func parseFilter(query url.Values) (*dto.Filter, error) {
+	// the query parameters are in a map, they are read in order so the same query always gets the same errors
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	filter := &dto.Filter{}
+	var fieldErrors []constants.FieldError
+	for _, key := range keys {
+		match := filterKey.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+
+		if fieldError := applyFilter(filter, match[1], match[2], query[key]); fieldError != nil {
+			fieldError.Field = key
+			fieldErrors = append(fieldErrors, *fieldError)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, constants.NewValidationError("invalid query parameters", fieldErrors...)
+	}
+
+	return filter, nil
+}
+
+// applyFilter - Sets the filter of the field with the operator to the values, the field of the returned error is left empty
+func applyFilter(filter *dto.Filter, field, operator string, values []string) *constants.FieldError {
+	switch field {
+	case "country":
+		if operator != "" && operator != "in" {
+			return unknownOperator("in")
+		}
+		return applyCountries(filter, values)
+	case "nick_name":
+		return applyTextFilter(&filter.NickName, operator, dto.MatchContains, values)
+	case "email":
+		return applyTextFilter(&filter.Email, operator, dto.MatchExact, values)
+	case "first_name":
+		return applyTextFilter(&filter.FirstName, operator, dto.MatchExact, values)
+	case "last_name":
+		return applyTextFilter(&filter.LastName, operator, dto.MatchExact, values)
+	case "id":
+		return applyInt64Range(&filter.ID, operator, values)
+	case "created_at":
+		return applyTimeRange(&filter.CreatedAt, operator, values)
+	case "updated_at":
+		return applyTimeRange(&filter.UpdatedAt, operator, values)
+	default:
+		if operator != "" {
+			return &constants.FieldError{Code: "unknown_filter", Message: "can't be filtered by"}
+		}
+		// another query parameter, e.g. the page
+		return nil
+	}
+}
+
+func applyCountries(filter *dto.Filter, values []string) *constants.FieldError {
+	for _, value := range values {
+		for _, country := range strings.Split(value, ",") {
+			country = strings.TrimSpace(country)
+			if country != "" {
+				filter.Countries = append(filter.Countries, strings.ToUpper(country))
+			}
+		}
+	}
+
+	if len(filter.Countries) > maxFilterValues {
+		return &constants.FieldError{Code: "too_many_values", Message: "must have at most " + strconv.Itoa(maxFilterValues) + " values"}
+	}
+
+	return nil
+}
+
+// applyTextFilter - Sets the text filter, it's matched with the default match without an operator
+func applyTextFilter(textFilter *dto.TextFilter, operator string, defaultMatch dto.Match, values []string) *constants.FieldError {
+	match := defaultMatch
+	switch dto.Match(operator) {
+	case "":
+	case dto.MatchExact, dto.MatchPrefix, dto.MatchContains:
+		match = dto.Match(operator)
+	default:
+		return unknownOperator("eq", "prefix", "contains")
+	}
+
+	if len(values) > 1 {
+		return repeated()
+	}
+	if values[0] == "" {
+		return nil
+	}
+	if textFilter.Value != "" {
+		return &constants.FieldError{Code: "conflicting_filters", Message: "can't be given with another operator of the field"}
+	}
+
+	*textFilter = dto.TextFilter{Value: values[0], Match: match}
+	return nil
+}
+
+func applyInt64Range(int64Range *dto.Int64Range, operator string, values []string) *constants.FieldError {
+	bounds := map[string]**int64{"gt": &int64Range.Gt, "gte": &int64Range.Gte, "lt": &int64Range.Lt, "lte": &int64Range.Lte}
+	bound, found := bounds[operator]
+	if !found {
+		return unknownOperator("gt", "gte", "lt", "lte")
+	}
+	if len(values) > 1 {
+		return repeated()
+	}
+
+	value, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return &constants.FieldError{Code: "invalid_format", Message: "must be an integer"}
+	}
+
+	*bound = &value
+	return nil
+}
+
+func applyTimeRange(timeRange *dto.TimeRange, operator string, values []string) *constants.FieldError {
+	bounds := map[string]**time.Time{"gt": &timeRange.Gt, "gte": &timeRange.Gte, "lt": &timeRange.Lt, "lte": &timeRange.Lte}
+	bound, found := bounds[operator]
+	if !found {
+		return unknownOperator("gt", "gte", "lt", "lte")
+	}
+	if len(values) > 1 {
+		return repeated()
+	}
+
+	value, fieldErr := parseTime(values[0])
+	if fieldErr != nil {
+		return fieldErr
+	}
+
+	*bound = &value
+	return nil
+}
+
+// parseTime - Parses an RFC 3339 time or a date in UTC
+func parseTime(value string) (time.Time, *constants.FieldError) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse("2006-01-02", value)
+	}
+	if err != nil {
+		return time.Time{}, &constants.FieldError{Code: "invalid_format", Message: "must be an RFC 3339 time, e.g. 2022-01-02T15:04:05Z, or a date, e.g. 2022-01-02"}
+	}
+
+	return parsed, nil
+}
+
+func repeated() *constants.FieldError {
+	return &constants.FieldError{Code: "repeated", Message: "must be given once"}
+}
+
+func unknownOperator(operators ...string) *constants.FieldError {
+	return &constants.FieldError{Code: "unknown_operator", Message: "the operator must be one of " + strings.Join(operators, ", ")}
+}
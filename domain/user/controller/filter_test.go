@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FilterTestSuite struct {
+	suite.Suite
+}
+
+func (f *FilterTestSuite) TestParseFilter() {
+	minID, maxID := int64(10), int64(20)
+	from := time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC)
+	to := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		query          string
+		expectedFilter *dto.Filter
+		expectedFields []string
+	}{
+		{
+			query:          "page=2&sort=-id&with_count=true",
+			expectedFilter: &dto.Filter{},
+		},
+		{
+			query: "country=uk&nick_name=test",
+			expectedFilter: &dto.Filter{
+				Countries: []string{"UK"},
+				NickName:  dto.TextFilter{Value: "test", Match: dto.MatchContains},
+			},
+		},
+		{
+			query: "country[in]=uk,%20de,&country=nl&email=test@gmail.com&first_name[prefix]=te&last_name[contains]=st&nick_name[eq]=test",
+			expectedFilter: &dto.Filter{
+				Countries: []string{"NL", "UK", "DE"},
+				NickName:  dto.TextFilter{Value: "test", Match: dto.MatchExact},
+				Email:     dto.TextFilter{Value: "test@gmail.com", Match: dto.MatchExact},
+				FirstName: dto.TextFilter{Value: "te", Match: dto.MatchPrefix},
+				LastName:  dto.TextFilter{Value: "st", Match: dto.MatchContains},
+			},
+		},
+		{
+			query: "id[gt]=10&id[lte]=20&created_at[gte]=2022-01-02T15:04:05Z&created_at[lt]=2022-02-01&updated_at[lte]=2022-02-01",
+			expectedFilter: &dto.Filter{
+				ID:        dto.Int64Range{Gt: &minID, Lte: &maxID},
+				CreatedAt: dto.TimeRange{Gte: &from, Lt: &to},
+				UpdatedAt: dto.TimeRange{Lte: &to},
+			},
+		},
+		{
+			query:          "email=",
+			expectedFilter: &dto.Filter{},
+		},
+		{
+			query:          "id[gt]=ten&created_at[gte]=yesterday&email[gt]=a&password[eq]=secret&id=1&country[eq]=UK",
+			expectedFields: []string{"country[eq]", "created_at[gte]", "email[gt]", "id", "id[gt]", "password[eq]"},
+		},
+		{
+			query:          "email=a&email[prefix]=b",
+			expectedFields: []string{"email[prefix]"},
+		},
+		{
+			query:          "nick_name=a&nick_name=b&id[lt]=1&id[lt]=2",
+			expectedFields: []string{"id[lt]", "nick_name"},
+		},
+	}
+
+	for _, tc := range testCases {
+		query, err := url.ParseQuery(tc.query)
+		f.Require().NoError(err)
+
+		filter, err := parseFilter(query)
+		assert.Equal(f.T(), tc.expectedFilter, filter, tc.query)
+		if tc.expectedFields == nil {
+			assert.NoError(f.T(), err, tc.query)
+			continue
+		}
+
+		var validationError *constants.ValidationError
+		f.Require().True(errors.As(err, &validationError), tc.query)
+		fields := make([]string, len(validationError.Fields))
+		for i, fieldError := range validationError.Fields {
+			fields[i] = fieldError.Field
+		}
+		assert.Equal(f.T(), tc.expectedFields, fields, tc.query)
+	}
+}
+
+func TestFilterTestSuite(t *testing.T) {
+	suite.Run(t, new(FilterTestSuite))
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	userv1 "faceit/api/user/v1"
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"faceit/domain/user/service"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UsersGRPCController - Serves the user service over gRPC, see api/user/v1/user.proto
+type UsersGRPCController struct {
+	userv1.UnimplementedUserServiceServer
+	service service.IUserService
+}
+
+// NewUsersGRPCController - Creates a new gRPC user controller with dependency injection
+func NewUsersGRPCController(service service.IUserService) *UsersGRPCController {
+	return &UsersGRPCController{service: service}
+}
+
+// RegisterServices - Registers the user service on the gRPC server
+func (u *UsersGRPCController) RegisterServices(server *grpc.Server) {
+	userv1.RegisterUserServiceServer(server, u)
+}
+
+// CreateUser - Creates a user with the given user information
+func (u *UsersGRPCController) CreateUser(ctx context.Context, request *userv1.CreateUserRequest) (*userv1.CreateUserResponse, error) {
+	if request.FirstName == "" || request.LastName == "" || request.NickName == "" || request.Email == "" || request.Country == "" || request.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "first_name, last_name, nick_name, email, country and password are required")
+	}
+
+	userDTO, err := u.service.Create(ctx, &dto.User{
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		NickName:  request.NickName,
+		Email:     request.Email,
+		Country:   request.Country,
+	}, request.Password)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &userv1.CreateUserResponse{User: userToProto(userDTO)}, nil
+}
+
+// UpdateUser - Updates the given fields of the user at the given version, which is required so the stale check can't be skipped
+func (u *UsersGRPCController) UpdateUser(ctx context.Context, request *userv1.UpdateUserRequest) (*userv1.UpdateUserResponse, error) {
+	if request.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if request.Version <= 0 {
+		return nil, status.Error(codes.FailedPrecondition, "version is required")
+	}
+
+	err := u.service.Update(ctx, &dto.User{
+		ID:        request.Id,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		NickName:  request.NickName,
+		Email:     request.Email,
+		Country:   request.Country,
+		Version:   request.Version,
+	}, request.Password)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &userv1.UpdateUserResponse{}, nil
+}
+
+// RemoveUser - Removes the user with the given ID
+func (u *UsersGRPCController) RemoveUser(ctx context.Context, request *userv1.RemoveUserRequest) (*userv1.RemoveUserResponse, error) {
+	if request.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := u.service.Remove(ctx, request.Id); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &userv1.RemoveUserResponse{}, nil
+}
+
+// GetUser - Returns the user with the given ID
+func (u *UsersGRPCController) GetUser(ctx context.Context, request *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	if request.Id <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	userDTO, err := u.service.GetByID(ctx, request.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &userv1.GetUserResponse{User: userToProto(userDTO)}, nil
+}
+
+// ListUsers - Returns a page of the users matching the filters and the total count of them
+func (u *UsersGRPCController) ListUsers(ctx context.Context, request *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	if request.Page <= 0 || request.PageSize <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "page and page_size are required")
+	}
+
+	filter := &dto.Filter{}
+	if request.NickName != "" {
+		filter.NickName = dto.TextFilter{Value: request.NickName, Match: dto.MatchContains}
+	}
+	if request.Country != "" {
+		filter.Countries = []string{strings.ToUpper(request.Country)}
+	}
+
+	sort, err := parseSort(request.Sort)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	userDTOs, count, err := u.service.Get(ctx, filter, sort, request.Page, request.PageSize)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	users := make([]*userv1.User, len(userDTOs))
+	for i, userDTO := range userDTOs {
+		users[i] = userToProto(userDTO)
+	}
+
+	return &userv1.ListUsersResponse{Users: users, Count: count}, nil
+}
+
+// BatchGetUsers - Returns the users with the given IDs in the order of the IDs, and the IDs of the missing users
+func (u *UsersGRPCController) BatchGetUsers(ctx context.Context, request *userv1.BatchGetUsersRequest) (*userv1.BatchGetUsersResponse, error) {
+	if len(request.Ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "ids are required")
+	}
+
+	userDTOs, missingIDs, err := u.service.GetByIDs(ctx, request.Ids)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	users := make([]*userv1.User, len(userDTOs))
+	for i, userDTO := range userDTOs {
+		users[i] = userToProto(userDTO)
+	}
+
+	return &userv1.BatchGetUsersResponse{Users: users, MissingIds: missingIDs}, nil
+}
+
+func userToProto(user *dto.User) *userv1.User {
+	return &userv1.User{
+		Id:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		NickName:  user.NickName,
+		Email:     user.Email,
+		Country:   user.Country,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+		Version:   user.Version,
+	}
+}
+
+// grpcError - Returns the gRPC status of the errors returned by the service by their kind, like the problems of the HTTP API.
+// The unknown errors are logged and reported as internal without their message, as it may reveal e.g. the database details.
+func grpcError(err error) error {
+	var domainErr *constants.Error
+	if errors.As(err, &domainErr) {
+		return status.Error(kindCode(domainErr), domainErr.Message)
+	}
+
+	var validationErr *constants.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	log.Printf("internal error: %s\n", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// kindCode - Returns the gRPC code of the kind of the domain errors
+func kindCode(err *constants.Error) codes.Code {
+	switch {
+	// a stale version is a concurrency conflict, which the client should retry at a higher level
+	case errors.Is(err, constants.ErrVersionConflict):
+		return codes.Aborted
+	// there is nothing wrong with the arguments, the user is already in the given state
+	case errors.Is(err, constants.ErrHasNoChanges):
+		return codes.FailedPrecondition
+	}
+
+	switch err.Kind {
+	case constants.KindInvalid:
+		return codes.InvalidArgument
+	case constants.KindNotFound:
+		return codes.NotFound
+	case constants.KindConflict:
+		return codes.AlreadyExists
+	case constants.KindUnauthenticated:
+		return codes.Unauthenticated
+	case constants.KindForbidden:
+		return codes.PermissionDenied
+	case constants.KindPreconditionFailed, constants.KindPreconditionRequired:
+		return codes.FailedPrecondition
+	case constants.KindTooLarge:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
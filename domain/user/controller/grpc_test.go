@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	userv1 "faceit/api/user/v1"
+	authController "faceit/domain/auth/controller"
+	authEntity "faceit/domain/auth/entity"
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
+	"faceit/infrastructure/server"
+	authMocks "faceit/mocks/domain/auth/service"
+	mocks "faceit/mocks/domain/user/service"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCTestSuite struct {
+	suite.Suite
+	serviceMock *mocks.IUserService
+	server      *server.GRPCServer
+	conn        *grpc.ClientConn
+	client      userv1.UserServiceClient
+}
+
+func (g *GRPCTestSuite) SetupTest() {
+	g.serviceMock = &mocks.IUserService{}
+	authMock := &authMocks.IAuthService{}
+	authMock.On("Authenticate", mock.Anything, "valid").Return(&authEntity.Principal{UserID: 1}, nil)
+	authMock.On("Authenticate", mock.Anything, mock.Anything).Return(nil, constants.ErrUnauthenticated)
+
+	var err error
+	g.server, err = server.RunGRPC(
+		"127.0.0.1:0",
+		[]grpc.UnaryServerInterceptor{authController.NewAuthController(authMock).UnaryAuthenticate},
+		NewUsersGRPCController(g.serviceMock),
+	)
+	g.Require().NoError(err)
+
+	g.conn, err = grpc.Dial(g.server.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	g.Require().NoError(err)
+	g.client = userv1.NewUserServiceClient(g.conn)
+}
+
+func (g *GRPCTestSuite) TearDownTest() {
+	_ = g.conn.Close()
+	_ = g.server.Shutdown(context.Background())
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func (g *GRPCTestSuite) TestCreateUser() {
+	createdAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	g.serviceMock.On("Create", mock.Anything, &dto.User{FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK"}, "pass").
+		Return(&dto.User{ID: 1, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", CreatedAt: createdAt}, nil).Once()
+	g.serviceMock.On("Create", mock.Anything, mock.Anything, "pass").Return(nil, constants.ErrUserExists).Once()
+
+	// creating a user doesn't need a token
+	request := &userv1.CreateUserRequest{FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", Password: "pass"}
+	response, err := g.client.CreateUser(context.Background(), request)
+	g.Require().NoError(err)
+	assert.Equal(g.T(), int64(1), response.User.Id)
+	assert.Equal(g.T(), createdAt, response.User.CreatedAt.AsTime())
+
+	_, err = g.client.CreateUser(context.Background(), request)
+	assert.Equal(g.T(), codes.AlreadyExists, status.Code(err))
+
+	_, err = g.client.CreateUser(context.Background(), &userv1.CreateUserRequest{NickName: "test"})
+	assert.Equal(g.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (g *GRPCTestSuite) TestUpdateUser() {
+	g.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new", Version: 3}, "").Return(nil).Once()
+	g.serviceMock.On("Update", mock.Anything, &dto.User{ID: 1, NickName: "new", Version: 2}, "").Return(constants.ErrVersionConflict).Once()
+
+	_, err := g.client.UpdateUser(withToken("valid"), &userv1.UpdateUserRequest{Id: 1, NickName: "new", Version: 3})
+	g.Require().NoError(err)
+
+	_, err = g.client.UpdateUser(withToken("valid"), &userv1.UpdateUserRequest{Id: 1, NickName: "new", Version: 2})
+	assert.Equal(g.T(), codes.Aborted, status.Code(err))
+
+	// the version is required, so the stale check can't be skipped
+	_, err = g.client.UpdateUser(withToken("valid"), &userv1.UpdateUserRequest{Id: 1, NickName: "new"})
+	assert.Equal(g.T(), codes.FailedPrecondition, status.Code(err))
+	g.serviceMock.AssertExpectations(g.T())
+}
+
+func (g *GRPCTestSuite) TestGetUser() {
+	g.serviceMock.On("GetByID", mock.MatchedBy(func(ctx context.Context) bool {
+		principal, ok := authEntity.PrincipalFromContext(ctx)
+		return ok && principal.UserID == 1
+	}), int64(1)).Return(&dto.User{ID: 1, NickName: "test"}, nil)
+	g.serviceMock.On("GetByID", mock.Anything, int64(2)).Return(nil, constants.ErrUserNotFound)
+	g.serviceMock.On("GetByID", mock.Anything, mock.Anything).Return(nil, constants.ErrUnauthenticated)
+
+	response, err := g.client.GetUser(withToken("valid"), &userv1.GetUserRequest{Id: 1})
+	g.Require().NoError(err)
+	assert.Equal(g.T(), "test", response.User.NickName)
+
+	_, err = g.client.GetUser(withToken("valid"), &userv1.GetUserRequest{Id: 2})
+	assert.Equal(g.T(), codes.NotFound, status.Code(err))
+
+	// the service rejects the calls without a principal
+	_, err = g.client.GetUser(context.Background(), &userv1.GetUserRequest{Id: 1})
+	assert.Equal(g.T(), codes.Unauthenticated, status.Code(err))
+
+	// an invalid token is rejected before reaching the service
+	_, err = g.client.GetUser(withToken("expired"), &userv1.GetUserRequest{Id: 1})
+	assert.Equal(g.T(), codes.Unauthenticated, status.Code(err))
+	g.serviceMock.AssertNumberOfCalls(g.T(), "GetByID", 3)
+}
+
+func (g *GRPCTestSuite) TestListUsers() {
+	g.serviceMock.On("Get", mock.Anything, &dto.Filter{Countries: []string{"UK"}, NickName: dto.TextFilter{Value: "meh", Match: dto.MatchContains}}, []dto.SortKey(nil), int64(1), int64(10)).
+		Return([]*dto.User{{ID: 1}, {ID: 2}}, uint64(12), nil)
+
+	response, err := g.client.ListUsers(withToken("valid"), &userv1.ListUsersRequest{Country: "uk", NickName: "meh", Page: 1, PageSize: 10})
+	g.Require().NoError(err)
+	assert.Len(g.T(), response.Users, 2)
+	assert.Equal(g.T(), uint64(12), response.Count)
+
+	g.serviceMock.On("Get", mock.Anything, &dto.Filter{}, []dto.SortKey{{Field: "password"}}, int64(1), int64(10)).
+		Return(nil, uint64(0), constants.ErrInvalidSort)
+	_, err = g.client.ListUsers(withToken("valid"), &userv1.ListUsersRequest{Sort: "password", Page: 1, PageSize: 10})
+	assert.Equal(g.T(), codes.InvalidArgument, status.Code(err))
+
+	_, err = g.client.ListUsers(withToken("valid"), &userv1.ListUsersRequest{Sort: "-", Page: 1, PageSize: 10})
+	assert.Equal(g.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (g *GRPCTestSuite) TestBatchGetUsers() {
+	g.serviceMock.On("GetByIDs", mock.Anything, []int64{2, 1, 5}).Return([]*dto.User{{ID: 2}, {ID: 1}}, []int64{5}, nil)
+
+	response, err := g.client.BatchGetUsers(withToken("valid"), &userv1.BatchGetUsersRequest{Ids: []int64{2, 1, 5}})
+	g.Require().NoError(err)
+	g.Require().Len(response.Users, 2)
+	assert.Equal(g.T(), int64(2), response.Users[0].Id)
+	assert.Equal(g.T(), int64(1), response.Users[1].Id)
+	assert.Equal(g.T(), []int64{5}, response.MissingIds)
+
+	_, err = g.client.BatchGetUsers(withToken("valid"), &userv1.BatchGetUsersRequest{})
+	assert.Equal(g.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (g *GRPCTestSuite) TestHealth() {
+	client := healthpb.NewHealthClient(g.conn)
+	for _, service := range []string{"", userv1.UserService_ServiceDesc.ServiceName} {
+		response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		g.Require().NoError(err)
+		assert.Equal(g.T(), healthpb.HealthCheckResponse_SERVING, response.Status)
+	}
+}
+
+func (g *GRPCTestSuite) TestGRPCError() {
+	testCases := []struct {
+		err             error
+		expectedCode    codes.Code
+		expectedMessage string
+	}{
+		{err: constants.ErrUnauthenticated, expectedCode: codes.Unauthenticated},
+		{err: constants.ErrForbidden, expectedCode: codes.PermissionDenied},
+		{err: constants.ErrUserNotFound, expectedCode: codes.NotFound},
+		{err: fmt.Errorf("failed to update user: %w", constants.ErrUserExists), expectedCode: codes.AlreadyExists},
+		{err: constants.ErrNickNameExists, expectedCode: codes.AlreadyExists},
+		{err: constants.ErrHasNoChanges, expectedCode: codes.FailedPrecondition},
+		{err: constants.ErrVersionConflict, expectedCode: codes.Aborted},
+		{err: constants.ErrTooManyIDs, expectedCode: codes.InvalidArgument},
+		{err: constants.ErrPermissionNotFound, expectedCode: codes.InvalidArgument},
+		{err: constants.NewValidationError("invalid request"), expectedCode: codes.InvalidArgument},
+		{err: constants.ErrRoleNotFound, expectedCode: codes.NotFound},
+		{err: constants.ErrRoleExists, expectedCode: codes.AlreadyExists},
+		{err: constants.ErrInvalidCredentials, expectedCode: codes.Unauthenticated},
+		{err: constants.ErrPreconditionFailed, expectedCode: codes.FailedPrecondition},
+		{err: constants.ErrPreconditionRequired, expectedCode: codes.FailedPrecondition},
+		{err: constants.ErrRequestTooLarge, expectedCode: codes.ResourceExhausted},
+		{err: context.DeadlineExceeded, expectedCode: codes.DeadlineExceeded},
+		{err: context.Canceled, expectedCode: codes.Canceled},
+		{err: fmt.Errorf("failed to query database"), expectedCode: codes.Internal, expectedMessage: "internal error"},
+	}
+
+	for _, tc := range testCases {
+		grpcStatus := status.Convert(grpcError(tc.err))
+		assert.Equal(g.T(), tc.expectedCode, grpcStatus.Code(), tc.err.Error())
+		if tc.expectedMessage != "" {
+			assert.Equal(g.T(), tc.expectedMessage, grpcStatus.Message())
+		}
+	}
+}
+
+func TestGRPCTestSuite(t *testing.T) {
+	suite.Run(t, new(GRPCTestSuite))
+}
@@ -4,21 +4,89 @@ type createRequest struct {
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
 	NickName  string `json:"nick_name" binding:"required"`
-	Password  string `json:"password" binding:"required"`
+	Password  string `json:"password" binding:"required,max=72"`
 	Email     string `json:"email" binding:"required"`
 	Country   string `json:"country" binding:"required"`
 }
 
+// replaceRequest - All the information of the user, the password is optional.
+// The version is needed unless it's given in the If-Match header.
+type replaceRequest struct {
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+	NickName  string `json:"nick_name" binding:"required"`
+	Password  string `json:"password" binding:"omitempty,max=72"`
+	Email     string `json:"email" binding:"required"`
+	Country   string `json:"country" binding:"required"`
+	Version   int64  `json:"version" binding:"omitempty,min=1"`
+}
+
+// patchRequest - The fields to change, the missing ones are kept.
+// The version is needed unless it's given in the If-Match header.
+type patchRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	NickName  string `json:"nick_name"`
+	Password  string `json:"password" binding:"omitempty,max=72"`
+	Email     string `json:"email"`
+	Country   string `json:"country"`
+	Version   int64  `json:"version" binding:"omitempty,min=1"`
+}
+
+// listRequest - The users are paged by the cursor unless a page number is given, the filters are parsed by parseFilter
+type listRequest struct {
+	Sort      string `form:"sort"`
+	Cursor    string `form:"cursor"`
+	WithCount bool   `form:"with_count"`
+	Page      int64  `form:"page" binding:"omitempty,min=1"`
+	PageSize  int64  `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// pageRequest - A page by its number, e.g. of the removed users or the audit entries
+type pageRequest struct {
+	Page     int64 `form:"page" binding:"omitempty,min=1"`
+	PageSize int64 `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// auditRequest - The filters of the audit log, the entries are in the time range from inclusive to exclusive, parsed by auditFilter
+type auditRequest struct {
+	pageRequest
+	UserID  int64  `form:"user_id" binding:"omitempty,min=1"`
+	ActorID int64  `form:"actor_id" binding:"omitempty,min=1"`
+	Action  string `form:"action"`
+	From    string `form:"from"`
+	To      string `form:"to"`
+}
+
+// lookupRequest - Exactly one of the email and the nickname is given
+type lookupRequest struct {
+	Email    string `form:"email"`
+	NickName string `form:"nick_name"`
+}
+
+// searchRequest - The text is matched against the nickname, and the names and the email with the users:read:pii permission
+type searchRequest struct {
+	Query string `form:"q" binding:"required,max=200"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type batchGetRequest struct {
+	IDs []int64 `json:"ids" binding:"required,min=1"`
+}
+
+// updateRequest - The request of the deprecated POST /v1/users/update, the version is needed like for the other updates
 type updateRequest struct {
 	ID        int64  `json:"id" binding:"required"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	NickName  string `json:"nick_name"`
-	Password  string `json:"password"`
+	Password  string `json:"password" binding:"omitempty,max=72"`
 	Email     string `json:"email"`
 	Country   string `json:"country"`
+	Version   int64  `json:"version" binding:"omitempty,min=1"`
 }
 
+// getRequest - The request of the deprecated POST /v1/users/get
 type getRequest struct {
 	Page     int64 `json:"page" binding:"required"`
 	PageSize int64 `json:"page_size" binding:"required"`
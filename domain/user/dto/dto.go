@@ -1,21 +1,119 @@
 package dto
 
 import (
+	"faceit/domain/user/events"
 	"time"
 )
 
 type User struct {
-	ID        int64     `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	NickName  string    `json:"nick_name"`
-	Email     string    `json:"email"`
-	Country   string    `json:"country"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64      `json:"id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	NickName  string     `json:"nick_name"`
+	Email     string     `json:"email"`
+	Country   string     `json:"country"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Version - The version of the user, an update with a version is rejected if the user has been changed since.
+	// It's not known for the users found by the search, as the index doesn't keep it.
+	Version int64 `json:"version,omitempty"`
 }
 
+// Filter - The criteria the listed users must meet, the empty fields are not applied
 type Filter struct {
-	Country  string
-	NickName string
+	// Countries - The user is in one of the countries
+	Countries []string
+	NickName  TextFilter
+	Email     TextFilter
+	FirstName TextFilter
+	LastName  TextFilter
+	ID        Int64Range
+	CreatedAt TimeRange
+	UpdatedAt TimeRange
+}
+
+// HasPII - Tells if the filter matches the PII fields, which reveals them as much as reading them does
+func (f *Filter) HasPII() bool {
+	return f.Email.Value != "" || f.FirstName.Value != "" || f.LastName.Value != ""
+}
+
+// Match - How the value of a TextFilter is matched
+type Match string
+
+const (
+	MatchExact    Match = "eq"
+	MatchPrefix   Match = "prefix"
+	MatchContains Match = "contains"
+)
+
+// TextFilter - Matches a text field with the value, it's not applied when the value is empty
+type TextFilter struct {
+	Value string
+	Match Match
+}
+
+// Int64Range - The bounds of a number field, the nil bounds are not applied
+type Int64Range struct {
+	Gt  *int64
+	Gte *int64
+	Lt  *int64
+	Lte *int64
+}
+
+// TimeRange - The bounds of a time field, the nil bounds are not applied
+type TimeRange struct {
+	Gt  *time.Time
+	Gte *time.Time
+	Lt  *time.Time
+	Lte *time.Time
+}
+
+// SortKey - A field the users are ordered by
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// SearchHit - A user matching the searched text, a higher score is a better match
+type SearchHit struct {
+	*User
+	Score float64 `json:"score"`
+}
+
+// SearchResult - The users best matching the searched text, the best first, and the total number of the matching users
+type SearchResult struct {
+	Hits  []*SearchHit
+	Total uint64
+}
+
+// Page - A page of the users listed with a cursor, the cursors are empty when there are no more users in their direction.
+// Count is only set when it's asked for.
+type Page struct {
+	Users      []*User
+	NextCursor string
+	PrevCursor string
+	Count      *uint64
+}
+
+// AuditEntry - A change made to a user, with who made it and where the request came from
+type AuditEntry struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	Action string `json:"action"`
+	// ActorID - The user who made the change, it's missing when no user made it, e.g. a sign up or a purge
+	ActorID   *int64                   `json:"actor_id,omitempty"`
+	RequestID string                   `json:"request_id,omitempty"`
+	SourceIP  string                   `json:"source_ip,omitempty"`
+	Changes   map[string]events.Change `json:"changes,omitempty"`
+	CreatedAt time.Time                `json:"created_at"`
+}
+
+// AuditFilter - The criteria of the audit entries, the empty fields are not applied
+type AuditFilter struct {
+	UserID  *int64
+	ActorID *int64
+	// Actions - The entry is one of the actions
+	Actions   []string
+	CreatedAt TimeRange
 }
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"faceit/domain/user/dto"
+	"faceit/domain/user/events"
+	"time"
+)
+
+// AuditEntry - A change made to a user, recorded with who made it and where the request came from
+type AuditEntry struct {
+	ID     int64
+	UserID int64
+	// Action - The type of the event of the change
+	Action string
+	// ActorID - The user who made the change, nil when no user made it, e.g. a sign up or a purge
+	ActorID   *int64
+	RequestID string
+	SourceIP  string
+	Changes   map[string]events.Change
+	CreatedAt time.Time
+}
+
+// AuditFilter - The criteria of the audit entries, the empty fields are not applied
+type AuditFilter struct {
+	UserID    *int64
+	ActorID   *int64
+	Actions   []string
+	CreatedAt dto.TimeRange
+}
+
+func AuditFilterEntityFromDTO(dto *dto.AuditFilter) *AuditFilter {
+	return &AuditFilter{
+		UserID:    dto.UserID,
+		ActorID:   dto.ActorID,
+		Actions:   dto.Actions,
+		CreatedAt: dto.CreatedAt,
+	}
+}
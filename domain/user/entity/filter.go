@@ -4,14 +4,27 @@ import (
 	"faceit/domain/user/dto"
 )
 
+// Filter - The criteria of the users, the empty fields are not applied
 type Filter struct {
-	Country  string
-	NickName string
+	Countries []string
+	NickName  dto.TextFilter
+	Email     dto.TextFilter
+	FirstName dto.TextFilter
+	LastName  dto.TextFilter
+	ID        dto.Int64Range
+	CreatedAt dto.TimeRange
+	UpdatedAt dto.TimeRange
 }
 
 func FilterEntityFromDTO(dto *dto.Filter) *Filter {
 	return &Filter{
-		Country:  dto.Country,
-		NickName: dto.NickName,
+		Countries: dto.Countries,
+		NickName:  dto.NickName,
+		Email:     dto.Email,
+		FirstName: dto.FirstName,
+		LastName:  dto.LastName,
+		ID:        dto.ID,
+		CreatedAt: dto.CreatedAt,
+		UpdatedAt: dto.UpdatedAt,
 	}
 }
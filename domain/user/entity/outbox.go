@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+)
+
+// OutboxEvent - A user event waiting in the outbox to be relayed to the event sink
+type OutboxEvent struct {
+	ID      int64
+	EventID string
+	UserID  int64
+	Type    string
+	// Payload - The json encoded events.Event
+	Payload  []byte
+	Attempts int
+	// NextAttemptAt - The event is not retried before this time, zero if it has never failed
+	NextAttemptAt time.Time
+}
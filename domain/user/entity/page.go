@@ -0,0 +1,29 @@
+package entity
+
+import "faceit/domain/user/dto"
+
+// SortKey - A column the users are ordered by
+type SortKey struct {
+	Column     string
+	Descending bool
+}
+
+func SortEntityFromDTO(sort []dto.SortKey) []SortKey {
+	keys := make([]SortKey, len(sort))
+	for i, key := range sort {
+		keys[i] = SortKey{Column: key.Field, Descending: key.Descending}
+	}
+
+	return keys
+}
+
+// DefaultSort - The order of the users when no other is asked for, the ID makes the order unique
+var DefaultSort = []SortKey{{Column: "id"}}
+
+// Cursor - The position of a user in the ordered list of the users, a page starts right after or before it.
+// Values are the values of the sort keys of the user at the position.
+type Cursor struct {
+	Sort     []SortKey
+	Values   []interface{}
+	Backward bool
+}
@@ -14,4 +14,8 @@ type User struct {
 	Country   string    `json:"country"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Version - Incremented by every write of the user, the updates are only applied to the version they are based on
+	Version int64 `json:"version"`
+	// DeletedAt - When the user was removed, it's only read for the removed users
+	DeletedAt *time.Time `json:"deleted_at"`
 }
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Type - The kind of change made to a user
+type Type string
+
+const (
+	TypeUserCreated Type = "user.created"
+	TypeUserUpdated Type = "user.updated"
+	TypeUserDeleted Type = "user.deleted"
+	// TypeUserRestored - A removed user is restored before it was purged
+	TypeUserRestored Type = "user.restored"
+	// TypeUserPurged - A removed user is deleted for good after the retention period, the services should forget it
+	TypeUserPurged Type = "user.purged"
+)
+
+// Types - All the event types, in the order of the user's lifecycle
+var Types = []Type{TypeUserCreated, TypeUserUpdated, TypeUserDeleted, TypeUserRestored, TypeUserPurged}
+
+// IEventPublisher - A sink the user events are published to, e.g. a Redis stream or a NATS subject.
+// Publish must return an error unless the sink has accepted the event, so it can be retried.
+type IEventPublisher interface {
+	Publish(ctx context.Context, event *Event) error
+	Close() error
+}
+
+// Redacted - Replaces the values of the secret fields in the changes, e.g. the password
+const Redacted = "[REDACTED]"
+
+// Change - The values of a field before and after an update
+type Change struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Event - A change made to a user, as published to the other services
+type Event struct {
+	ID         string    `json:"id"`
+	Type       Type      `json:"type"`
+	UserID     int64     `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Changes - The changed fields by their json names, only set for updates
+	Changes map[string]Change `json:"changes,omitempty"`
+}
+
+// New - Creates an event with a random ID which happened now
+func New(eventType Type, userID int64, changes map[string]Change) (*Event, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		ID:         id,
+		Type:       eventType,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+		Changes:    changes,
+	}, nil
+}
+
+// IsValid - Checks whether the type is one of the known event types
+func (t Type) IsValid() bool {
+	for _, eventType := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// newID - Generates a random (version 4) UUID
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventsTestSuite struct {
+	suite.Suite
+}
+
+func (e *EventsTestSuite) TestNew() {
+	changes := map[string]Change{"country": {Before: "UK", After: "NL"}}
+	event, err := New(TypeUserUpdated, 1, changes)
+
+	assert.NoError(e.T(), err)
+	assert.Regexp(e.T(), regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), event.ID)
+	assert.Equal(e.T(), TypeUserUpdated, event.Type)
+	assert.Equal(e.T(), int64(1), event.UserID)
+	assert.Equal(e.T(), changes, event.Changes)
+	assert.WithinDuration(e.T(), time.Now(), event.OccurredAt, time.Second)
+
+	other, err := New(TypeUserUpdated, 1, changes)
+	assert.NoError(e.T(), err)
+	assert.NotEqual(e.T(), event.ID, other.ID)
+}
+
+func (e *EventsTestSuite) TestIsValid() {
+	testCases := []struct {
+		eventType Type
+		expected  bool
+	}{
+		{eventType: TypeUserCreated, expected: true},
+		{eventType: TypeUserUpdated, expected: true},
+		{eventType: TypeUserDeleted, expected: true},
+		{eventType: TypeUserPurged, expected: true},
+		{eventType: "user.renamed", expected: false},
+		{eventType: "", expected: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(e.T(), tc.expected, tc.eventType.IsValid())
+	}
+}
+
+func TestEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(EventsTestSuite))
+}
@@ -0,0 +1,54 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"faceit/domain/user/events"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FilePublisher - Appends every event as a line of json to a file (NDJSON)
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish - Appends the event and syncs the file, so an accepted event survives a crash
+func (f *FilePublisher) Publish(_ context.Context, event *events.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write event to file: %w", err)
+	}
+
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync events file: %w", err)
+	}
+
+	return nil
+}
+
+func (f *FilePublisher) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,53 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"faceit/domain/user/events"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FilePublisherTestSuite struct {
+	suite.Suite
+}
+
+func (f *FilePublisherTestSuite) TestPublish() {
+	path := filepath.Join(f.T().TempDir(), "user-events.ndjson")
+	published := []*events.Event{
+		{ID: "1", Type: events.TypeUserCreated, UserID: 1},
+		{ID: "2", Type: events.TypeUserUpdated, UserID: 1, Changes: map[string]events.Change{"country": {Before: "NL", After: "UK"}}},
+	}
+
+	// the file is appended to, so the events of the previous runs are kept
+	for _, event := range published {
+		publisher, err := NewFilePublisher(path)
+		assert.NoError(f.T(), err)
+		assert.NoError(f.T(), publisher.Publish(context.Background(), event))
+		assert.NoError(f.T(), publisher.Close())
+	}
+
+	file, err := os.Open(path)
+	assert.NoError(f.T(), err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var read []*events.Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		event := new(events.Event)
+		assert.NoError(f.T(), json.Unmarshal(scanner.Bytes(), event))
+		read = append(read, event)
+	}
+	assert.Equal(f.T(), published, read)
+}
+
+func TestFilePublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(FilePublisherTestSuite))
+}
@@ -0,0 +1,50 @@
+package sink
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"fmt"
+	"strings"
+)
+
+// MultiPublisher - Publishes every event to all the publishers.
+// An event which fails on any of them is retried on all of them, so the publishers must tolerate duplicates.
+type MultiPublisher struct {
+	publishers []events.IEventPublisher
+}
+
+func NewMultiPublisher(publishers ...events.IEventPublisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+// Publish - Publishes the event to every publisher, even if some of them fail
+func (m *MultiPublisher) Publish(ctx context.Context, event *events.Event) error {
+	var failures []string
+	for _, publisher := range m.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to publish event: %s", strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// Close - Closes all the publishers
+func (m *MultiPublisher) Close() error {
+	var failures []string
+	for _, publisher := range m.publishers {
+		if err := publisher.Close(); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to close publishers: %s", strings.Join(failures, "; "))
+	}
+
+	return nil
+}
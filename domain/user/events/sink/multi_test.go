@@ -0,0 +1,57 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/user/events"
+	eventsMocks "faceit/mocks/domain/user/events"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MultiPublisherTestSuite struct {
+	suite.Suite
+}
+
+func (m *MultiPublisherTestSuite) TestPublish() {
+	event := &events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1}
+	testCases := []struct {
+		firstError    error
+		secondError   error
+		expectedError bool
+	}{
+		{firstError: nil, secondError: nil, expectedError: false},
+		// the other publishers still get the event
+		{firstError: errors.New("connection refused"), secondError: nil, expectedError: true},
+		{firstError: nil, secondError: errors.New("connection refused"), expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		first := eventsMocks.IEventPublisher{}
+		second := eventsMocks.IEventPublisher{}
+		first.On("Publish", context.Background(), event).Return(tc.firstError).Once()
+		second.On("Publish", context.Background(), event).Return(tc.secondError).Once()
+
+		err := NewMultiPublisher(&first, &second).Publish(context.Background(), event)
+		assert.Equal(m.T(), tc.expectedError, err != nil)
+		first.AssertExpectations(m.T())
+		second.AssertExpectations(m.T())
+	}
+}
+
+func (m *MultiPublisherTestSuite) TestClose() {
+	first := eventsMocks.IEventPublisher{}
+	second := eventsMocks.IEventPublisher{}
+	first.On("Close").Return(errors.New("already closed")).Once()
+	second.On("Close").Return(nil).Once()
+
+	assert.Error(m.T(), NewMultiPublisher(&first, &second).Close())
+	first.AssertExpectations(m.T())
+	second.AssertExpectations(m.T())
+}
+
+func TestMultiPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(MultiPublisherTestSuite))
+}
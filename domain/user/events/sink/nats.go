@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"faceit/domain/user/events"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSConfig - Where the user events are published in NATS
+type NATSConfig struct {
+	URL string
+	// SubjectPrefix - The events are published to <prefix>.<type>, e.g. user-events.user.created,
+	// so the subscribers can pick the types with the subject wildcards
+	SubjectPrefix string
+	// Timeout - How long to wait for the server to accept an event
+	Timeout time.Duration
+}
+
+// NATSPublisher - Publishes the events to NATS subjects
+type NATSPublisher struct {
+	conn   *nats.Conn
+	config NATSConfig
+}
+
+func NewNATSPublisher(config NATSConfig) (*NATSPublisher, error) {
+	conn, err := nats.Connect(config.URL, nats.Name("user-manager"), nats.Timeout(config.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	return &NATSPublisher{conn: conn, config: config}, nil
+}
+
+// Subject - The subject the events of the given type are published to
+func (n *NATSPublisher) Subject(eventType events.Type) string {
+	return n.config.SubjectPrefix + "." + string(eventType)
+}
+
+// Publish - Publishes the event and waits until the server has received it
+func (n *NATSPublisher) Publish(_ context.Context, event *events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	msg := nats.NewMsg(n.Subject(event.Type))
+	msg.Data = payload
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	if err := n.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish event to nats: %w", err)
+	}
+
+	// the publish is buffered by the client, flushing makes sure the server got it
+	if err := n.conn.FlushTimeout(n.config.Timeout); err != nil {
+		return fmt.Errorf("failed to publish event to nats: %w", err)
+	}
+
+	return nil
+}
+
+// Close - Flushes the pending events and closes the connection
+func (n *NATSPublisher) Close() error {
+	return n.conn.Drain()
+}
@@ -0,0 +1,80 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"faceit/domain/user/events"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsServer "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type NATSPublisherTestSuite struct {
+	suite.Suite
+	server *server.Server
+}
+
+func (n *NATSPublisherTestSuite) SetupTest() {
+	options := natsServer.DefaultTestOptions
+	options.Port = server.RANDOM_PORT
+	n.server = natsServer.RunServer(&options)
+}
+
+func (n *NATSPublisherTestSuite) TearDownTest() {
+	n.server.Shutdown()
+}
+
+func (n *NATSPublisherTestSuite) TestPublish() {
+	subscriber, err := nats.Connect(n.server.ClientURL())
+	assert.NoError(n.T(), err)
+	defer subscriber.Close()
+
+	// the subscribers pick the event types with the subject wildcards
+	messages := make(chan *nats.Msg, 10)
+	_, err = subscriber.ChanSubscribe("user-events.user.deleted", messages)
+	assert.NoError(n.T(), err)
+	assert.NoError(n.T(), subscriber.Flush())
+
+	publisher, err := NewNATSPublisher(NATSConfig{URL: n.server.ClientURL(), SubjectPrefix: "user-events", Timeout: time.Second})
+	assert.NoError(n.T(), err)
+	defer func() {
+		_ = publisher.Close()
+	}()
+
+	deleted := &events.Event{ID: "2", Type: events.TypeUserDeleted, UserID: 1}
+	assert.NoError(n.T(), publisher.Publish(context.Background(), &events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1}))
+	assert.NoError(n.T(), publisher.Publish(context.Background(), deleted))
+
+	select {
+	case msg := <-messages:
+		assert.Equal(n.T(), "user-events.user.deleted", msg.Subject)
+		assert.Equal(n.T(), "2", msg.Header.Get(nats.MsgIdHdr))
+		event := new(events.Event)
+		assert.NoError(n.T(), json.Unmarshal(msg.Data, event))
+		assert.Equal(n.T(), deleted, event)
+	case <-time.After(time.Second):
+		n.T().Fatal("the event was not received")
+	}
+	assert.Empty(n.T(), messages)
+}
+
+func (n *NATSPublisherTestSuite) TestPublishFailure() {
+	publisher, err := NewNATSPublisher(NATSConfig{URL: n.server.ClientURL(), SubjectPrefix: "user-events", Timeout: 100 * time.Millisecond})
+	assert.NoError(n.T(), err)
+	defer func() {
+		_ = publisher.Close()
+	}()
+	n.server.Shutdown()
+
+	err = publisher.Publish(context.Background(), &events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1})
+	assert.Error(n.T(), err)
+}
+
+func TestNATSPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(NATSPublisherTestSuite))
+}
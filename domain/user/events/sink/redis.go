@@ -0,0 +1,64 @@
+package sink
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"faceit/domain/user/events/stream"
+	"fmt"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	// UserEventsStreamKey - The stream of the events of the users
+	UserEventsStreamKey = "user-events-stream"
+	// UserChangesRedisKey - The list of the IDs of the changed users.
+	// Deprecated: consume the UserEventsStreamKey stream with a consumer group instead, the list is only written while the legacy list is enabled.
+	UserChangesRedisKey = "user-changes"
+)
+
+// RedisConfig - Where the user events are published in Redis
+type RedisConfig struct {
+	// Stream - The key of the stream, UserEventsStreamKey by default
+	Stream string
+	// MaxLen - The stream is trimmed to about this many entries, zero keeps all of them
+	MaxLen int64
+	// LegacyList - Whether the bare user IDs are still pushed into the UserChangesRedisKey list, until its consumers move to the stream
+	LegacyList bool
+}
+
+// RedisPublisher - Adds the events to a Redis stream
+type RedisPublisher struct {
+	redis  redis.UniversalClient
+	config RedisConfig
+}
+
+func NewRedisPublisher(redis redis.UniversalClient, config RedisConfig) *RedisPublisher {
+	if config.Stream == "" {
+		config.Stream = UserEventsStreamKey
+	}
+
+	return &RedisPublisher{redis: redis, config: config}
+}
+
+// Publish - Adds the event to the stream, and pushes the user ID into the legacy list in the same transaction
+func (r *RedisPublisher) Publish(_ context.Context, event *events.Event) error {
+	_, err := r.redis.TxPipelined(func(pipe redis.Pipeliner) error {
+		if err := stream.Add(pipe, r.config.Stream, r.config.MaxLen, event); err != nil {
+			return err
+		}
+		if r.config.LegacyList {
+			pipe.RPush(UserChangesRedisKey, event.UserID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish event to redis: %w", err)
+	}
+	return nil
+}
+
+// Close - The client is shared, so it is closed by its owner
+func (r *RedisPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,70 @@
+package sink
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"faceit/domain/user/events/stream"
+	redisMocks "faceit/mocks/infrastructure/redis"
+	"strconv"
+	"testing"
+
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RedisPublisherTestSuite struct {
+	suite.Suite
+}
+
+func (r *RedisPublisherTestSuite) TestPublish() {
+	testCases := []struct {
+		event      *events.Event
+		legacyList bool
+	}{
+		{event: &events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1}, legacyList: true},
+		{event: &events.Event{ID: "2", Type: events.TypeUserDeleted, UserID: 2}, legacyList: false},
+	}
+
+	for _, tc := range testCases {
+		redisMock := redisMocks.NewRedisMock()
+		redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
+			Addrs: []string{redisMock.Addr()},
+		})
+		publisher := NewRedisPublisher(redisClient, RedisConfig{MaxLen: 1000, LegacyList: tc.legacyList})
+
+		err := publisher.Publish(context.Background(), tc.event)
+		assert.Nil(r.T(), err)
+
+		messages, err := redisClient.XRange(UserEventsStreamKey, "-", "+").Result()
+		assert.NoError(r.T(), err)
+		assert.Len(r.T(), messages, 1)
+		event, err := stream.Decode(messages[0])
+		assert.NoError(r.T(), err)
+		assert.Equal(r.T(), tc.event, event)
+
+		userIDs, err := redisClient.LRange(UserChangesRedisKey, 0, -1).Result()
+		assert.NoError(r.T(), err)
+		if tc.legacyList {
+			assert.Equal(r.T(), []string{strconv.FormatInt(tc.event.UserID, 10)}, userIDs)
+		} else {
+			assert.Empty(r.T(), userIDs)
+		}
+	}
+}
+
+func (r *RedisPublisherTestSuite) TestPublishFailure() {
+	redisMock := redisMocks.NewRedisMock()
+	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs: []string{redisMock.Addr()},
+	})
+	publisher := NewRedisPublisher(redisClient, RedisConfig{})
+	redisMock.Close()
+
+	err := publisher.Publish(context.Background(), &events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1})
+	assert.Error(r.T(), err)
+}
+
+func TestRedisPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisPublisherTestSuite))
+}
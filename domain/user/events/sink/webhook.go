@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"faceit/domain/user/events"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookConfig - Where the user events are posted
+type WebhookConfig struct {
+	URL     string
+	Timeout time.Duration
+}
+
+// WebhookPublisher - Posts every event as json to an HTTP endpoint
+type WebhookPublisher struct {
+	client *http.Client
+	config WebhookConfig
+}
+
+func NewWebhookPublisher(config WebhookConfig) *WebhookPublisher {
+	return &WebhookPublisher{
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+	}
+}
+
+// Publish - Posts the event, any status other than 2xx is a failure
+func (w *WebhookPublisher) Publish(ctx context.Context, event *events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-ID", event.ID)
+	request.Header.Set("X-Event-Type", string(event.Type))
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post event to webhook: %w", err)
+	}
+
+	defer func(body io.ReadCloser) {
+		_, _ = io.Copy(io.Discard, body)
+		_ = body.Close()
+	}(response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+func (w *WebhookPublisher) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
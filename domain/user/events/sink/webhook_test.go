@@ -0,0 +1,56 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"faceit/domain/user/events"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookPublisherTestSuite struct {
+	suite.Suite
+}
+
+func (w *WebhookPublisherTestSuite) TestPublish() {
+	testCases := []struct {
+		status        int
+		expectedError bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusAccepted},
+		{status: http.StatusInternalServerError, expectedError: true},
+		{status: http.StatusNotFound, expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		var received *events.Event
+		status := tc.status
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			assert.Equal(w.T(), http.MethodPost, request.Method)
+			assert.Equal(w.T(), "application/json", request.Header.Get("Content-Type"))
+			assert.Equal(w.T(), "user.updated", request.Header.Get("X-Event-Type"))
+			received = new(events.Event)
+			assert.NoError(w.T(), json.NewDecoder(request.Body).Decode(received))
+			writer.WriteHeader(status)
+		}))
+
+		event := &events.Event{ID: "1", Type: events.TypeUserUpdated, UserID: 1, Changes: map[string]events.Change{"country": {Before: "NL", After: "UK"}}}
+		publisher := NewWebhookPublisher(WebhookConfig{URL: server.URL, Timeout: time.Second})
+		err := publisher.Publish(context.Background(), event)
+		assert.Equal(w.T(), tc.expectedError, err != nil)
+		assert.Equal(w.T(), event, received)
+
+		_ = publisher.Close()
+		server.Close()
+	}
+}
+
+func TestWebhookPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookPublisherTestSuite))
+}
@@ -0,0 +1,218 @@
+package stream
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Handler - Handles an event, the entry is acknowledged only when it returns nil and redelivered otherwise
+type Handler func(ctx context.Context, event *events.Event) error
+
+// ConsumerConfig - Where and how a consumer reads the events
+type ConsumerConfig struct {
+	Stream string
+	// Group - Every service reading the stream has its own group, and with it its own position in the stream
+	Group string
+	// Consumer - The name of this instance in the group, it must be stable across restarts, e.g. the hostname
+	Consumer string
+	// Count - The maximum number of entries read at once
+	Count int64
+	// Block - How long a read waits for new entries, a cancelled Consume returns only after it. Zero waits forever and a negative value doesn't wait.
+	Block time.Duration
+	// MinIdle - The unacknowledged entries are claimed from their consumer, e.g. because it crashed, after being idle for this long.
+	// The failed entries of the consumer itself are retried after the same delay.
+	MinIdle time.Duration
+	// MaxDeliveries - The entries delivered more often are acknowledged without being handled, zero means no limit
+	MaxDeliveries int64
+	// MinBackoff - The delay before polling again after a poll failed, e.g. when Redis is failing over, doubled on each failure in a row
+	MinBackoff time.Duration
+	// MaxBackoff - The longest delay between two polls which failed
+	MaxBackoff time.Duration
+}
+
+// The backoff of the consumers configured without one
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// Consumer - Reads the events of a stream as a member of a consumer group
+type Consumer struct {
+	redis  redis.UniversalClient
+	config ConsumerConfig
+}
+
+func NewConsumer(redis redis.UniversalClient, config ConsumerConfig) *Consumer {
+	return &Consumer{redis: redis, config: config}
+}
+
+// CreateGroup - Creates the consumer group and the stream if they don't exist.
+// The start is the ID after which the group reads, "0" for all the entries kept in the stream or "$" for only the new ones.
+func (c *Consumer) CreateGroup(start string) error {
+	err := c.redis.XGroupCreateMkStream(c.config.Stream, c.config.Group, start).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", c.config.Group, err)
+	}
+
+	return nil
+}
+
+// Consume - Handles the events until the context is cancelled.
+// A failed poll is logged and retried with a backoff, so the consumer outlives the outages of Redis.
+func (c *Consumer) Consume(ctx context.Context, handler Handler) error {
+	failures := 0
+	for ctx.Err() == nil {
+		if _, err := c.Poll(ctx, handler); err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			failures++
+			delay := c.backoff(failures)
+			log.Printf("failed to poll stream %s, retrying in %s: %s", c.config.Stream, delay, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		failures = 0
+	}
+
+	return nil
+}
+
+// backoff - The delay after the given number of failed polls in a row, doubled on each one up to the maximum
+func (c *Consumer) backoff(failures int) time.Duration {
+	minBackoff, maxBackoff := c.config.MinBackoff, c.config.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	delay := minBackoff
+	for i := 1; i < failures && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+// Poll - Handles the claimable pending entries and then one batch of new entries, and returns the number of the handled ones
+func (c *Consumer) Poll(ctx context.Context, handler Handler) (int, error) {
+	claimed, err := c.claim()
+	if err != nil {
+		return 0, err
+	}
+
+	handled := c.handle(ctx, claimed, handler)
+
+	streams, err := c.redis.XReadGroup(&redis.XReadGroupArgs{
+		Group:    c.config.Group,
+		Consumer: c.config.Consumer,
+		Streams:  []string{c.config.Stream, ">"},
+		Count:    c.config.Count,
+		Block:    c.config.Block,
+	}).Result()
+	if err == redis.Nil {
+		return handled, nil
+	}
+	if err != nil {
+		return handled, fmt.Errorf("failed to read stream %s: %w", c.config.Stream, err)
+	}
+
+	for _, stream := range streams {
+		handled += c.handle(ctx, stream.Messages, handler)
+	}
+
+	return handled, nil
+}
+
+// claim - Takes over the entries which have not been acknowledged for MinIdle, and drops the ones delivered too often
+func (c *Consumer) claim() ([]redis.XMessage, error) {
+	pending, err := c.redis.XPendingExt(&redis.XPendingExtArgs{
+		Stream: c.config.Stream,
+		Group:  c.config.Group,
+		Start:  "-",
+		End:    "+",
+		Count:  c.config.Count,
+	}).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the pending entries of stream %s: %w", c.config.Stream, err)
+	}
+
+	var ids []string
+	for _, entry := range pending {
+		if entry.Idle < c.config.MinIdle {
+			continue
+		}
+
+		if c.config.MaxDeliveries > 0 && entry.RetryCount >= c.config.MaxDeliveries {
+			log.Printf("dropping stream entry %s of %s after %d deliveries", entry.Id, c.config.Stream, entry.RetryCount)
+			if err := c.redis.XAck(c.config.Stream, c.config.Group, entry.Id).Err(); err != nil {
+				return nil, fmt.Errorf("failed to acknowledge stream entry %s: %w", entry.Id, err)
+			}
+			continue
+		}
+
+		ids = append(ids, entry.Id)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// an entry claimed by another consumer in the meantime is not idle anymore, so it is not returned
+	messages, err := c.redis.XClaim(&redis.XClaimArgs{
+		Stream:   c.config.Stream,
+		Group:    c.config.Group,
+		Consumer: c.config.Consumer,
+		MinIdle:  c.config.MinIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim the pending entries of stream %s: %w", c.config.Stream, err)
+	}
+
+	return messages, nil
+}
+
+// handle - Passes the entries to the handler and acknowledges the handled ones
+func (c *Consumer) handle(ctx context.Context, messages []redis.XMessage, handler Handler) int {
+	handled := 0
+	for _, message := range messages {
+		event, err := Decode(message)
+		if err != nil {
+			// it can never be decoded, so retrying it is pointless
+			log.Printf("dropping stream entry %s of %s: %s", message.ID, c.config.Stream, err)
+		} else if err := handler(ctx, event); err != nil {
+			log.Printf("failed to handle event %s, it will be redelivered: %s", event.ID, err)
+			continue
+		} else {
+			handled++
+		}
+
+		if err := c.redis.XAck(c.config.Stream, c.config.Group, message.ID).Err(); err != nil {
+			log.Printf("failed to acknowledge stream entry %s of %s: %s", message.ID, c.config.Stream, err)
+		}
+	}
+
+	return handled
+}
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"encoding/json"
+	"faceit/domain/user/events"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis"
+)
+
+// The fields of a stream entry. The type and the user ID are repeated next to the event,
+// so the entries can be inspected with redis-cli without decoding them.
+const (
+	fieldEvent  = "event"
+	fieldType   = "type"
+	fieldUserID = "user_id"
+)
+
+// Add - Appends the event to the stream, which is trimmed to about maxLen entries when maxLen is positive.
+// The client can be a pipeline, in which case the error of the command is returned by its Exec.
+func Add(client redis.Cmdable, stream string, maxLen int64, event *events.Event) error {
+	values, err := Encode(event)
+	if err != nil {
+		return err
+	}
+
+	return client.XAdd(&redis.XAddArgs{
+		Stream:       stream,
+		MaxLenApprox: maxLen,
+		Values:       values,
+	}).Err()
+}
+
+// Encode - Converts the event to the fields of a stream entry
+func Encode(event *events.Event) (map[string]interface{}, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return map[string]interface{}{
+		fieldEvent:  string(payload),
+		fieldType:   string(event.Type),
+		fieldUserID: strconv.FormatInt(event.UserID, 10),
+	}, nil
+}
+
+// Decode - Reads the event of a stream entry
+func Decode(message redis.XMessage) (*events.Event, error) {
+	payload, ok := message.Values[fieldEvent].(string)
+	if !ok {
+		return nil, fmt.Errorf("stream entry %s has no event", message.ID)
+	}
+
+	event := new(events.Event)
+	if err := json.Unmarshal([]byte(payload), event); err != nil {
+		return nil, fmt.Errorf("failed to decode the event of stream entry %s: %w", message.ID, err)
+	}
+
+	return event, nil
+}
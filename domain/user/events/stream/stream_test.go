@@ -0,0 +1,199 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/user/events"
+	redisMocks "faceit/mocks/infrastructure/redis"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const testStream = "user-events-stream"
+
+type StreamTestSuite struct {
+	suite.Suite
+	redis  *miniredis.Miniredis
+	client redis.UniversalClient
+}
+
+func (s *StreamTestSuite) SetupTest() {
+	s.redis = redisMocks.NewRedisMock()
+	s.client = redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs: []string{s.redis.Addr()},
+	})
+}
+
+func (s *StreamTestSuite) newConsumer(group, consumer string) *Consumer {
+	c := NewConsumer(s.client, ConsumerConfig{
+		Stream:        testStream,
+		Group:         group,
+		Consumer:      consumer,
+		Count:         10,
+		Block:         -1,
+		MinIdle:       time.Minute,
+		MaxDeliveries: 3,
+	})
+	assert.NoError(s.T(), c.CreateGroup("0"))
+	return c
+}
+
+func (s *StreamTestSuite) add(events ...*events.Event) {
+	for _, event := range events {
+		assert.NoError(s.T(), Add(s.client, testStream, 1000, event))
+	}
+}
+
+func (s *StreamTestSuite) TestEncodeDecode() {
+	event := &events.Event{
+		ID:         "1",
+		Type:       events.TypeUserUpdated,
+		UserID:     7,
+		OccurredAt: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+		Changes:    map[string]events.Change{"country": {Before: "NL", After: "UK"}},
+	}
+
+	values, err := Encode(event)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), "user.updated", values[fieldType])
+	assert.Equal(s.T(), "7", values[fieldUserID])
+
+	decoded, err := Decode(redis.XMessage{ID: "1-0", Values: values})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), event, decoded)
+
+	_, err = Decode(redis.XMessage{ID: "1-0", Values: map[string]interface{}{}})
+	assert.Error(s.T(), err)
+}
+
+func (s *StreamTestSuite) TestAddTrimsStream() {
+	for i := 0; i < 5; i++ {
+		assert.NoError(s.T(), Add(s.client, testStream, 3, &events.Event{ID: "1", Type: events.TypeUserCreated}))
+	}
+
+	length, err := s.client.XLen(testStream).Result()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(3), length)
+}
+
+func (s *StreamTestSuite) TestGroupsHaveTheirOwnCursor() {
+	s.add(&events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1}, &events.Event{ID: "2", Type: events.TypeUserDeleted, UserID: 1})
+
+	for _, group := range []string{"billing", "search"} {
+		consumer := s.newConsumer(group, "instance-1")
+		var received []string
+		handled, err := consumer.Poll(context.Background(), func(ctx context.Context, event *events.Event) error {
+			received = append(received, event.ID)
+			return nil
+		})
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), 2, handled)
+		assert.Equal(s.T(), []string{"1", "2"}, received)
+
+		// acknowledged entries are not delivered again
+		handled, err = consumer.Poll(context.Background(), func(ctx context.Context, event *events.Event) error {
+			return nil
+		})
+		assert.NoError(s.T(), err)
+		assert.Equal(s.T(), 0, handled)
+	}
+}
+
+func (s *StreamTestSuite) TestReclaim() {
+	s.add(&events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1})
+	crashed := s.newConsumer("billing", "instance-1")
+	survivor := s.newConsumer("billing", "instance-2")
+
+	// the first consumer reads the entry but never acknowledges it
+	handled, err := crashed.Poll(context.Background(), func(ctx context.Context, event *events.Event) error {
+		return errors.New("crashed")
+	})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, handled)
+
+	// the entry is not claimed before it's idle for MinIdle
+	var received []string
+	handler := func(ctx context.Context, event *events.Event) error {
+		received = append(received, event.ID)
+		return nil
+	}
+	handled, err = survivor.Poll(context.Background(), handler)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, handled)
+
+	s.redis.SetTime(time.Now().Add(2 * time.Minute))
+	handled, err = survivor.Poll(context.Background(), handler)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 1, handled)
+	assert.Equal(s.T(), []string{"1"}, received)
+
+	pending, err := s.client.XPending(testStream, "billing").Result()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), pending.Count)
+}
+
+func (s *StreamTestSuite) TestMaxDeliveries() {
+	s.add(&events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1})
+	consumer := s.newConsumer("billing", "instance-1")
+	failing := func(ctx context.Context, event *events.Event) error {
+		return errors.New("failed")
+	}
+
+	now := time.Now()
+	for i := 1; i <= 4; i++ {
+		_, err := consumer.Poll(context.Background(), failing)
+		assert.NoError(s.T(), err)
+		s.redis.SetTime(now.Add(time.Duration(i) * 2 * time.Minute))
+	}
+
+	pending, err := s.client.XPending(testStream, "billing").Result()
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(0), pending.Count)
+}
+
+func (s *StreamTestSuite) TestConsumeOutlivesFailures() {
+	s.add(&events.Event{ID: "1", Type: events.TypeUserCreated, UserID: 1})
+	consumer := s.newConsumer("search", "instance-1")
+	consumer.config.MinBackoff = 10 * time.Millisecond
+
+	// the first polls fail, e.g. while Redis is failing over
+	s.redis.SetError("LOADING Redis is loading the dataset in memory")
+	time.AfterFunc(30*time.Millisecond, func() { s.redis.SetError("") })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var handled []string
+	err := consumer.Consume(ctx, func(ctx context.Context, event *events.Event) error {
+		handled = append(handled, event.ID)
+		cancel()
+		return nil
+	})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"1"}, handled)
+}
+
+func (s *StreamTestSuite) TestBackoff() {
+	testCases := []struct {
+		failures      int
+		expectedDelay time.Duration
+	}{
+		{failures: 1, expectedDelay: time.Second},
+		{failures: 3, expectedDelay: 4 * time.Second},
+		{failures: 10, expectedDelay: 30 * time.Second},
+	}
+
+	consumer := NewConsumer(s.client, ConsumerConfig{MinBackoff: time.Second, MaxBackoff: 30 * time.Second})
+	for _, tc := range testCases {
+		assert.Equal(s.T(), tc.expectedDelay, consumer.backoff(tc.failures))
+	}
+	assert.Equal(s.T(), defaultMinBackoff, NewConsumer(s.client, ConsumerConfig{}).backoff(1))
+}
+
+func TestStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamTestSuite))
+}
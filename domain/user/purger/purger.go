@@ -0,0 +1,86 @@
+package purger
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/user/events"
+	"faceit/domain/user/repository"
+	"log"
+	"time"
+)
+
+// Config - The tuning of the purger
+type Config struct {
+	// PollInterval - How often the expired users are looked for
+	PollInterval time.Duration
+	// BatchSize - The maximum number of users purged at once
+	BatchSize int64
+	// Retention - How long the removed users are kept before they are deleted for good, they are kept forever without it
+	Retention time.Duration
+}
+
+// Purger - Deletes the removed users for good once they are kept for the retention period, and publishes their purged events.
+// Every replica runs the purger, a user purged or restored in the meantime by another replica is skipped.
+type Purger struct {
+	repository repository.IUsersRepository
+	config     Config
+}
+
+func NewPurger(repository repository.IUsersRepository, config Config) *Purger {
+	return &Purger{repository: repository, config: config}
+}
+
+// Run - Purges the expired users until the context is cancelled
+func (p *Purger) Run(ctx context.Context) {
+	if p.config.Retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		count, err := p.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("failed to purge the removed users: %s", err)
+		}
+		if count > 0 {
+			log.Printf("purged %d removed users", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired - Purges one batch of the users removed before the retention period and returns the number of the purged ones
+func (p *Purger) PurgeExpired(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().UTC().Add(-p.config.Retention)
+	IDs, err := p.repository.GetExpiredIDs(ctx, deletedBefore, p.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, ID := range IDs {
+		event, err := events.New(events.TypeUserPurged, ID, nil)
+		if err != nil {
+			return purged, err
+		}
+
+		err = p.repository.Purge(ctx, ID, deletedBefore, event)
+		if errors.Is(err, constants.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,98 @@
+package purger
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/user/events"
+	repositoryMocks "faceit/mocks/domain/user/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type PurgerTestSuite struct {
+	suite.Suite
+}
+
+var testConfig = Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    100,
+	Retention:    30 * 24 * time.Hour,
+}
+
+func (p *PurgerTestSuite) TestPurgeExpired() {
+	failure := errors.New("connection refused")
+	testCases := []struct {
+		expiredIDs       []int64
+		expiredError     error
+		purgeErrors      map[int64]error
+		expectedAttempts []int64
+		expectedCount    int
+		expectedError    error
+	}{
+		{expiredIDs: []int64{1, 2, 3}, expectedAttempts: []int64{1, 2, 3}, expectedCount: 3},
+		{expiredIDs: nil, expectedCount: 0},
+		// restored or purged by another replica since it was listed
+		{expiredIDs: []int64{1, 2}, purgeErrors: map[int64]error{1: constants.ErrUserNotFound}, expectedAttempts: []int64{1, 2}, expectedCount: 1},
+		// the rest is purged on the next poll
+		{expiredIDs: []int64{1, 2, 3}, purgeErrors: map[int64]error{2: failure}, expectedAttempts: []int64{1, 2}, expectedCount: 1, expectedError: failure},
+		{expiredError: failure, expectedError: failure},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := &repositoryMocks.IUsersRepository{}
+		before := time.Now().UTC().Add(-testConfig.Retention)
+
+		var deletedBefore time.Time
+		repositoryMock.On("GetExpiredIDs", mock.Anything, mock.AnythingOfType("time.Time"), testConfig.BatchSize).
+			Run(func(args mock.Arguments) {
+				deletedBefore = args.Get(1).(time.Time)
+			}).
+			Return(tc.expiredIDs, tc.expiredError)
+
+		var attempts []int64
+		for _, ID := range tc.expiredIDs {
+			ID := ID
+			repositoryMock.On("Purge", mock.Anything, ID, mock.AnythingOfType("time.Time"), mock.MatchedBy(func(event *events.Event) bool {
+				return event.Type == events.TypeUserPurged && event.UserID == ID
+			})).
+				Run(func(args mock.Arguments) {
+					assert.Equal(p.T(), deletedBefore, args.Get(2).(time.Time))
+					attempts = append(attempts, ID)
+				}).
+				Return(tc.purgeErrors[ID]).Maybe()
+		}
+
+		count, err := NewPurger(repositoryMock, testConfig).PurgeExpired(context.Background())
+		assert.Equal(p.T(), tc.expectedError, err)
+		assert.Equal(p.T(), tc.expectedCount, count)
+		assert.Equal(p.T(), tc.expectedAttempts, attempts)
+		assert.False(p.T(), deletedBefore.Before(before))
+		assert.True(p.T(), deletedBefore.Before(time.Now().UTC().Add(-testConfig.Retention+time.Second)))
+	}
+}
+
+func (p *PurgerTestSuite) TestRunWithoutRetention() {
+	repositoryMock := &repositoryMocks.IUsersRepository{}
+
+	done := make(chan struct{})
+	go func() {
+		NewPurger(repositoryMock, Config{PollInterval: time.Millisecond}).Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		p.Fail("the purger runs without a retention period")
+	}
+	repositoryMock.AssertNotCalled(p.T(), "GetExpiredIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPurgerTestSuite(t *testing.T) {
+	suite.Run(t, new(PurgerTestSuite))
+}
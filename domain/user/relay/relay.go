@@ -0,0 +1,210 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	"faceit/domain/user/repository"
+	"faceit/infrastructure/database"
+	"fmt"
+	"log"
+	"time"
+)
+
+// lockName - Only the replica holding this lock relays the events
+const lockName = "user_events_outbox_relay"
+
+// errUndecodable - The payload of the event can't be decoded, it would never be published
+var errUndecodable = errors.New("failed to decode event")
+
+// Config - The tuning of the relay
+type Config struct {
+	// PollInterval - How often the outbox is checked for pending events
+	PollInterval time.Duration
+	// BatchSize - The maximum number of events read from the outbox at once
+	BatchSize int64
+	// MinBackoff - The delay before retrying an event which failed for the first time, doubled on each failure
+	MinBackoff time.Duration
+	// MaxBackoff - The longest delay between two attempts of an event
+	MaxBackoff time.Duration
+	// MaxAttempts - The number of failed attempts after which an event is dead, 0 retries the events forever
+	MaxAttempts int
+	// Retention - How long the delivered events are kept in the outbox
+	Retention time.Duration
+}
+
+// Relay - Publishes the events written to the outbox.
+// The events of a user are published in the order they were written, and a failed event holds back the later events of its user
+// until it's published or dead. An event is dead after MaxAttempts failures, or right away if it can't be decoded.
+// An event can be published more than once, e.g. when the relay stops right after publishing it, so the consumers should deduplicate by the event ID.
+type Relay struct {
+	db          *sql.DB
+	repository  repository.IOutboxRepository
+	publisher   events.IEventPublisher
+	config      Config
+	lastCleanup time.Time
+}
+
+func NewRelay(db *sql.DB, repository repository.IOutboxRepository, publisher events.IEventPublisher, config Config) *Relay {
+	return &Relay{
+		db:         db,
+		repository: repository,
+		publisher:  publisher,
+		config:     config,
+	}
+}
+
+// Run - Relays the events until the context is cancelled.
+// Every replica runs the relay, but only the one holding the database lock publishes, the others take over when it stops.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	var lock *database.Lock
+	defer func() {
+		if lock != nil {
+			_ = lock.Release(context.Background())
+		}
+	}()
+
+	for {
+		lock = r.lead(ctx, lock)
+		if lock != nil {
+			if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to relay the user events: %s", err)
+			}
+			r.cleanup(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead - Tries to take the lock if it's not held, and makes sure the held lock is still alive.
+// MySQL releases the lock when its connection is lost, so a broken connection means another replica may be leading.
+func (r *Relay) lead(ctx context.Context, lock *database.Lock) *database.Lock {
+	if lock != nil {
+		if err := lock.Conn().PingContext(ctx); err == nil {
+			return lock
+		}
+
+		log.Println("lost the user events relay lock")
+		_ = lock.Release(ctx)
+	}
+
+	lock, err := database.AcquireLock(ctx, r.db, lockName, 0)
+	if err != nil {
+		if !errors.Is(err, database.ErrLockTimeout) && ctx.Err() == nil {
+			log.Printf("failed to acquire the user events relay lock: %s", err)
+		}
+		return nil
+	}
+
+	log.Println("relaying the user events from the outbox")
+	return lock
+}
+
+// RelayPending - Publishes one batch of the pending events and returns the number of the delivered ones
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	now := time.Now()
+	outboxEvents, err := r.repository.GetPendingEvents(ctx, now, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	// the users with an event which can't be published yet, their later events must wait for it
+	blocked := make(map[int64]bool)
+	for _, outboxEvent := range outboxEvents {
+		if blocked[outboxEvent.UserID] {
+			continue
+		}
+
+		if outboxEvent.NextAttemptAt.After(now) {
+			blocked[outboxEvent.UserID] = true
+			continue
+		}
+
+		if err := r.publish(ctx, outboxEvent); err != nil {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+
+			attempts := outboxEvent.Attempts + 1
+			if (r.config.MaxAttempts > 0 && attempts >= r.config.MaxAttempts) || errors.Is(err, errUndecodable) {
+				log.Printf("user event %s died after %d attempts: %s", outboxEvent.EventID, attempts, err)
+				if err := r.repository.MarkDead(ctx, outboxEvent.ID, err.Error()); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+
+			blocked[outboxEvent.UserID] = true
+			nextAttemptAt := now.Add(r.backoff(attempts))
+			log.Printf("failed to publish user event %s, retrying at %s: %s", outboxEvent.EventID, nextAttemptAt.Format(time.RFC3339), err)
+			if err := r.repository.MarkFailed(ctx, outboxEvent.ID, err.Error(), nextAttemptAt); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := r.repository.MarkDelivered(ctx, outboxEvent.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+func (r *Relay) publish(ctx context.Context, outboxEvent *entity.OutboxEvent) error {
+	event := new(events.Event)
+	if err := json.Unmarshal(outboxEvent.Payload, event); err != nil {
+		return fmt.Errorf("%w: %s", errUndecodable, err)
+	}
+
+	return r.publisher.Publish(ctx, event)
+}
+
+// backoff - The delay before the given attempt, doubled on each attempt up to the maximum
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.MinBackoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > r.config.MaxBackoff {
+		delay = r.config.MaxBackoff
+	}
+
+	return delay
+}
+
+// cleanup - Removes the events delivered before the retention period, once an hour
+func (r *Relay) cleanup(ctx context.Context) {
+	if r.config.Retention <= 0 || time.Since(r.lastCleanup) < time.Hour {
+		return
+	}
+
+	limit := r.config.BatchSize * 10
+	count, err := r.repository.DeleteDelivered(ctx, time.Now().Add(-r.config.Retention), limit)
+	if err != nil {
+		log.Printf("failed to clean up the user events outbox: %s", err)
+		return
+	}
+
+	// keep cleaning up on the next polls until everything expired is gone
+	if count < limit {
+		r.lastCleanup = time.Now()
+	}
+	if count > 0 {
+		log.Printf("removed %d delivered user events from the outbox", count)
+	}
+}
@@ -0,0 +1,232 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	eventsMocks "faceit/mocks/domain/user/events"
+	repositoryMocks "faceit/mocks/domain/user/repository"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RelayTestSuite struct {
+	suite.Suite
+}
+
+var testConfig = Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    100,
+	MinBackoff:   time.Second,
+	MaxBackoff:   time.Minute,
+	MaxAttempts:  3,
+}
+
+func outboxEvent(id, userID int64, nextAttemptAt time.Time) *entity.OutboxEvent {
+	payload, _ := json.Marshal(&events.Event{ID: string(rune('a' + id)), Type: events.TypeUserUpdated, UserID: userID})
+	return &entity.OutboxEvent{
+		ID:            id,
+		EventID:       string(rune('a' + id)),
+		UserID:        userID,
+		Type:          string(events.TypeUserUpdated),
+		Payload:       payload,
+		NextAttemptAt: nextAttemptAt,
+	}
+}
+
+// failedOutboxEvent - An event which has failed the given number of times and is due again
+func failedOutboxEvent(id, userID int64, attempts int) *entity.OutboxEvent {
+	outboxEvent := outboxEvent(id, userID, time.Now().Add(-time.Second))
+	outboxEvent.Attempts = attempts
+	return outboxEvent
+}
+
+func (r *RelayTestSuite) TestRelayPending() {
+	failure := errors.New("connection refused")
+	testCases := []struct {
+		outboxEvents      []*entity.OutboxEvent
+		failingEvents     map[string]bool
+		expectedPublished []string
+		expectedDelivered []int64
+		expectedFailed    []int64
+		expectedDead      []int64
+	}{
+		{
+			outboxEvents:      []*entity.OutboxEvent{outboxEvent(1, 1, time.Time{}), outboxEvent(2, 2, time.Time{}), outboxEvent(3, 1, time.Time{})},
+			expectedPublished: []string{"b", "c", "d"},
+			expectedDelivered: []int64{1, 2, 3},
+		},
+		{
+			// the later events of the user wait for the failed one
+			outboxEvents:      []*entity.OutboxEvent{outboxEvent(1, 1, time.Time{}), outboxEvent(2, 2, time.Time{}), outboxEvent(3, 1, time.Time{})},
+			failingEvents:     map[string]bool{"b": true},
+			expectedPublished: []string{"b", "c"},
+			expectedDelivered: []int64{2},
+			expectedFailed:    []int64{1},
+		},
+		{
+			// an event which is not due yet holds back the user, the other users are not affected
+			outboxEvents:      []*entity.OutboxEvent{outboxEvent(1, 1, time.Now().Add(time.Hour)), outboxEvent(2, 1, time.Time{}), outboxEvent(3, 2, time.Now().Add(-time.Second))},
+			expectedPublished: []string{"d"},
+			expectedDelivered: []int64{3},
+		},
+		{
+			// the last attempt kills the event, which doesn't hold back the user anymore
+			outboxEvents:      []*entity.OutboxEvent{failedOutboxEvent(1, 1, 2), outboxEvent(2, 1, time.Time{})},
+			failingEvents:     map[string]bool{"b": true},
+			expectedPublished: []string{"b", "c"},
+			expectedDelivered: []int64{2},
+			expectedDead:      []int64{1},
+		},
+		{
+			// an event which can't be decoded is dead right away
+			outboxEvents:      []*entity.OutboxEvent{{ID: 1, EventID: "b", UserID: 1, Payload: []byte(`{"id":`)}, outboxEvent(2, 1, time.Time{})},
+			expectedPublished: []string{"c"},
+			expectedDelivered: []int64{2},
+			expectedDead:      []int64{1},
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := repositoryMocks.IOutboxRepository{}
+		publisherMock := eventsMocks.IEventPublisher{}
+
+		var published []string
+		failingEvents := tc.failingEvents
+		publisherMock.On("Publish", mock.Anything, mock.Anything).Return(func(ctx context.Context, event *events.Event) error {
+			published = append(published, event.ID)
+			if failingEvents[event.ID] {
+				return failure
+			}
+			return nil
+		})
+		repositoryMock.On("GetPendingEvents", mock.Anything, mock.Anything, testConfig.BatchSize).Return(tc.outboxEvents, nil)
+		for _, id := range tc.expectedDelivered {
+			repositoryMock.On("MarkDelivered", mock.Anything, id).Return(nil).Once()
+		}
+		for _, id := range tc.expectedFailed {
+			repositoryMock.On("MarkFailed", mock.Anything, id, failure.Error(), mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+				return nextAttemptAt.After(time.Now())
+			})).Return(nil).Once()
+		}
+		for _, id := range tc.expectedDead {
+			repositoryMock.On("MarkDead", mock.Anything, id, mock.Anything).Return(nil).Once()
+		}
+
+		relay := NewRelay(nil, &repositoryMock, &publisherMock, testConfig)
+		delivered, err := relay.RelayPending(context.Background())
+		assert.NoError(r.T(), err)
+		assert.Equal(r.T(), len(tc.expectedDelivered), delivered)
+		assert.Equal(r.T(), tc.expectedPublished, published)
+		repositoryMock.AssertExpectations(r.T())
+	}
+}
+
+func (r *RelayTestSuite) TestRelaySaturated() {
+	// a full batch of events of other users are backing off, next to an event which is due
+	var pending []*entity.OutboxEvent
+	for i := int64(1); i <= testConfig.BatchSize; i++ {
+		pending = append(pending, outboxEvent(i, i, time.Now().Add(time.Hour)))
+	}
+	due := outboxEvent(testConfig.BatchSize+1, 1000, time.Time{})
+	pending = append(pending, due)
+
+	repositoryMock := repositoryMocks.IOutboxRepository{}
+	publisherMock := eventsMocks.IEventPublisher{}
+	// the events which are not due are left out of the batch, as the query does
+	repositoryMock.On("GetPendingEvents", mock.Anything, mock.Anything, testConfig.BatchSize).Return(func(_ context.Context, now time.Time, limit int64) []*entity.OutboxEvent {
+		var batch []*entity.OutboxEvent
+		for _, outboxEvent := range pending {
+			if !outboxEvent.NextAttemptAt.After(now) && int64(len(batch)) < limit {
+				batch = append(batch, outboxEvent)
+			}
+		}
+		return batch
+	}, nil)
+	publisherMock.On("Publish", mock.Anything, mock.MatchedBy(func(event *events.Event) bool {
+		return event.ID == due.EventID
+	})).Return(nil).Once()
+	repositoryMock.On("MarkDelivered", mock.Anything, due.ID).Return(nil).Once()
+
+	relay := NewRelay(nil, &repositoryMock, &publisherMock, testConfig)
+	delivered, err := relay.RelayPending(context.Background())
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), 1, delivered)
+	repositoryMock.AssertExpectations(r.T())
+	publisherMock.AssertExpectations(r.T())
+}
+
+func (r *RelayTestSuite) TestBackoff() {
+	testCases := []struct {
+		attempts      int
+		expectedDelay time.Duration
+	}{
+		{attempts: 1, expectedDelay: time.Second},
+		{attempts: 2, expectedDelay: 2 * time.Second},
+		{attempts: 4, expectedDelay: 8 * time.Second},
+		{attempts: 7, expectedDelay: time.Minute},
+		{attempts: 100, expectedDelay: time.Minute},
+	}
+
+	relay := NewRelay(nil, nil, nil, testConfig)
+	for _, tc := range testCases {
+		assert.Equal(r.T(), tc.expectedDelay, relay.backoff(tc.attempts))
+	}
+}
+
+func (r *RelayTestSuite) TestRun() {
+	db, sqlMock := databaseMocks.NewDBMock()
+	repositoryMock := repositoryMocks.IOutboxRepository{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WithArgs(lockName, int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(1))
+	repositoryMock.On("GetPendingEvents", mock.Anything, mock.Anything, testConfig.BatchSize).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil, nil).Once()
+	sqlMock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+		WithArgs(lockName).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	relay := NewRelay(db, &repositoryMock, &eventsMocks.IEventPublisher{}, testConfig)
+	relay.Run(ctx)
+
+	repositoryMock.AssertExpectations(r.T())
+	assert.NoError(r.T(), sqlMock.ExpectationsWereMet())
+}
+
+func (r *RelayTestSuite) TestLead() {
+	testCases := []struct {
+		acquired       int
+		expectedLeader bool
+	}{
+		{acquired: 1, expectedLeader: true},
+		{acquired: 0, expectedLeader: false},
+	}
+
+	for _, tc := range testCases {
+		db, sqlMock := databaseMocks.NewDBMock()
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+			WithArgs(lockName, int64(0)).
+			WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(tc.acquired))
+
+		relay := NewRelay(db, nil, nil, testConfig)
+		lock := relay.lead(context.Background(), nil)
+		assert.Equal(r.T(), tc.expectedLeader, lock != nil)
+		assert.NoError(r.T(), sqlMock.ExpectationsWereMet())
+	}
+}
+
+func TestRelayTestSuite(t *testing.T) {
+	suite.Run(t, new(RelayTestSuite))
+}
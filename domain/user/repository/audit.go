@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	authEntity "faceit/domain/auth/entity"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	"faceit/domain/user/utils"
+	"faceit/infrastructure/requestmeta"
+	"fmt"
+)
+
+type IAuditRepository interface {
+	Get(ctx context.Context, filter *entity.AuditFilter, page, pageSize int64) ([]*entity.AuditEntry, error)
+}
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Get - gets a page of the audit entries with the filter, the latest first
+func (a *AuditRepository) Get(ctx context.Context, filter *entity.AuditFilter, page, pageSize int64) ([]*entity.AuditEntry, error) {
+	query, args := utils.AuditQueryBuilder(filter, auditTableName, page, pageSize)
+
+	results, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var entries []*entity.AuditEntry
+	for results.Next() {
+		entry := new(entity.AuditEntry)
+		var changes []byte
+		if err := results.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Action,
+			&entry.ActorID,
+			&entry.RequestID,
+			&entry.SourceIP,
+			&changes,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+				return nil, fmt.Errorf("failed to decode the changes of audit entry %d: %w", entry.ID, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := results.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records from database: %w", err)
+	}
+
+	return entries, nil
+}
+
+// writeAuditEntry - records the change described by the event within its transaction,
+// with the principal and the meta of the request carried by the context
+func writeAuditEntry(ctx context.Context, tx *sql.Tx, event *events.Event) error {
+	var actorID *int64
+	if principal, ok := authEntity.PrincipalFromContext(ctx); ok {
+		actorID = &principal.UserID
+	}
+
+	var requestID, sourceIP string
+	if meta, ok := requestmeta.MetaFromContext(ctx); ok {
+		requestID, sourceIP = meta.RequestID, meta.SourceIP
+	}
+
+	var changes []byte
+	if len(event.Changes) > 0 {
+		var err error
+		if changes, err = json.Marshal(event.Changes); err != nil {
+			return fmt.Errorf("failed to encode changes: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, insertAuditEntry, event.UserID, string(event.Type), actorID, requestID, sourceIP, changes, event.OccurredAt); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+func (a *AuditRepositoryTestSuite) TestGet() {
+	createdAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	userID, actorID := int64(3), int64(7)
+
+	a.db, a.mock = databaseMocks.NewDBMock()
+	auditRepository := NewAuditRepository(a.db)
+
+	a.mock.ExpectQuery("SELECT id, user_id, action, actor_id, request_id, source_ip, changes, created_at FROM user_audit_log WHERE user_id = \\? ORDER BY id DESC LIMIT \\? OFFSET \\?").
+		WithArgs(userID, int64(20), int64(0)).
+		WillReturnRows(a.mock.NewRows([]string{"id", "user_id", "action", "actor_id", "request_id", "source_ip", "changes", "created_at"}).
+			AddRow(2, userID, "user.updated", actorID, "req-1", "203.0.113.7", []byte(`{"email":{"before":"a@gmail.com","after":"b@gmail.com"}}`), createdAt).
+			AddRow(1, userID, "user.created", nil, "req-0", "203.0.113.7", nil, createdAt))
+
+	entries, err := auditRepository.Get(context.Background(), &entity.AuditFilter{UserID: &userID}, 1, 20)
+	a.Require().NoError(err)
+	assert.Equal(a.T(), []*entity.AuditEntry{
+		{
+			ID: 2, UserID: userID, Action: "user.updated", ActorID: &actorID, RequestID: "req-1", SourceIP: "203.0.113.7",
+			Changes: map[string]events.Change{"email": {Before: "a@gmail.com", After: "b@gmail.com"}}, CreatedAt: createdAt,
+		},
+		{ID: 1, UserID: userID, Action: "user.created", RequestID: "req-0", SourceIP: "203.0.113.7", CreatedAt: createdAt},
+	}, entries)
+	assert.NoError(a.T(), a.mock.ExpectationsWereMet())
+}
+
+func TestAuditRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditRepositoryTestSuite))
+}
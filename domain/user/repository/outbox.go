@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	"fmt"
+	"time"
+)
+
+// maxLastErrorLength - The size of the last_error column
+const maxLastErrorLength = 1024
+
+type IOutboxRepository interface {
+	GetPendingEvents(ctx context.Context, now time.Time, limit int64) ([]*entity.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, ID int64) error
+	MarkFailed(ctx context.Context, ID int64, reason string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, ID int64, reason string) error
+	DeleteDelivered(ctx context.Context, before time.Time, limit int64) (int64, error)
+	GetEventsAfter(ctx context.Context, afterID int64, settle time.Duration, limit int64) ([]*entity.OutboxEvent, error)
+	GetLastSettledEventID(ctx context.Context, settle time.Duration) (int64, error)
+}
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// GetPendingEvents - gets the events which are not delivered yet in the order they were written
+func (o *OutboxRepository) GetPendingEvents(ctx context.Context, now time.Time, limit int64) ([]*entity.OutboxEvent, error) {
+	results, err := o.db.QueryContext(ctx, getPendingOutboxEvents, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending events: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var outboxEvents []*entity.OutboxEvent
+	for results.Next() {
+		outboxEvent := new(entity.OutboxEvent)
+		var nextAttemptAt sql.NullTime
+		if err := results.Scan(
+			&outboxEvent.ID,
+			&outboxEvent.EventID,
+			&outboxEvent.UserID,
+			&outboxEvent.Type,
+			&outboxEvent.Payload,
+			&outboxEvent.Attempts,
+			&nextAttemptAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+		outboxEvent.NextAttemptAt = nextAttemptAt.Time
+
+		outboxEvents = append(outboxEvents, outboxEvent)
+	}
+
+	return outboxEvents, results.Err()
+}
+
+// MarkDelivered - marks the event as delivered, so it is never relayed again
+func (o *OutboxRepository) MarkDelivered(ctx context.Context, ID int64) error {
+	if _, err := o.db.ExecContext(ctx, markOutboxEventDelivered, time.Now().UTC(), ID); err != nil {
+		return fmt.Errorf("failed to mark event as delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed - records the failed attempt and postpones the next one
+func (o *OutboxRepository) MarkFailed(ctx context.Context, ID int64, reason string, nextAttemptAt time.Time) error {
+	if len(reason) > maxLastErrorLength {
+		reason = reason[:maxLastErrorLength]
+	}
+
+	if _, err := o.db.ExecContext(ctx, markOutboxEventFailed, reason, nextAttemptAt.UTC(), ID); err != nil {
+		return fmt.Errorf("failed to mark event as failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDead - records the last failed attempt, the event is not retried anymore and doesn't hold back the later events of its user
+func (o *OutboxRepository) MarkDead(ctx context.Context, ID int64, reason string) error {
+	if len(reason) > maxLastErrorLength {
+		reason = reason[:maxLastErrorLength]
+	}
+
+	if _, err := o.db.ExecContext(ctx, markOutboxEventDead, time.Now().UTC(), reason, ID); err != nil {
+		return fmt.Errorf("failed to mark event as dead: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDelivered - removes up to limit events delivered before the given time and returns how many were removed
+func (o *OutboxRepository) DeleteDelivered(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	result, err := o.db.ExecContext(ctx, deleteDeliveredOutboxEvents, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered events: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetEventsAfter - gets up to limit events after the given ID in the order they were written, delivered or not, without the payload.
+// It stops at the first event written within settle, as the transactions which wrote the events before it may not be committed yet,
+// and an event committed later than the ones after it would be skipped.
+func (o *OutboxRepository) GetEventsAfter(ctx context.Context, afterID int64, settle time.Duration, limit int64) ([]*entity.OutboxEvent, error) {
+	results, err := o.db.QueryContext(ctx, getOutboxEventsAfter, settleSeconds(settle), afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var outboxEvents []*entity.OutboxEvent
+	for results.Next() {
+		outboxEvent := new(entity.OutboxEvent)
+		var settled bool
+		if err := results.Scan(
+			&outboxEvent.ID,
+			&outboxEvent.EventID,
+			&outboxEvent.UserID,
+			&outboxEvent.Type,
+			&settled,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+		if !settled {
+			break
+		}
+
+		outboxEvents = append(outboxEvents, outboxEvent)
+	}
+
+	return outboxEvents, results.Err()
+}
+
+// GetLastSettledEventID - gets the ID of the last event written before settle, 0 if there is none
+func (o *OutboxRepository) GetLastSettledEventID(ctx context.Context, settle time.Duration) (int64, error) {
+	var ID int64
+	err := o.db.QueryRowContext(ctx, getLastSettledOutboxEventID, settleSeconds(settle)).Scan(&ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to get the last event: %w", err)
+	}
+
+	return ID, nil
+}
+
+// settleSeconds - The created_at column only has seconds, so a part of a second is rounded up
+func settleSeconds(settle time.Duration) int64 {
+	return int64((settle + time.Second - 1) / time.Second)
+}
+
+// writeOutboxEvent - writes the event into the outbox within the transaction of the change it describes
+func writeOutboxEvent(ctx context.Context, tx *sql.Tx, event *events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, insertOutboxEvent, event.ID, event.UserID, string(event.Type), payload); err != nil {
+		return fmt.Errorf("failed to write event to outbox: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"faceit/domain/user/entity"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboxRepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+func (o *OutboxRepositoryTestSuite) TestGetPendingEvents() {
+	nextAttemptAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		limit                int64
+		expectedOutboxEvents []*entity.OutboxEvent
+	}{
+		{
+			limit: 10,
+			expectedOutboxEvents: []*entity.OutboxEvent{
+				{ID: 1, EventID: "a", UserID: 1, Type: "user.created", Payload: []byte(`{"id":"a"}`)},
+				{ID: 2, EventID: "b", UserID: 1, Type: "user.updated", Payload: []byte(`{"id":"b"}`), Attempts: 2, NextAttemptAt: nextAttemptAt},
+			},
+		},
+		{
+			limit: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		o.db, o.mock = databaseMocks.NewDBMock()
+		outboxRepository := NewOutboxRepository(o.db)
+
+		rows := o.mock.NewRows([]string{"id", "event_id", "user_id", "type", "payload", "attempts", "next_attempt_at"})
+		for _, outboxEvent := range tc.expectedOutboxEvents {
+			var next interface{}
+			if !outboxEvent.NextAttemptAt.IsZero() {
+				next = outboxEvent.NextAttemptAt
+			}
+			rows.AddRow(outboxEvent.ID, outboxEvent.EventID, outboxEvent.UserID, outboxEvent.Type, outboxEvent.Payload, outboxEvent.Attempts, next)
+		}
+
+		now := time.Now()
+		o.mock.ExpectQuery(regexp.QuoteMeta(getPendingOutboxEvents)).
+			WithArgs(now.UTC(), tc.limit).
+			WillReturnRows(rows)
+		outboxEvents, err := outboxRepository.GetPendingEvents(context.Background(), now, tc.limit)
+		assert.NoError(o.T(), err)
+		assert.Equal(o.T(), tc.expectedOutboxEvents, outboxEvents)
+	}
+}
+
+func (o *OutboxRepositoryTestSuite) TestMarkDelivered() {
+	o.db, o.mock = databaseMocks.NewDBMock()
+	outboxRepository := NewOutboxRepository(o.db)
+
+	o.mock.ExpectExec(regexp.QuoteMeta(markOutboxEventDelivered)).
+		WithArgs(sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err := outboxRepository.MarkDelivered(context.Background(), 1)
+	assert.NoError(o.T(), err)
+	assert.NoError(o.T(), o.mock.ExpectationsWereMet())
+}
+
+func (o *OutboxRepositoryTestSuite) TestMarkFailed() {
+	nextAttemptAt := time.Now()
+	testCases := []struct {
+		reason         string
+		expectedReason string
+	}{
+		{reason: "connection refused", expectedReason: "connection refused"},
+		{reason: strings.Repeat("a", 2000), expectedReason: strings.Repeat("a", maxLastErrorLength)},
+	}
+
+	for _, tc := range testCases {
+		o.db, o.mock = databaseMocks.NewDBMock()
+		outboxRepository := NewOutboxRepository(o.db)
+
+		o.mock.ExpectExec(regexp.QuoteMeta(markOutboxEventFailed)).
+			WithArgs(tc.expectedReason, nextAttemptAt.UTC(), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		err := outboxRepository.MarkFailed(context.Background(), 1, tc.reason, nextAttemptAt)
+		assert.NoError(o.T(), err)
+		assert.NoError(o.T(), o.mock.ExpectationsWereMet())
+	}
+}
+
+func (o *OutboxRepositoryTestSuite) TestMarkDead() {
+	o.db, o.mock = databaseMocks.NewDBMock()
+	outboxRepository := NewOutboxRepository(o.db)
+
+	o.mock.ExpectExec(regexp.QuoteMeta(markOutboxEventDead)).
+		WithArgs(sqlmock.AnyArg(), "failed to decode event", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	err := outboxRepository.MarkDead(context.Background(), 1, "failed to decode event")
+	assert.NoError(o.T(), err)
+	assert.NoError(o.T(), o.mock.ExpectationsWereMet())
+}
+
+func (o *OutboxRepositoryTestSuite) TestDeleteDelivered() {
+	o.db, o.mock = databaseMocks.NewDBMock()
+	outboxRepository := NewOutboxRepository(o.db)
+	before := time.Now()
+
+	o.mock.ExpectExec(regexp.QuoteMeta(deleteDeliveredOutboxEvents)).
+		WithArgs(before.UTC(), int64(1000)).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+	count, err := outboxRepository.DeleteDelivered(context.Background(), before, 1000)
+	assert.NoError(o.T(), err)
+	assert.Equal(o.T(), int64(42), count)
+}
+
+func (o *OutboxRepositoryTestSuite) TestGetEventsAfter() {
+	o.db, o.mock = databaseMocks.NewDBMock()
+	outboxRepository := NewOutboxRepository(o.db)
+
+	// the events from the first one written within the settle time are left for later, even if the ones after it are older
+	rows := o.mock.NewRows([]string{"id", "event_id", "user_id", "type", "settled"}).
+		AddRow(3, "c", 1, "user.updated", true).
+		AddRow(4, "d", 2, "user.deleted", true).
+		AddRow(5, "e", 1, "user.updated", false).
+		AddRow(6, "f", 3, "user.created", true)
+	o.mock.ExpectQuery(regexp.QuoteMeta(getOutboxEventsAfter)).
+		WithArgs(int64(2), int64(2), int64(10)).
+		WillReturnRows(rows)
+	outboxEvents, err := outboxRepository.GetEventsAfter(context.Background(), 2, 1500*time.Millisecond, 10)
+	assert.NoError(o.T(), err)
+	assert.Equal(o.T(), []*entity.OutboxEvent{
+		{ID: 3, EventID: "c", UserID: 1, Type: "user.updated"},
+		{ID: 4, EventID: "d", UserID: 2, Type: "user.deleted"},
+	}, outboxEvents)
+	assert.NoError(o.T(), o.mock.ExpectationsWereMet())
+}
+
+func (o *OutboxRepositoryTestSuite) TestGetLastSettledEventID() {
+	testCases := []struct {
+		rows       *sqlmock.Rows
+		expectedID int64
+	}{
+		{rows: sqlmock.NewRows([]string{"id"}).AddRow(42), expectedID: 42},
+		{rows: sqlmock.NewRows([]string{"id"}), expectedID: 0},
+	}
+
+	for _, tc := range testCases {
+		o.db, o.mock = databaseMocks.NewDBMock()
+		outboxRepository := NewOutboxRepository(o.db)
+
+		o.mock.ExpectQuery(regexp.QuoteMeta(getLastSettledOutboxEventID)).
+			WithArgs(int64(5)).
+			WillReturnRows(tc.rows)
+		ID, err := outboxRepository.GetLastSettledEventID(context.Background(), 5*time.Second)
+		assert.NoError(o.T(), err)
+		assert.Equal(o.T(), tc.expectedID, ID)
+		assert.NoError(o.T(), o.mock.ExpectationsWereMet())
+	}
+}
+
+func TestOutboxRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(OutboxRepositoryTestSuite))
+}
@@ -1,15 +1,74 @@
 package repository
 
-const usersTableName = "users"
+const (
+	usersTableName  = "users"
+	outboxTableName = "user_events_outbox"
+	auditTableName  = "user_audit_log"
+)
+
+// the unique keys of the email and the nickname of the users which are not removed, they are named in the errors of the violations
+const (
+	uniqueEmailKey    = "users_live_email"
+	uniqueNickNameKey = "users_live_nick_name"
+)
+
+// getByIDsChunkSize - The maximum number of IDs in the IN list of a query
+const getByIDsChunkSize = 500
 
 const (
 	createUser = `INSERT INTO ` + usersTableName + ` SET first_name = ?, last_name = ?, nick_name = ?, password = ?, email = ?, country = ?`
 
-	deleteUser = `DELETE FROM ` + usersTableName + ` WHERE id = ?`
+	// a rehash is not a change of the user, so it keeps the version, but it's dropped if the user has been changed since it was read
+	updatePassword = `UPDATE ` + usersTableName + ` SET password = ? WHERE id = ? AND version = ?`
+
+	// tells why a conditional update of the user didn't match it, it's either removed or its version is changed
+	getUserVersion = `SELECT version FROM ` + usersTableName + ` WHERE id = ? AND deleted_at IS NULL`
+
+	// the removed users are kept until they are purged, so they can be restored
+	deleteUser = `UPDATE ` + usersTableName + ` SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL`
+
+	getDeletedUserForUpdate = `SELECT email, nick_name FROM ` + usersTableName + ` WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE`
+
+	// the email and the nickname of a removed user can be taken by another user before it's restored
+	countTakenIdentities = `SELECT count(*) FROM ` + usersTableName + ` WHERE deleted_at IS NULL AND (email = ? OR nick_name = ?)`
+
+	restoreUser = `UPDATE ` + usersTableName + ` SET deleted_at = NULL, version = version + 1 WHERE id = ?`
+
+	getDeletedUsers = `SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version, deleted_at FROM ` + usersTableName + ` WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?`
+
+	countDeletedUsers = `SELECT count(*) FROM ` + usersTableName + ` WHERE deleted_at IS NOT NULL`
+
+	getExpiredUserIDs = `SELECT id FROM ` + usersTableName + ` WHERE deleted_at < ? ORDER BY deleted_at, id LIMIT ?`
+
+	purgeUser = `DELETE FROM ` + usersTableName + ` WHERE id = ? AND deleted_at < ?`
+
+	getUserByID = `SELECT id, first_name, last_name, nick_name, password, email, country, created_at, updated_at, version FROM ` + usersTableName + ` WHERE id = ? AND deleted_at IS NULL`
+
+	getUserByEmail = `SELECT id, first_name, last_name, nick_name, password, email, country, created_at, updated_at, version FROM ` + usersTableName + ` WHERE email = ? AND deleted_at IS NULL`
+
+	getUserByNickName = `SELECT id, first_name, last_name, nick_name, password, email, country, created_at, updated_at, version FROM ` + usersTableName + ` WHERE nick_name = ? AND deleted_at IS NULL`
+
+	insertAuditEntry = `INSERT INTO ` + auditTableName + ` SET user_id = ?, action = ?, actor_id = ?, request_id = ?, source_ip = ?, changes = ?, created_at = ?`
+
+	// the history of a purged user is kept without the values of its fields
+	redactAuditChanges = `UPDATE ` + auditTableName + ` SET changes = NULL WHERE user_id = ?`
+
+	insertOutboxEvent = `INSERT INTO ` + outboxTableName + ` SET event_id = ?, user_id = ?, type = ?, payload = ?`
+
+	// getPendingOutboxEvents - an event which is not due yet is skipped with the later events of its user, which must wait for it,
+	// so the events backing off never fill the batch and hold back the other users
+	getPendingOutboxEvents = `SELECT o.id, o.event_id, o.user_id, o.type, o.payload, o.attempts, o.next_attempt_at FROM ` + outboxTableName + ` o WHERE o.delivered_at IS NULL AND o.dead_at IS NULL AND NOT EXISTS (SELECT 1 FROM ` + outboxTableName + ` b WHERE b.user_id = o.user_id AND b.delivered_at IS NULL AND b.dead_at IS NULL AND b.id <= o.id AND b.next_attempt_at > ?) ORDER BY o.id LIMIT ?`
+
+	markOutboxEventDelivered = `UPDATE ` + outboxTableName + ` SET delivered_at = ?, attempts = attempts + 1, last_error = '' WHERE id = ?`
+
+	markOutboxEventFailed = `UPDATE ` + outboxTableName + ` SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?`
+
+	markOutboxEventDead = `UPDATE ` + outboxTableName + ` SET dead_at = ?, attempts = attempts + 1, last_error = ? WHERE id = ?`
 
-	getUserByID = `SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM ` + usersTableName + ` WHERE id = ?`
+	deleteDeliveredOutboxEvents = `DELETE FROM ` + outboxTableName + ` WHERE delivered_at IS NOT NULL AND delivered_at < ? LIMIT ?`
 
-	getUserByEmail = `SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM ` + usersTableName + ` WHERE email = ?`
+	// getOutboxEventsAfter - all the events after the ID whatever their delivery, and whether they were written long enough ago
+	getOutboxEventsAfter = `SELECT id, event_id, user_id, type, created_at < CURRENT_TIMESTAMP - INTERVAL ? SECOND FROM ` + outboxTableName + ` WHERE id > ? ORDER BY id LIMIT ?`
 
-	getUserByNickName = `SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM ` + usersTableName + ` WHERE nick_name = ?`
+	getLastSettledOutboxEventID = `SELECT id FROM ` + outboxTableName + ` WHERE created_at < CURRENT_TIMESTAMP - INTERVAL ? SECOND ORDER BY id DESC LIMIT 1`
 )
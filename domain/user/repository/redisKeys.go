@@ -1,5 +0,0 @@
-package repository
-
-const (
-	UserChangesRedisKey = "user-changes"
-)
@@ -3,38 +3,61 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"faceit/domain/constants"
 	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
 	"faceit/domain/user/utils"
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/go-redis/redis"
+	"github.com/go-sql-driver/mysql"
 )
 
+// mysqlDuplicateEntry - The number of the MySQL error of a violated unique key
+const mysqlDuplicateEntry = 1062
+
 type IUsersRepository interface {
-	Create(ctx context.Context, user *entity.User) (*entity.User, error)
-	Update(ctx context.Context, user *entity.User) error
-	Remove(ctx context.Context, ID int64) error
+	Create(ctx context.Context, user *entity.User, event *events.Event) (*entity.User, error)
+	Update(ctx context.Context, user *entity.User, event *events.Event) error
+	UpdatePassword(ctx context.Context, ID, version int64, passwordHash string) error
+	Remove(ctx context.Context, ID int64, event *events.Event) error
+	Restore(ctx context.Context, ID int64, event *events.Event) error
+	Purge(ctx context.Context, ID int64, deletedBefore time.Time, event *events.Event) error
 	GetByID(ctx context.Context, ID int64) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByNickName(ctx context.Context, nickName string) (*entity.User, error)
-	Get(ctx context.Context, filter *entity.Filter, page, pageSize int64) ([]*entity.User, error)
+	GetByIDs(ctx context.Context, IDs []int64) ([]*entity.User, error)
+	Get(ctx context.Context, filter *entity.Filter, sort []entity.SortKey, page, pageSize int64) ([]*entity.User, error)
+	GetPage(ctx context.Context, filter *entity.Filter, sort []entity.SortKey, cursor *entity.Cursor, limit int64) ([]*entity.User, error)
 	GetCount(ctx context.Context, filter *entity.Filter) (uint64, error)
-	PublishUserChangeEvent(userID int64) error
+	GetDeleted(ctx context.Context, page, pageSize int64) ([]*entity.User, error)
+	GetDeletedCount(ctx context.Context) (uint64, error)
+	GetExpiredIDs(ctx context.Context, deletedBefore time.Time, limit int64) ([]int64, error)
 }
 
 type UsersRepository struct {
-	db    *sql.DB
-	redis redis.UniversalClient
+	db *sql.DB
 }
 
-func NewUserRepository(db *sql.DB, redis redis.UniversalClient) *UsersRepository {
-	return &UsersRepository{db: db, redis: redis}
+func NewUserRepository(db *sql.DB) *UsersRepository {
+	return &UsersRepository{db: db}
 }
 
-// Create - creates a user with the given information
-func (u *UsersRepository) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
-	result, err := u.db.ExecContext(
+// Create - creates a user with the given information and writes the event with the ID of the new user to the outbox.
+// It returns constants.ErrEmailExists or constants.ErrNickNameExists if another user has the email or the nickname.
+func (u *UsersRepository) Create(ctx context.Context, user *entity.User, event *events.Event) (*entity.User, error) {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	result, err := tx.ExecContext(
 		ctx,
 		createUser,
 		user.FirstName,
@@ -45,6 +68,9 @@ func (u *UsersRepository) Create(ctx context.Context, user *entity.User) (*entit
 		user.Country,
 	)
 	if err != nil {
+		if existsErr := uniqueKeyError(err); existsErr != nil {
+			return nil, existsErr
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
@@ -52,33 +78,118 @@ func (u *UsersRepository) Create(ctx context.Context, user *entity.User) (*entit
 	if err != nil {
 		return nil, fmt.Errorf("failed to get last inserted ID: %w", err)
 	}
+	// the version of a new user is the default of the column
+	user.Version = 1
+
+	event.UserID = user.ID
+	if err := writeOutboxEvent(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	if err := writeAuditEntry(ctx, tx, event); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return user, nil
 }
 
-// Update - updates the user with the given information
-func (u *UsersRepository) Update(ctx context.Context, user *entity.User) error {
-	query := utils.UpdateQueryBuilder(user, usersTableName)
-	_, err := u.db.ExecContext(
+// Update - updates the user with the given information and writes the event to the outbox and the audit log.
+// The user is only updated at its given version, otherwise it returns constants.ErrVersionConflict, or constants.ErrUserNotFound if it's removed.
+// It returns constants.ErrEmailExists or constants.ErrNickNameExists if another user has the new email or nickname.
+func (u *UsersRepository) Update(ctx context.Context, user *entity.User, event *events.Event) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	query, args := utils.UpdateQueryBuilder(user, usersTableName)
+	result, err := tx.ExecContext(
 		ctx,
 		query,
+		args...,
 	)
 	if err != nil {
+		if existsErr := uniqueKeyError(err); existsErr != nil {
+			return existsErr
+		}
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
-	if err := u.PublishUserChangeEvent(user.ID); err != nil {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		var version int64
+		err := tx.QueryRowContext(ctx, getUserVersion, user.ID).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return constants.ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read version of user: %w", err)
+		}
+
+		return constants.ErrVersionConflict
+	}
+
+	if err := writeOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := writeAuditEntry(ctx, tx, event); err != nil {
 		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePassword - replaces the password hash of the user, e.g. when it is rehashed with new cost parameters.
+// This is not a change of the user's information, so no change event is published and the version is kept.
+// Nothing is changed if the user is not at the given version anymore, e.g. its password is changed since it was read.
+func (u *UsersRepository) UpdatePassword(ctx context.Context, ID, version int64, passwordHash string) error {
+	_, err := u.db.ExecContext(
+		ctx,
+		updatePassword,
+		passwordHash,
+		ID,
+		version,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
 	return nil
 }
 
-// Remove - removes the user with the given ID
-func (u *UsersRepository) Remove(ctx context.Context, ID int64) error {
-	result, err := u.db.ExecContext(
+// Remove - marks the user with the given ID as removed and writes the event to the outbox and the audit log.
+// The removed user is hidden from all the reads, and it's kept until it's purged, so it can be restored.
+func (u *UsersRepository) Remove(ctx context.Context, ID int64, event *events.Event) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	result, err := tx.ExecContext(
 		ctx,
 		deleteUser,
+		time.Now().UTC(),
 		ID,
 	)
 	if err != nil {
@@ -91,7 +202,116 @@ func (u *UsersRepository) Remove(ctx context.Context, ID int64) error {
 	}
 
 	if count == 0 {
-		return fmt.Errorf("no users were deleted")
+		return constants.ErrUserNotFound
+	}
+
+	if err := writeOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := writeAuditEntry(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Restore - brings back the removed user with the given ID and writes the event to the outbox and the audit log.
+// It returns constants.ErrUserNotFound if the user is not removed, and constants.ErrUserExists if its email or nickname is taken by another user since.
+func (u *UsersRepository) Restore(ctx context.Context, ID int64, event *events.Event) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	var email, nickName string
+	err = tx.QueryRowContext(ctx, getDeletedUserForUpdate, ID).Scan(&email, &nickName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return constants.ErrUserNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read removed user: %w", err)
+	}
+
+	var taken int64
+	if err := tx.QueryRowContext(ctx, countTakenIdentities, email, nickName).Scan(&taken); err != nil {
+		return fmt.Errorf("failed to check email and nickname: %w", err)
+	}
+	if taken > 0 {
+		return constants.ErrUserExists
+	}
+
+	if _, err := tx.ExecContext(ctx, restoreUser, ID); err != nil {
+		if existsErr := uniqueKeyError(err); existsErr != nil {
+			return existsErr
+		}
+		return fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	if err := writeOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := writeAuditEntry(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Purge - deletes the user with the given ID for good if it was removed before the given time, and writes the event to the outbox and the audit log.
+// The history of the user is kept for the record, but the values of its fields are removed from it.
+// It returns constants.ErrUserNotFound if the user is not removed or it was removed later, e.g. it's restored and removed again since it was listed.
+func (u *UsersRepository) Purge(ctx context.Context, ID int64, deletedBefore time.Time, event *events.Event) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	result, err := tx.ExecContext(ctx, purgeUser, ID, deletedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to purge user: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		return constants.ErrUserNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, redactAuditChanges, ID); err != nil {
+		return fmt.Errorf("failed to redact the history of user: %w", err)
+	}
+
+	if err := writeOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := writeAuditEntry(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -122,10 +342,12 @@ func (u *UsersRepository) GetByID(ctx context.Context, ID int64) (*entity.User,
 		&user.FirstName,
 		&user.LastName,
 		&user.NickName,
+		&user.Password,
 		&user.Email,
 		&user.Country,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	); err != nil {
 		return nil, fmt.Errorf("failed to read user from database: %w", err)
 	}
@@ -158,10 +380,12 @@ func (u *UsersRepository) GetByEmail(ctx context.Context, email string) (*entity
 		&user.FirstName,
 		&user.LastName,
 		&user.NickName,
+		&user.Password,
 		&user.Email,
 		&user.Country,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	); err != nil {
 		return nil, fmt.Errorf("failed to read user from database: %w", err)
 	}
@@ -194,10 +418,12 @@ func (u *UsersRepository) GetByNickName(ctx context.Context, nickName string) (*
 		&user.FirstName,
 		&user.LastName,
 		&user.NickName,
+		&user.Password,
 		&user.Email,
 		&user.Country,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.Version,
 	); err != nil {
 		return nil, fmt.Errorf("failed to read user from database: %w", err)
 	}
@@ -205,11 +431,61 @@ func (u *UsersRepository) GetByNickName(ctx context.Context, nickName string) (*
 	return user, nil
 }
 
+// GetByIDs - gets the users with the given IDs in chunks, so the queries stay small for long lists.
+// The missing users are skipped and the order of the users is not defined.
+func (u *UsersRepository) GetByIDs(ctx context.Context, IDs []int64) ([]*entity.User, error) {
+	users := make([]*entity.User, 0, len(IDs))
+	for start := 0; start < len(IDs); start += getByIDsChunkSize {
+		end := start + getByIDsChunkSize
+		if end > len(IDs) {
+			end = len(IDs)
+		}
+
+		chunk, err := u.getByIDs(ctx, IDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, chunk...)
+	}
+
+	return users, nil
+}
+
+func (u *UsersRepository) getByIDs(ctx context.Context, IDs []int64) ([]*entity.User, error) {
+	query, args := utils.IDsQueryBuilder(IDs, usersTableName)
+
+	return u.getUsers(ctx, query, args)
+}
+
 // Get - return the users with the provided criteria in the filter field and return the data with pagination and the total count of the results.
-func (u *UsersRepository) Get(ctx context.Context, filter *entity.Filter, page, pageSize int64) ([]*entity.User, error) {
-	query := utils.QueryBuilder(filter, usersTableName, page, pageSize)
+func (u *UsersRepository) Get(ctx context.Context, filter *entity.Filter, sort []entity.SortKey, page, pageSize int64) ([]*entity.User, error) {
+	query, args := utils.QueryBuilder(filter, usersTableName, sort, page, pageSize)
+
+	return u.getUsers(ctx, query, args)
+}
+
+// GetPage - returns up to limit users with the filter right after the cursor in the order of the sort keys, or the first ones without a cursor.
+// The users before a backward cursor are returned in the order of the sort keys as well.
+func (u *UsersRepository) GetPage(ctx context.Context, filter *entity.Filter, sort []entity.SortKey, cursor *entity.Cursor, limit int64) ([]*entity.User, error) {
+	query, args := utils.PageQueryBuilder(filter, usersTableName, sort, cursor, limit)
+
+	users, err := u.getUsers(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	return users, nil
+}
 
-	results, err := u.db.QueryContext(ctx, query)
+func (u *UsersRepository) getUsers(ctx context.Context, query string, args []interface{}) ([]*entity.User, error) {
+	results, err := u.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
@@ -230,6 +506,7 @@ func (u *UsersRepository) Get(ctx context.Context, filter *entity.Filter, page,
 			&user.Country,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.Version,
 		); err != nil {
 			return nil, fmt.Errorf("failed to read records from database: %w", err)
 		}
@@ -237,14 +514,18 @@ func (u *UsersRepository) Get(ctx context.Context, filter *entity.Filter, page,
 		users = append(users, user)
 	}
 
+	if err := results.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records from database: %w", err)
+	}
+
 	return users, nil
 }
 
 // GetCount - gets the total count of users with the provided filter
 func (u *UsersRepository) GetCount(ctx context.Context, filter *entity.Filter) (uint64, error) {
-	query := utils.CountQueryBuilder(filter, usersTableName)
+	query, args := utils.CountQueryBuilder(filter, usersTableName)
 
-	result, err := u.db.QueryContext(ctx, query)
+	result, err := u.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total count of users: %w", err)
 	}
@@ -265,11 +546,97 @@ func (u *UsersRepository) GetCount(ctx context.Context, filter *entity.Filter) (
 	return count, nil
 }
 
-// PublishUserChangeEvent - This function is used to store the user change event in the redis so other services can be notified of the change.
-func (u *UsersRepository) PublishUserChangeEvent(userID int64) error {
-	_, err := u.redis.RPush(UserChangesRedisKey, userID).Result()
+// GetDeleted - gets the removed users which are not purged yet, the most recently removed first
+func (u *UsersRepository) GetDeleted(ctx context.Context, page, pageSize int64) ([]*entity.User, error) {
+	results, err := u.db.QueryContext(ctx, getDeletedUsers, pageSize, (page-1)*pageSize)
 	if err != nil {
-		return fmt.Errorf("failed to push event to redis: %w", err)
+		return nil, fmt.Errorf("failed to get removed users: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var users []*entity.User
+	for results.Next() {
+		user := new(entity.User)
+		if err := results.Scan(
+			&user.ID,
+			&user.FirstName,
+			&user.LastName,
+			&user.NickName,
+			&user.Email,
+			&user.Country,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+			&user.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if err := results.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records from database: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetDeletedCount - gets the total count of the removed users which are not purged yet
+func (u *UsersRepository) GetDeletedCount(ctx context.Context) (uint64, error) {
+	var count uint64
+	if err := u.db.QueryRowContext(ctx, countDeletedUsers).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to get total count of removed users: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetExpiredIDs - gets the IDs of up to limit users which were removed before the given time, the earliest removed first
+func (u *UsersRepository) GetExpiredIDs(ctx context.Context, deletedBefore time.Time, limit int64) ([]int64, error) {
+	results, err := u.db.QueryContext(ctx, getExpiredUserIDs, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired users: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var IDs []int64
+	for results.Next() {
+		var ID int64
+		if err := results.Scan(&ID); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		IDs = append(IDs, ID)
+	}
+
+	if err := results.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read records from database: %w", err)
+	}
+
+	return IDs, nil
+}
+
+// uniqueKeyError - Returns the error of the field whose unique key is violated by the error, or nil if it's not a violation.
+// The email and the nickname can be taken by a concurrent request after they are checked, so only the unique keys can tell.
+func uniqueKeyError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlDuplicateEntry {
+		return nil
+	}
+
+	switch {
+	case strings.Contains(mysqlErr.Message, uniqueEmailKey):
+		return constants.ErrEmailExists
+	case strings.Contains(mysqlErr.Message, uniqueNickNameKey):
+		return constants.ErrNickNameExists
+	default:
+		return constants.ErrUserExists
 	}
-	return nil
 }
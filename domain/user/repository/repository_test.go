@@ -3,24 +3,26 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	authEntity "faceit/domain/auth/entity"
+	"faceit/domain/constants"
 	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	"faceit/infrastructure/requestmeta"
 	databaseMocks "faceit/mocks/infrastructure/database"
-	redisMocks "faceit/mocks/infrastructure/redis"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/alicebob/miniredis/v2"
-	"github.com/go-redis/redis"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
 
 type RepositoryTestSuite struct {
 	suite.Suite
-	db    *sql.DB
-	mock  sqlmock.Sqlmock
-	redis *miniredis.Miniredis
+	db   *sql.DB
+	mock sqlmock.Sqlmock
 }
 
 func (r *RepositoryTestSuite) TestCreate() {
@@ -41,37 +43,89 @@ func (r *RepositoryTestSuite) TestCreate() {
 			},
 			ctx: context.Background(),
 			expectedUserEntity: &entity.User{
+				ID:        1,
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
 				Password:  "pass",
 				Email:     "test@gmail.com",
 				Country:   "UK",
+				Version:   1,
 			},
 			expectedError: nil,
 		},
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
-		r.mock.ExpectExec("INSERT INTO").
+		r.mock.ExpectBegin()
+		r.mock.ExpectExec("INSERT INTO users").
 			WithArgs(tc.user.FirstName, tc.user.LastName, tc.user.NickName, tc.user.Password, tc.user.Email, tc.user.Country).
-			WillReturnResult(sqlmock.NewResult(0, 1))
-		userEntity, err := userRepository.Create(tc.ctx, tc.user)
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		r.expectOutboxEvent("1", tc.expectedUserEntity.ID, events.TypeUserCreated)
+		r.expectAuditEntry(tc.expectedUserEntity.ID, events.TypeUserCreated)
+		r.mock.ExpectCommit()
+		event := &events.Event{ID: "1", Type: events.TypeUserCreated}
+		userEntity, err := userRepository.Create(tc.ctx, tc.user, event)
 		assert.Equal(r.T(), tc.expectedError, err)
 		assert.Equal(r.T(), tc.expectedUserEntity, userEntity)
+		assert.Equal(r.T(), tc.expectedUserEntity.ID, event.UserID)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
 	}
 }
 
+func (r *RepositoryTestSuite) TestCreateDuplicate() {
+	testCases := []struct {
+		err           error
+		expectedError error
+	}{
+		{
+			// MySQL 8 prefixes the key with the table
+			err:           &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'test@gmail.com' for key 'users.users_live_email'"},
+			expectedError: constants.ErrEmailExists,
+		},
+		{
+			err:           &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'test' for key 'users_live_nick_name'"},
+			expectedError: constants.ErrNickNameExists,
+		},
+		{
+			err:           &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"},
+			expectedError: constants.ErrUserExists,
+		},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectBegin()
+		r.mock.ExpectExec("INSERT INTO users").WillReturnError(tc.err)
+		r.mock.ExpectRollback()
+		user := &entity.User{FirstName: "test", LastName: "test", NickName: "test", Password: "pass", Email: "test@gmail.com", Country: "UK"}
+		_, err := userRepository.Create(context.Background(), user, &events.Event{ID: "1", Type: events.TypeUserCreated})
+		assert.Equal(r.T(), tc.expectedError, err)
+		assert.ErrorIs(r.T(), err, constants.ErrUserExists)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+	}
+
+	// the other errors are not violations of the unique keys
+	r.mock.ExpectBegin()
+	r.mock.ExpectExec("INSERT INTO users").WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+	r.mock.ExpectRollback()
+	_, err := userRepository.Create(context.Background(), &entity.User{}, &events.Event{ID: "1", Type: events.TypeUserCreated})
+	assert.Error(r.T(), err)
+	assert.NotErrorIs(r.T(), err, constants.ErrUserExists)
+	assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+}
+
 func (r *RepositoryTestSuite) TestUpdate() {
 	testCases := []struct {
 		user          *entity.User
+		updated       int64
+		version       []int64
+		duplicate     bool
 		ctx           context.Context
 		expectedError error
 	}{
@@ -84,55 +138,270 @@ func (r *RepositoryTestSuite) TestUpdate() {
 				Password:  "pass",
 				Email:     "test@gmail.com",
 				Country:   "UK",
+				Version:   3,
 			},
+			updated:       1,
+			ctx:           context.Background(),
+			expectedError: nil,
+		},
+		{
+			// changed by another update since it was read
+			user:          &entity.User{ID: 1, Country: "UK", Version: 3},
+			version:       []int64{4},
+			ctx:           context.Background(),
+			expectedError: constants.ErrVersionConflict,
+		},
+		{
+			// removed since it was read
+			user:          &entity.User{ID: 1, Country: "UK", Version: 3},
+			ctx:           context.Background(),
+			expectedError: constants.ErrUserNotFound,
+		},
+		{
+			// the email is taken by another user
+			user:          &entity.User{ID: 1, Email: "taken@gmail.com", Version: 3},
+			duplicate:     true,
+			ctx:           context.Background(),
+			expectedError: constants.ErrEmailExists,
+		},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectBegin()
+		if tc.updated > 0 {
+			r.mock.ExpectExec("UPDATE users SET first_name = \\?, last_name = \\?, nick_name = \\?, email = \\?, country = \\?, password = \\?, updated_at = NOW\\(\\), version = version \\+ 1 WHERE id = \\? AND deleted_at IS NULL AND version = \\?").
+				WithArgs(tc.user.FirstName, tc.user.LastName, tc.user.NickName, tc.user.Email, tc.user.Country, tc.user.Password, tc.user.ID, tc.user.Version).
+				WillReturnResult(sqlmock.NewResult(0, tc.updated))
+			r.expectOutboxEvent("2", tc.user.ID, events.TypeUserUpdated)
+			r.mock.ExpectExec("INSERT INTO user_audit_log").
+				WithArgs(tc.user.ID, string(events.TypeUserUpdated), int64(7), "req-1", "203.0.113.7", []byte(`{"country":{"before":"NL","after":"UK"}}`), sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			r.mock.ExpectCommit()
+		} else if tc.duplicate {
+			r.mock.ExpectExec("UPDATE users SET email = \\?").
+				WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'taken@gmail.com' for key 'users.users_live_email'"})
+			r.mock.ExpectRollback()
+		} else {
+			r.mock.ExpectExec("UPDATE users SET country = \\?, updated_at = NOW\\(\\), version = version \\+ 1 WHERE id = \\? AND deleted_at IS NULL AND version = \\?").
+				WithArgs(tc.user.Country, tc.user.ID, tc.user.Version).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			rows := r.mock.NewRows([]string{"version"})
+			for _, version := range tc.version {
+				rows.AddRow(version)
+			}
+			r.mock.ExpectQuery("SELECT version FROM users WHERE id = \\? AND deleted_at IS NULL").
+				WithArgs(tc.user.ID).
+				WillReturnRows(rows)
+			r.mock.ExpectRollback()
+		}
+		event := &events.Event{
+			ID:      "2",
+			Type:    events.TypeUserUpdated,
+			UserID:  tc.user.ID,
+			Changes: map[string]events.Change{"country": {Before: "NL", After: "UK"}},
+		}
+		ctx := authEntity.ContextWithPrincipal(tc.ctx, &authEntity.Principal{UserID: 7})
+		ctx = requestmeta.ContextWithMeta(ctx, &requestmeta.Meta{RequestID: "req-1", SourceIP: "203.0.113.7"})
+		err := userRepository.Update(ctx, tc.user, event)
+		assert.Equal(r.T(), tc.expectedError, err)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+	}
+}
+
+func (r *RepositoryTestSuite) TestUpdatePassword() {
+	testCases := []struct {
+		id            int64
+		version       int64
+		passwordHash  string
+		ctx           context.Context
+		expectedError error
+	}{
+		{
+			id:            1,
+			version:       3,
+			passwordHash:  "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA",
 			ctx:           context.Background(),
 			expectedError: nil,
 		},
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
-		r.mock.ExpectExec("UPDATE users").
+		// the version is kept, and the rehash is dropped if the user has been changed since it was read
+		r.mock.ExpectExec("UPDATE users SET password = \\? WHERE id = \\? AND version = \\?").
+			WithArgs(tc.passwordHash, tc.id, tc.version).
 			WillReturnResult(sqlmock.NewResult(0, 1))
-		err := userRepository.Update(tc.ctx, tc.user)
+		err := userRepository.UpdatePassword(tc.ctx, tc.id, tc.version, tc.passwordHash)
 		assert.Equal(r.T(), tc.expectedError, err)
+		// any other statement, e.g. writing a change event to the outbox, would fail as unexpected
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
 	}
 }
 
 func (r *RepositoryTestSuite) TestRemove() {
 	testCases := []struct {
 		id            int64
+		deleted       int64
 		ctx           context.Context
 		expectedError error
 	}{
 		{
 			id:            1,
+			deleted:       1,
 			ctx:           context.Background(),
 			expectedError: nil,
 		},
+		{
+			id:            2,
+			deleted:       0,
+			ctx:           context.Background(),
+			expectedError: constants.ErrUserNotFound,
+		},
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
-		r.mock.ExpectExec("DELETE FROM users").
-			WillReturnResult(sqlmock.NewResult(0, 1))
-		err := userRepository.Remove(tc.ctx, tc.id)
+		r.mock.ExpectBegin()
+		r.mock.ExpectExec("UPDATE users SET deleted_at = \\?, version = version \\+ 1 WHERE id = \\? AND deleted_at IS NULL").
+			WithArgs(sqlmock.AnyArg(), tc.id).
+			WillReturnResult(sqlmock.NewResult(0, tc.deleted))
+		if tc.deleted > 0 {
+			r.expectOutboxEvent("3", tc.id, events.TypeUserDeleted)
+			r.expectAuditEntry(tc.id, events.TypeUserDeleted)
+			r.mock.ExpectCommit()
+		} else {
+			r.mock.ExpectRollback()
+		}
+		event := &events.Event{ID: "3", Type: events.TypeUserDeleted, UserID: tc.id}
+		err := userRepository.Remove(tc.ctx, tc.id, event)
 		assert.Equal(r.T(), tc.expectedError, err)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
 	}
 }
 
+func (r *RepositoryTestSuite) TestRestore() {
+	testCases := []struct {
+		id            int64
+		removed       bool
+		taken         int64
+		expectedError error
+	}{
+		{id: 1, removed: true, expectedError: nil},
+		// the user is not removed, or it's purged
+		{id: 2, removed: false, expectedError: constants.ErrUserNotFound},
+		// the email or the nickname is taken by another user since
+		{id: 3, removed: true, taken: 1, expectedError: constants.ErrUserExists},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectBegin()
+		rows := r.mock.NewRows([]string{"email", "nick_name"})
+		if tc.removed {
+			rows.AddRow("test@gmail.com", "test")
+		}
+		r.mock.ExpectQuery("SELECT email, nick_name FROM users WHERE id = \\? AND deleted_at IS NOT NULL FOR UPDATE").
+			WithArgs(tc.id).
+			WillReturnRows(rows)
+		if tc.removed {
+			r.mock.ExpectQuery("SELECT count\\(\\*\\) FROM users WHERE deleted_at IS NULL AND \\(email = \\? OR nick_name = \\?\\)").
+				WithArgs("test@gmail.com", "test").
+				WillReturnRows(r.mock.NewRows([]string{"count"}).AddRow(tc.taken))
+		}
+		if tc.expectedError == nil {
+			r.mock.ExpectExec("UPDATE users SET deleted_at = NULL, version = version \\+ 1 WHERE id = \\?").
+				WithArgs(tc.id).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			r.expectOutboxEvent("4", tc.id, events.TypeUserRestored)
+			r.expectAuditEntry(tc.id, events.TypeUserRestored)
+			r.mock.ExpectCommit()
+		} else {
+			r.mock.ExpectRollback()
+		}
+
+		event := &events.Event{ID: "4", Type: events.TypeUserRestored, UserID: tc.id}
+		err := userRepository.Restore(context.Background(), tc.id, event)
+		assert.Equal(r.T(), tc.expectedError, err)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+	}
+}
+
+func (r *RepositoryTestSuite) TestPurge() {
+	deletedBefore := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		id            int64
+		purged        int64
+		expectedError error
+	}{
+		{id: 1, purged: 1, expectedError: nil},
+		// restored or removed again since it was listed
+		{id: 2, purged: 0, expectedError: constants.ErrUserNotFound},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectBegin()
+		r.mock.ExpectExec("DELETE FROM users WHERE id = \\? AND deleted_at < \\?").
+			WithArgs(tc.id, deletedBefore).
+			WillReturnResult(sqlmock.NewResult(0, tc.purged))
+		if tc.purged > 0 {
+			r.mock.ExpectExec("UPDATE user_audit_log SET changes = NULL WHERE user_id = \\?").
+				WithArgs(tc.id).
+				WillReturnResult(sqlmock.NewResult(0, 3))
+			r.expectOutboxEvent("5", tc.id, events.TypeUserPurged)
+			r.expectAuditEntry(tc.id, events.TypeUserPurged)
+			r.mock.ExpectCommit()
+		} else {
+			r.mock.ExpectRollback()
+		}
+
+		event := &events.Event{ID: "5", Type: events.TypeUserPurged, UserID: tc.id}
+		err := userRepository.Purge(context.Background(), tc.id, deletedBefore, event)
+		assert.Equal(r.T(), tc.expectedError, err)
+		assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+	}
+}
+
+func (r *RepositoryTestSuite) TestGetDeleted() {
+	now := time.Now()
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version, deleted_at FROM users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC LIMIT \\? OFFSET \\?").
+		WithArgs(int64(10), int64(10)).
+		WillReturnRows(r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at", "version", "deleted_at"}).
+			AddRow(1, "test", "test", "test", "test@gmail.com", "UK", now, now, 3, now))
+	users, err := userRepository.GetDeleted(context.Background(), 2, 10)
+	r.Require().NoError(err)
+	assert.Equal(r.T(), []*entity.User{{ID: 1, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", CreatedAt: now, UpdatedAt: now, Version: 3, DeletedAt: &now}}, users)
+
+	r.mock.ExpectQuery("SELECT count\\(\\*\\) FROM users WHERE deleted_at IS NOT NULL").
+		WillReturnRows(r.mock.NewRows([]string{"count"}).AddRow(11))
+	count, err := userRepository.GetDeletedCount(context.Background())
+	r.Require().NoError(err)
+	assert.Equal(r.T(), uint64(11), count)
+
+	deletedBefore := now.Add(-time.Hour)
+	r.mock.ExpectQuery("SELECT id FROM users WHERE deleted_at < \\? ORDER BY deleted_at, id LIMIT \\?").
+		WithArgs(deletedBefore, int64(100)).
+		WillReturnRows(r.mock.NewRows([]string{"id"}).AddRow(3).AddRow(7))
+	IDs, err := userRepository.GetExpiredIDs(context.Background(), deletedBefore, 100)
+	r.Require().NoError(err)
+	assert.Equal(r.T(), []int64{3, 7}, IDs)
+	assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+}
+
 func (r *RepositoryTestSuite) TestGetByID() {
 	testCases := []struct {
 		id                 int64
@@ -148,36 +417,36 @@ func (r *RepositoryTestSuite) TestGetByID() {
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
+				Password:  "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA",
 				Email:     "test@gmail.com",
 				Country:   "UK",
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
+				Version:   2,
 			},
 			expectedError: nil,
 		},
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
-		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at"}).
+		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "password", "email", "country", "created_at", "updated_at", "version"}).
 			AddRow(
 				tc.expectedUserEntity.ID,
 				tc.expectedUserEntity.FirstName,
 				tc.expectedUserEntity.LastName,
 				tc.expectedUserEntity.NickName,
+				tc.expectedUserEntity.Password,
 				tc.expectedUserEntity.Email,
 				tc.expectedUserEntity.Country,
 				tc.expectedUserEntity.CreatedAt,
 				tc.expectedUserEntity.UpdatedAt,
+				tc.expectedUserEntity.Version,
 			)
 
-		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users").
+		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, password, email, country, created_at, updated_at, version FROM users").
 			WithArgs(tc.id).
 			WillReturnRows(rows)
 		userEntity, err := userRepository.GetByID(tc.ctx, tc.id)
@@ -201,36 +470,36 @@ func (r *RepositoryTestSuite) TestGetByNickName() {
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
+				Password:  "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA",
 				Email:     "test@gmail.com",
 				Country:   "UK",
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
+				Version:   2,
 			},
 			expectedError: nil,
 		},
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
-		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at"}).
+		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "password", "email", "country", "created_at", "updated_at", "version"}).
 			AddRow(
 				tc.expectedUserEntity.ID,
 				tc.expectedUserEntity.FirstName,
 				tc.expectedUserEntity.LastName,
 				tc.expectedUserEntity.NickName,
+				tc.expectedUserEntity.Password,
 				tc.expectedUserEntity.Email,
 				tc.expectedUserEntity.Country,
 				tc.expectedUserEntity.CreatedAt,
 				tc.expectedUserEntity.UpdatedAt,
+				tc.expectedUserEntity.Version,
 			)
 
-		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users").
+		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, password, email, country, created_at, updated_at, version FROM users").
 			WithArgs(tc.nickname).
 			WillReturnRows(rows)
 		userEntity, err := userRepository.GetByNickName(tc.ctx, tc.nickname)
@@ -254,36 +523,36 @@ func (r *RepositoryTestSuite) TestGetByEmail() {
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
+				Password:  "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA",
 				Email:     "test@gmail.com",
 				Country:   "UK",
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
+				Version:   2,
 			},
 			expectedError: nil,
 		},
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
-		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at"}).
+		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "password", "email", "country", "created_at", "updated_at", "version"}).
 			AddRow(
 				tc.expectedUserEntity.ID,
 				tc.expectedUserEntity.FirstName,
 				tc.expectedUserEntity.LastName,
 				tc.expectedUserEntity.NickName,
+				tc.expectedUserEntity.Password,
 				tc.expectedUserEntity.Email,
 				tc.expectedUserEntity.Country,
 				tc.expectedUserEntity.CreatedAt,
 				tc.expectedUserEntity.UpdatedAt,
+				tc.expectedUserEntity.Version,
 			)
 
-		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users").
+		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, password, email, country, created_at, updated_at, version FROM users").
 			WithArgs(tc.email).
 			WillReturnRows(rows)
 		userEntity, err := userRepository.GetByEmail(tc.ctx, tc.email)
@@ -303,7 +572,7 @@ func (r *RepositoryTestSuite) TestGet() {
 	}{
 		{
 			filter: &entity.Filter{
-				Country: "UK",
+				Countries: []string{"UK"},
 			},
 			ctx:      context.Background(),
 			page:     1,
@@ -325,15 +594,11 @@ func (r *RepositoryTestSuite) TestGet() {
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
 
-		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at"})
+		rows := r.mock.NewRows([]string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at", "version"})
 		for _, expectedUserEntity := range tc.expectedUserEntities {
 			rows.AddRow(
 				expectedUserEntity.ID,
@@ -344,17 +609,80 @@ func (r *RepositoryTestSuite) TestGet() {
 				expectedUserEntity.Country,
 				expectedUserEntity.CreatedAt,
 				expectedUserEntity.UpdatedAt,
+				expectedUserEntity.Version,
 			)
 		}
 
-		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users").
+		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND country IN \\(\\?\\) ORDER BY id LIMIT \\? OFFSET \\?").
+			WithArgs(tc.filter.Countries[0], tc.pageSize, (tc.page-1)*tc.pageSize).
 			WillReturnRows(rows)
-		userEntities, err := userRepository.Get(tc.ctx, tc.filter, tc.page, tc.pageSize)
+		userEntities, err := userRepository.Get(tc.ctx, tc.filter, entity.DefaultSort, tc.page, tc.pageSize)
 		assert.Equal(r.T(), tc.expectedError, err)
 		assert.Equal(r.T(), tc.expectedUserEntities, userEntities)
 	}
 }
 
+func (r *RepositoryTestSuite) TestGetByIDs() {
+	IDs := make([]int64, getByIDsChunkSize+1)
+	for i := range IDs {
+		IDs[i] = int64(i + 1)
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	// the IDs are queried in chunks
+	columns := []string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at", "version"}
+	now := time.Now()
+	for _, chunk := range [][]int64{IDs[:getByIDsChunkSize], IDs[getByIDsChunkSize:]} {
+		args := make([]driver.Value, len(chunk))
+		for i, ID := range chunk {
+			args[i] = ID
+		}
+
+		r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND id IN \\(\\?(, \\?)*\\)").
+			WithArgs(args...).
+			WillReturnRows(r.mock.NewRows(columns).AddRow(chunk[0], "test", "test", "test", "test@gmail.com", "UK", now, now, 1))
+	}
+
+	userEntities, err := userRepository.GetByIDs(context.Background(), IDs)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), []*entity.User{
+		{ID: 1, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", CreatedAt: now, UpdatedAt: now, Version: 1},
+		{ID: getByIDsChunkSize + 1, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", CreatedAt: now, UpdatedAt: now, Version: 1},
+	}, userEntities)
+	assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+
+	// no query is needed without IDs
+	userEntities, err = userRepository.GetByIDs(context.Background(), nil)
+	assert.NoError(r.T(), err)
+	assert.Empty(r.T(), userEntities)
+}
+
+func (r *RepositoryTestSuite) TestGetPage() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	userRepository := NewUserRepository(r.db)
+
+	columns := []string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at", "version"}
+	now := time.Now()
+
+	// the users before the cursor are read in the reverse order and returned in the order of the sort
+	r.mock.ExpectQuery("SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND country IN \\(\\?\\) AND id < \\? ORDER BY id DESC LIMIT \\?").
+		WithArgs("UK", int64(10), int64(3)).
+		WillReturnRows(r.mock.NewRows(columns).
+			AddRow(9, "test", "test", "b", "b@gmail.com", "UK", now, now, 1).
+			AddRow(8, "test", "test", "a", "a@gmail.com", "UK", now, now, 1))
+
+	cursor := &entity.Cursor{Sort: entity.DefaultSort, Values: []interface{}{int64(10)}, Backward: true}
+	userEntities, err := userRepository.GetPage(context.Background(), &entity.Filter{Countries: []string{"UK"}}, entity.DefaultSort, cursor, 3)
+	assert.NoError(r.T(), err)
+	assert.Equal(r.T(), []*entity.User{
+		{ID: 8, FirstName: "test", LastName: "test", NickName: "a", Email: "a@gmail.com", Country: "UK", CreatedAt: now, UpdatedAt: now, Version: 1},
+		{ID: 9, FirstName: "test", LastName: "test", NickName: "b", Email: "b@gmail.com", Country: "UK", CreatedAt: now, UpdatedAt: now, Version: 1},
+	}, userEntities)
+	assert.NoError(r.T(), r.mock.ExpectationsWereMet())
+}
+
 func (r *RepositoryTestSuite) TestGetCount() {
 	testCases := []struct {
 		filter        *entity.Filter
@@ -364,7 +692,7 @@ func (r *RepositoryTestSuite) TestGetCount() {
 	}{
 		{
 			filter: &entity.Filter{
-				Country: "UK",
+				Countries: []string{"UK"},
 			},
 			ctx:           context.Background(),
 			expectedCount: 1,
@@ -373,17 +701,14 @@ func (r *RepositoryTestSuite) TestGetCount() {
 	}
 
 	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
+	userRepository := NewUserRepository(r.db)
 
 	for _, tc := range testCases {
 
 		rows := r.mock.NewRows([]string{"total"}).AddRow(tc.expectedCount)
 
-		r.mock.ExpectQuery("SELECT count\\(\\*\\) as total FROM users").
+		r.mock.ExpectQuery("SELECT count\\(\\*\\) as total FROM users WHERE deleted_at IS NULL AND country IN \\(\\?\\)").
+			WithArgs(tc.filter.Countries[0]).
 			WillReturnRows(rows)
 		count, err := userRepository.GetCount(tc.ctx, tc.filter)
 		assert.Equal(r.T(), tc.expectedError, err)
@@ -391,25 +716,18 @@ func (r *RepositoryTestSuite) TestGetCount() {
 	}
 }
 
-func (r *RepositoryTestSuite) TestPublishUserChangeEvent() {
-	testCases := []struct {
-		ID int64
-	}{
-		{ID: 1},
-		{ID: 2},
-	}
-	r.db, r.mock = databaseMocks.NewDBMock()
-	r.redis = redisMocks.NewRedisMock()
-	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
-		Addrs: []string{r.redis.Addr()},
-	})
-	userRepository := NewUserRepository(r.db, redisClient)
-
-	for _, tc := range testCases {
-		err := userRepository.PublishUserChangeEvent(tc.ID)
-		assert.Nil(r.T(), err)
-	}
+// expectOutboxEvent - expects the event to be written to the outbox
+func (r *RepositoryTestSuite) expectOutboxEvent(eventID string, userID int64, eventType events.Type) {
+	r.mock.ExpectExec("INSERT INTO user_events_outbox").
+		WithArgs(eventID, userID, string(eventType), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
 
+// expectAuditEntry - expects the change to be recorded in the audit log without an actor or a request, e.g. by a background job
+func (r *RepositoryTestSuite) expectAuditEntry(userID int64, eventType events.Type) {
+	r.mock.ExpectExec("INSERT INTO user_audit_log").
+		WithArgs(userID, string(eventType), nil, "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
 }
 
 func TestRepositoryTestSuite(t *testing.T) {
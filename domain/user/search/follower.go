@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"faceit/domain/user/repository"
+	"log"
+	"time"
+)
+
+// FollowerConfig - The tuning of the follower
+type FollowerConfig struct {
+	// PollInterval - How often the outbox is checked for new events
+	PollInterval time.Duration
+	// BatchSize - The maximum number of events read from the outbox at once
+	BatchSize int64
+	// Settle - How long an event is left in the outbox before it's followed, it must be longer than the transactions writing the events
+	Settle time.Duration
+}
+
+// Follower - Keeps the index of the replica in sync by following the events written to the outbox.
+// Every replica follows the outbox on its own, whatever the event sink is and whichever replica relays the events,
+// and the position is kept in the index, so a restarted replica resumes where it stopped.
+type Follower struct {
+	index      *Index
+	indexer    *Indexer
+	repository repository.IOutboxRepository
+	config     FollowerConfig
+}
+
+func NewFollower(index *Index, users repository.IUsersRepository, outbox repository.IOutboxRepository, config FollowerConfig) *Follower {
+	return &Follower{
+		index:      index,
+		indexer:    NewIndexer(index, users),
+		repository: outbox,
+		config:     config,
+	}
+}
+
+// Run - Follows the outbox until the context is cancelled
+func (f *Follower) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := f.Follow(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("failed to update the search index: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Follow - Indexes one batch of the events after the position of the index and returns the number of them.
+// The position is moved past the indexed events even when a later one fails, so they are not indexed again.
+func (f *Follower) Follow(ctx context.Context) (int, error) {
+	position, err := f.index.Position()
+	if err != nil {
+		return 0, err
+	}
+	if position == nil {
+		position = &Position{}
+	}
+
+	now := time.Now()
+	outboxEvents, err := f.repository.GetEventsAfter(ctx, position.EventID, f.config.Settle, f.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for _, outboxEvent := range outboxEvents {
+		// the indexer only needs the type and the user, so the payload isn't decoded
+		event := &events.Event{ID: outboxEvent.EventID, Type: events.Type(outboxEvent.Type), UserID: outboxEvent.UserID}
+		if err = f.indexer.Handle(ctx, event); err != nil {
+			break
+		}
+
+		position.EventID = outboxEvent.ID
+		indexed++
+	}
+
+	// the index has all the events written before now only when there are no more to read
+	if err == nil && int64(len(outboxEvents)) < f.config.BatchSize {
+		position.SyncedAt = now
+	}
+
+	if positionErr := f.index.SetPosition(position); err == nil {
+		err = positionErr
+	}
+
+	return indexed, err
+}
+
+// IsStale - Tells if the index may have missed the events which are removed from the outbox after the retention,
+// as it was last in sync before them, so it must be rebuilt. A retention of 0 keeps the events forever.
+func IsStale(position *Position, retention, settle time.Duration) bool {
+	if position == nil {
+		return true
+	}
+
+	return retention > 0 && time.Since(position.SyncedAt)+settle > retention
+}
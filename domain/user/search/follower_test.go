@@ -0,0 +1,159 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/user/entity"
+	repositoryMocks "faceit/mocks/domain/user/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type FollowerTestSuite struct {
+	suite.Suite
+}
+
+var testFollowerConfig = FollowerConfig{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    3,
+	Settle:       3 * time.Second,
+}
+
+func (f *FollowerTestSuite) TestFollow() {
+	dbErr := errors.New("connection refused")
+	syncedAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		position        *Position
+		outboxEvents    []*entity.OutboxEvent
+		failingUser     int64
+		expectedAfterID int64
+		expectedIndexed int
+		expectedError   error
+		expectedEventID int64
+		expectedSynced  bool
+		expectedFound   map[int64]bool
+	}{
+		{
+			// an index without a position follows the outbox from the start
+			outboxEvents: []*entity.OutboxEvent{
+				{ID: 1, EventID: "a", UserID: 1, Type: "user.created"},
+				{ID: 2, EventID: "b", UserID: 2, Type: "user.deleted"},
+			},
+			expectedIndexed: 2,
+			expectedEventID: 2,
+			expectedSynced:  true,
+			expectedFound:   map[int64]bool{1: true},
+		},
+		{
+			// a full batch may be followed by more events, the index isn't in sync yet
+			position: &Position{EventID: 10, SyncedAt: syncedAt},
+			outboxEvents: []*entity.OutboxEvent{
+				{ID: 11, EventID: "a", UserID: 1, Type: "user.updated"},
+				{ID: 13, EventID: "b", UserID: 2, Type: "user.updated"},
+				{ID: 14, EventID: "c", UserID: 1, Type: "user.updated"},
+			},
+			expectedAfterID: 10,
+			expectedIndexed: 3,
+			expectedEventID: 14,
+			expectedFound:   map[int64]bool{1: true, 2: true},
+		},
+		{
+			// the events before the failed one are not indexed again
+			position: &Position{EventID: 10, SyncedAt: syncedAt},
+			outboxEvents: []*entity.OutboxEvent{
+				{ID: 11, EventID: "a", UserID: 1, Type: "user.updated"},
+				{ID: 12, EventID: "b", UserID: 2, Type: "user.updated"},
+			},
+			failingUser:     2,
+			expectedAfterID: 10,
+			expectedIndexed: 1,
+			expectedError:   dbErr,
+			expectedEventID: 11,
+			expectedFound:   map[int64]bool{1: true, 2: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		index, err := NewMemoryIndex()
+		f.Require().NoError(err)
+		f.Require().NoError(index.Index(&entity.User{ID: 2, NickName: "test"}))
+		if tc.position != nil {
+			f.Require().NoError(index.SetPosition(tc.position))
+		}
+
+		usersMock := &repositoryMocks.IUsersRepository{}
+		usersMock.On("GetByID", mock.Anything, tc.failingUser).Return(nil, dbErr)
+		usersMock.On("GetByID", mock.Anything, mock.Anything).Return(func(_ context.Context, ID int64) *entity.User {
+			return &entity.User{ID: ID, NickName: "test"}
+		}, nil)
+		outboxMock := &repositoryMocks.IOutboxRepository{}
+		outboxMock.On("GetEventsAfter", mock.Anything, tc.expectedAfterID, testFollowerConfig.Settle, testFollowerConfig.BatchSize).
+			Return(tc.outboxEvents, nil).Once()
+
+		startedAt := time.Now()
+		indexed, err := NewFollower(index, usersMock, outboxMock, testFollowerConfig).Follow(context.Background())
+		assert.Equal(f.T(), tc.expectedError, err)
+		assert.Equal(f.T(), tc.expectedIndexed, indexed)
+
+		position, err := index.Position()
+		f.Require().NoError(err)
+		assert.Equal(f.T(), tc.expectedEventID, position.EventID)
+		assert.Equal(f.T(), tc.expectedSynced, !position.SyncedAt.Before(startedAt))
+
+		hits, _, err := index.Search("test", AllFields, 10)
+		f.Require().NoError(err)
+		found := make(map[int64]bool)
+		for _, hit := range hits {
+			found[hit.ID] = true
+		}
+		assert.Equal(f.T(), tc.expectedFound, found)
+		outboxMock.AssertExpectations(f.T())
+		f.Require().NoError(index.Close())
+	}
+}
+
+func (f *FollowerTestSuite) TestRun() {
+	index, err := NewMemoryIndex()
+	f.Require().NoError(err)
+	defer func() {
+		_ = index.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	outboxMock := &repositoryMocks.IOutboxRepository{}
+	outboxMock.On("GetEventsAfter", mock.Anything, int64(0), testFollowerConfig.Settle, testFollowerConfig.BatchSize).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil, nil).Once()
+
+	NewFollower(index, &repositoryMocks.IUsersRepository{}, outboxMock, testFollowerConfig).Run(ctx)
+	outboxMock.AssertExpectations(f.T())
+}
+
+func (f *FollowerTestSuite) TestIsStale() {
+	testCases := []struct {
+		position  *Position
+		retention time.Duration
+		expected  bool
+	}{
+		{position: nil, retention: time.Hour, expected: true},
+		{position: &Position{SyncedAt: time.Now().Add(-time.Minute)}, retention: time.Hour, expected: false},
+		{position: &Position{SyncedAt: time.Now().Add(-2 * time.Hour)}, retention: time.Hour, expected: true},
+		// the events written within the settle time before the sync may be removed a little earlier
+		{position: &Position{SyncedAt: time.Now().Add(-time.Hour + time.Second)}, retention: time.Hour, expected: true},
+		// the events are never removed
+		{position: &Position{SyncedAt: time.Now().Add(-1000 * time.Hour)}, retention: 0, expected: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(f.T(), tc.expected, IsStale(tc.position, tc.retention, testFollowerConfig.Settle))
+	}
+}
+
+func TestFollowerTestSuite(t *testing.T) {
+	suite.Run(t, new(FollowerTestSuite))
+}
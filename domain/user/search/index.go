@@ -0,0 +1,289 @@
+package search
+
+import (
+	"encoding/json"
+	"faceit/domain/user/entity"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// The fields of the users in the index, the email only has its local part, the part before the @
+const (
+	FieldNickName  = "nick_name"
+	FieldFirstName = "first_name"
+	FieldLastName  = "last_name"
+	FieldEmail     = "email"
+)
+
+// PublicFields - The fields which can be searched without reading the PII of the users
+var PublicFields = []string{FieldNickName}
+
+// AllFields - All the searchable fields
+var AllFields = []string{FieldNickName, FieldFirstName, FieldLastName, FieldEmail}
+
+// fieldBoosts - The nickname is how the users know each other, so its matches rank higher
+var fieldBoosts = map[string]float64{FieldNickName: 2}
+
+// nameAnalyzer - Splits the names into words and lowercases them, unlike the standard analyzer it keeps the stop words, e.g. a nickname "the"
+const nameAnalyzer = "name"
+
+// The boosts of the kinds of matches, an exact word ranks above a word starting with the last word of the text, which ranks above a typo
+const (
+	exactBoost  = 3
+	prefixBoost = 2
+	fuzzyBoost  = 1
+)
+
+// Hit - A user matching the searched text, a higher score is a better match
+type Hit struct {
+	ID    int64
+	Score float64
+}
+
+// IIndex - The full-text index of the users
+type IIndex interface {
+	// Index - Adds the user to the index or replaces the indexed one
+	Index(user *entity.User) error
+	Delete(ID int64) error
+	// Search - Returns up to limit users best matching the text in the fields, the best first, and the total number of the matching users
+	Search(text string, fields []string, limit int) ([]Hit, uint64, error)
+	Close() error
+}
+
+type Index struct {
+	index bleve.Index
+}
+
+// positionKey - The internal key of the index the position of the index in the outbox is kept under
+var positionKey = []byte("outbox_position")
+
+// Position - How far the index is in sync with the outbox of the user events
+type Position struct {
+	// EventID - The ID of the last event in the outbox the index has the changes of
+	EventID int64 `json:"event_id"`
+	// SyncedAt - When the index last had all the events written before it, less the settle time of the outbox
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// Open - Opens the index in the directory, it must have been built by Rebuild
+func Open(path string) (*Index, error) {
+	index, err := bleve.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the search index %s: %w", path, err)
+	}
+
+	return &Index{index: index}, nil
+}
+
+// Exists - Tells if there is an index in the directory
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// NewMemoryIndex - Creates an empty index which is only kept in memory, e.g. for the tests
+func NewMemoryIndex() (*Index, error) {
+	index, err := bleve.NewMemOnly(newMapping())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the search index: %w", err)
+	}
+
+	return &Index{index: index}, nil
+}
+
+func create(path string) (*Index, error) {
+	index, err := bleve.New(path, newMapping())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the search index %s: %w", path, err)
+	}
+
+	return &Index{index: index}, nil
+}
+
+func (i *Index) Index(user *entity.User) error {
+	if err := i.index.Index(documentID(user.ID), document(user)); err != nil {
+		return fmt.Errorf("failed to index user %d: %w", user.ID, err)
+	}
+
+	return nil
+}
+
+func (i *Index) Delete(ID int64) error {
+	if err := i.index.Delete(documentID(ID)); err != nil {
+		return fmt.Errorf("failed to remove user %d from the search index: %w", ID, err)
+	}
+
+	return nil
+}
+
+// Search - Matches the words of the text in the fields exactly, with typos, and the last word as the prefix of a word, so the text can be autocompleted
+func (i *Index) Search(text string, fields []string, limit int) ([]Hit, uint64, error) {
+	words := i.words(text)
+	if len(words) == 0 {
+		return nil, 0, nil
+	}
+
+	var queries []query.Query
+	for _, field := range fields {
+		boost := fieldBoosts[field]
+		if boost == 0 {
+			boost = 1
+		}
+
+		exact := bleve.NewMatchQuery(text)
+		exact.SetField(field)
+		exact.Analyzer = nameAnalyzer
+		exact.SetBoost(exactBoost * boost)
+
+		prefix := bleve.NewPrefixQuery(words[len(words)-1])
+		prefix.SetField(field)
+		prefix.SetBoost(prefixBoost * boost)
+
+		queries = append(queries, exact, prefix)
+
+		// a typo in a short word matches too many other words
+		if fuzziness := fuzziness(words); fuzziness > 0 {
+			fuzzy := bleve.NewMatchQuery(text)
+			fuzzy.SetField(field)
+			fuzzy.Analyzer = nameAnalyzer
+			fuzzy.SetFuzziness(fuzziness)
+			fuzzy.SetBoost(fuzzyBoost * boost)
+			queries = append(queries, fuzzy)
+		}
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewDisjunctionQuery(queries...), limit, 0, false)
+	result, err := i.index.Search(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search the users: %w", err)
+	}
+
+	hits := make([]Hit, 0, len(result.Hits))
+	for _, match := range result.Hits {
+		ID, err := strconv.ParseInt(match.ID, 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid user ID %q in the search index: %w", match.ID, err)
+		}
+		hits = append(hits, Hit{ID: ID, Score: match.Score})
+	}
+
+	return hits, result.Total, nil
+}
+
+// Position - Returns the position of the index in the outbox, nil if the index doesn't have one, e.g. when it's built by an older version
+func (i *Index) Position() (*Position, error) {
+	value, err := i.index.GetInternal(positionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the position of the search index: %w", err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	position := new(Position)
+	if err := json.Unmarshal(value, position); err != nil {
+		return nil, fmt.Errorf("failed to decode the position of the search index: %w", err)
+	}
+
+	return position, nil
+}
+
+// SetPosition - Records the position of the index in the outbox, so it can be resumed after a restart
+func (i *Index) SetPosition(position *Position) error {
+	value, err := json.Marshal(position)
+	if err != nil {
+		return fmt.Errorf("failed to encode the position of the search index: %w", err)
+	}
+
+	if err := i.index.SetInternal(positionKey, value); err != nil {
+		return fmt.Errorf("failed to write the position of the search index: %w", err)
+	}
+
+	return nil
+}
+
+func (i *Index) Close() error {
+	return i.index.Close()
+}
+
+// words - The words of the text as they are in the index
+func (i *Index) words(text string) []string {
+	analyzer := i.index.Mapping().AnalyzerNamed(nameAnalyzer)
+
+	var words []string
+	for _, token := range analyzer.Analyze([]byte(text)) {
+		words = append(words, string(token.Term))
+	}
+
+	return words
+}
+
+// fuzziness - The number of the typos allowed in every word, it grows with the shortest word
+func fuzziness(words []string) int {
+	shortest := utf8.RuneCountInString(words[0])
+	for _, word := range words[1:] {
+		if length := utf8.RuneCountInString(word); length < shortest {
+			shortest = length
+		}
+	}
+
+	switch {
+	case shortest < 3:
+		return 0
+	case shortest < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func newMapping() mapping.IndexMapping {
+	indexMapping := bleve.NewIndexMapping()
+	if err := indexMapping.AddCustomAnalyzer(nameAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name},
+	}); err != nil {
+		// the analyzer is made of the built-in parts, so it can't fail
+		panic(err)
+	}
+
+	userMapping := bleve.NewDocumentStaticMapping()
+	for _, field := range AllFields {
+		fieldMapping := bleve.NewTextFieldMapping()
+		fieldMapping.Analyzer = nameAnalyzer
+		fieldMapping.Store = false
+		fieldMapping.IncludeInAll = false
+		userMapping.AddFieldMappingsAt(field, fieldMapping)
+	}
+
+	indexMapping.DefaultMapping = userMapping
+	indexMapping.DefaultAnalyzer = nameAnalyzer
+	return indexMapping
+}
+
+func document(user *entity.User) map[string]interface{} {
+	localPart, _, _ := strings.Cut(user.Email, "@")
+
+	return map[string]interface{}{
+		FieldNickName:  user.NickName,
+		FieldFirstName: user.FirstName,
+		FieldLastName:  user.LastName,
+		FieldEmail:     localPart,
+	}
+}
+
+func documentID(ID int64) string {
+	return strconv.FormatInt(ID, 10)
+}
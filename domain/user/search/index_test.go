@@ -0,0 +1,139 @@
+package search
+
+import (
+	"context"
+	"faceit/domain/user/entity"
+	repositoryMocks "faceit/mocks/domain/user/repository"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexTestSuite struct {
+	suite.Suite
+	index *Index
+}
+
+var testUsers = []*entity.User{
+	{ID: 1, FirstName: "Mehran", LastName: "Rahmanzadeh", NickName: "mehran", Email: "m.rahmanzadeh@gmail.com"},
+	{ID: 2, FirstName: "Sarah", LastName: "Connor", NickName: "terminator", Email: "sarah@gmail.com"},
+	{ID: 3, FirstName: "John", LastName: "Mehranfar", NickName: "johnny", Email: "john@gmail.com"},
+	{ID: 4, FirstName: "Kyle", LastName: "Reese", NickName: "the kyle", Email: "kyle@gmail.com"},
+}
+
+func (i *IndexTestSuite) SetupTest() {
+	var err error
+	i.index, err = NewMemoryIndex()
+	i.Require().NoError(err)
+
+	for _, user := range testUsers {
+		i.Require().NoError(i.index.Index(user))
+	}
+}
+
+func (i *IndexTestSuite) TearDownTest() {
+	i.Require().NoError(i.index.Close())
+}
+
+func (i *IndexTestSuite) TestSearch() {
+	testCases := []struct {
+		text          string
+		fields        []string
+		expectedIDs   []int64
+		expectedTotal uint64
+	}{
+		// the exact nickname ranks above the prefix of a last name
+		{text: "mehran", fields: AllFields, expectedIDs: []int64{1, 3}, expectedTotal: 2},
+		// the last word is autocompleted
+		{text: "termi", fields: AllFields, expectedIDs: []int64{2}, expectedTotal: 1},
+		// a typo
+		{text: "terminatr", fields: AllFields, expectedIDs: []int64{2}, expectedTotal: 1},
+		{text: "Sarah Connor", fields: AllFields, expectedIDs: []int64{2}, expectedTotal: 1},
+		// the local part of the email is split into words
+		{text: "rahmanzadeh", fields: AllFields, expectedIDs: []int64{1}, expectedTotal: 1},
+		// the stop words are kept
+		{text: "the", fields: AllFields, expectedIDs: []int64{4}, expectedTotal: 1},
+		// the names are not searched without them in the fields
+		{text: "connor", fields: PublicFields, expectedIDs: []int64{}, expectedTotal: 0},
+		{text: "reese", fields: PublicFields, expectedIDs: []int64{}, expectedTotal: 0},
+		{text: " ", fields: AllFields, expectedIDs: []int64{}, expectedTotal: 0},
+	}
+
+	for _, tc := range testCases {
+		hits, total, err := i.index.Search(tc.text, tc.fields, 10)
+		i.Require().NoError(err, tc.text)
+
+		IDs := make([]int64, len(hits))
+		for j, hit := range hits {
+			IDs[j] = hit.ID
+		}
+		assert.Equal(i.T(), tc.expectedIDs, IDs, tc.text)
+		assert.Equal(i.T(), tc.expectedTotal, total, tc.text)
+	}
+}
+
+func (i *IndexTestSuite) TestIndexAndDelete() {
+	i.Require().NoError(i.index.Index(&entity.User{ID: 2, NickName: "skynet"}))
+	hits, _, err := i.index.Search("terminator", AllFields, 10)
+	i.Require().NoError(err)
+	assert.Empty(i.T(), hits)
+
+	hits, _, err = i.index.Search("skynet", AllFields, 10)
+	i.Require().NoError(err)
+	assert.Equal(i.T(), []int64{2}, []int64{hits[0].ID})
+
+	i.Require().NoError(i.index.Delete(2))
+	hits, _, err = i.index.Search("skynet", AllFields, 10)
+	i.Require().NoError(err)
+	assert.Empty(i.T(), hits)
+}
+
+func (i *IndexTestSuite) TestPosition() {
+	position, err := i.index.Position()
+	i.Require().NoError(err)
+	assert.Nil(i.T(), position)
+
+	syncedAt := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	i.Require().NoError(i.index.SetPosition(&Position{EventID: 42, SyncedAt: syncedAt}))
+	position, err = i.index.Position()
+	i.Require().NoError(err)
+	assert.Equal(i.T(), &Position{EventID: 42, SyncedAt: syncedAt}, position)
+}
+
+func (i *IndexTestSuite) TestRebuild() {
+	repositoryMock := &repositoryMocks.IUsersRepository{}
+	repositoryMock.On("GetPage", mock.Anything, &entity.Filter{}, entity.DefaultSort, (*entity.Cursor)(nil), int64(rebuildBatchSize)).
+		Return(testUsers, nil).Once()
+	outboxMock := &repositoryMocks.IOutboxRepository{}
+	outboxMock.On("GetLastSettledEventID", mock.Anything, 3*time.Second).Return(int64(42), nil).Once()
+
+	path := filepath.Join(i.T().TempDir(), "search")
+	startedAt := time.Now()
+	count, err := Rebuild(context.Background(), path, repositoryMock, outboxMock, 3*time.Second)
+	i.Require().NoError(err)
+	assert.Equal(i.T(), len(testUsers), count)
+	assert.False(i.T(), Exists(path+".rebuild"))
+
+	index, err := Open(path)
+	i.Require().NoError(err)
+	hits, _, err := index.Search("johnny", AllFields, 10)
+	i.Require().NoError(err)
+	assert.Equal(i.T(), []Hit{{ID: 3, Score: hits[0].Score}}, hits)
+
+	// the index follows the outbox from the last event before it was filled
+	position, err := index.Position()
+	i.Require().NoError(err)
+	assert.Equal(i.T(), int64(42), position.EventID)
+	assert.False(i.T(), position.SyncedAt.Before(startedAt))
+	i.Require().NoError(index.Close())
+	repositoryMock.AssertExpectations(i.T())
+	outboxMock.AssertExpectations(i.T())
+}
+
+func TestIndexTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexTestSuite))
+}
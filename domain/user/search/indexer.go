@@ -0,0 +1,38 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/user/events"
+	"faceit/domain/user/repository"
+)
+
+// Indexer - Keeps the index in sync with the users by their change events.
+// The user is read from the database rather than from the event, so the events can be handled more than once and out of order.
+type Indexer struct {
+	index      IIndex
+	repository repository.IUsersRepository
+}
+
+func NewIndexer(index IIndex, repository repository.IUsersRepository) *Indexer {
+	return &Indexer{index: index, repository: repository}
+}
+
+// Handle - Indexes the current state of the changed user, it's a stream.Handler
+func (i *Indexer) Handle(ctx context.Context, event *events.Event) error {
+	if event.Type == events.TypeUserDeleted || event.Type == events.TypeUserPurged {
+		return i.index.Delete(event.UserID)
+	}
+
+	user, err := i.repository.GetByID(ctx, event.UserID)
+	if errors.Is(err, constants.ErrUserNotFound) {
+		// removed since, its own event removes it again
+		return i.index.Delete(event.UserID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return i.index.Index(user)
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"faceit/domain/constants"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	repositoryMocks "faceit/mocks/domain/user/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type IndexerTestSuite struct {
+	suite.Suite
+}
+
+func (i *IndexerTestSuite) TestHandle() {
+	user := &entity.User{ID: 1, NickName: "test"}
+	dbErr := errors.New("connection refused")
+
+	testCases := []struct {
+		event           *events.Event
+		repositoryUser  *entity.User
+		repositoryError error
+		expectedIndex   bool
+		expectedDelete  bool
+		expectedError   error
+	}{
+		{event: &events.Event{Type: events.TypeUserCreated, UserID: 1}, repositoryUser: user, expectedIndex: true},
+		{event: &events.Event{Type: events.TypeUserUpdated, UserID: 1}, repositoryUser: user, expectedIndex: true},
+		{event: &events.Event{Type: events.TypeUserDeleted, UserID: 1}, expectedDelete: true},
+		{event: &events.Event{Type: events.TypeUserPurged, UserID: 1}, expectedDelete: true},
+		{event: &events.Event{Type: events.TypeUserRestored, UserID: 1}, repositoryUser: user, expectedIndex: true},
+		// the user is removed after it was updated
+		{event: &events.Event{Type: events.TypeUserUpdated, UserID: 1}, repositoryError: constants.ErrUserNotFound, expectedDelete: true},
+		// the event is retried
+		{event: &events.Event{Type: events.TypeUserUpdated, UserID: 1}, repositoryError: dbErr, expectedError: dbErr},
+	}
+
+	for _, tc := range testCases {
+		index, err := NewMemoryIndex()
+		i.Require().NoError(err)
+		i.Require().NoError(index.Index(&entity.User{ID: 1, NickName: "old"}))
+
+		repositoryMock := &repositoryMocks.IUsersRepository{}
+		repositoryMock.On("GetByID", mock.Anything, int64(1)).Return(tc.repositoryUser, tc.repositoryError)
+
+		err = NewIndexer(index, repositoryMock).Handle(context.Background(), tc.event)
+		assert.Equal(i.T(), tc.expectedError, err)
+
+		newHits, _, err := index.Search("test", AllFields, 10)
+		i.Require().NoError(err)
+		oldHits, _, err := index.Search("old", AllFields, 10)
+		i.Require().NoError(err)
+		assert.Equal(i.T(), tc.expectedIndex, len(newHits) == 1)
+		assert.Equal(i.T(), tc.expectedDelete, len(oldHits) == 0 && len(newHits) == 0)
+		i.Require().NoError(index.Close())
+	}
+}
+
+func TestIndexerTestSuite(t *testing.T) {
+	suite.Run(t, new(IndexerTestSuite))
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/repository"
+	"faceit/domain/user/utils"
+	"fmt"
+	"os"
+	"time"
+)
+
+// rebuildBatchSize - The number of the users read from the database and indexed at once
+const rebuildBatchSize = 1000
+
+// Rebuild - Builds the index in the directory from all the users in the database, replacing the index which is there.
+// The new index is built next to the old one and only replaces it once it's complete, so a failed rebuild keeps the old index.
+// Its position is the last settled event in the outbox before it's filled, so the users changed while it's filled are indexed again by the Follower.
+// The index can't be open in another process, e.g. the running service, as it's locked while open.
+func Rebuild(ctx context.Context, path string, users repository.IUsersRepository, outbox repository.IOutboxRepository, settle time.Duration) (int, error) {
+	building := path + ".rebuild"
+	if err := os.RemoveAll(building); err != nil {
+		return 0, fmt.Errorf("failed to remove the unfinished rebuild %s: %w", building, err)
+	}
+
+	syncedAt := time.Now()
+	eventID, err := outbox.GetLastSettledEventID(ctx, settle)
+	if err != nil {
+		return 0, err
+	}
+
+	index, err := create(building)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := Fill(ctx, index, users)
+	if err == nil {
+		err = index.SetPosition(&Position{EventID: eventID, SyncedAt: syncedAt})
+	}
+	if closeErr := index.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close the search index: %w", closeErr)
+	}
+	if err != nil {
+		_ = os.RemoveAll(building)
+		return 0, err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return 0, fmt.Errorf("failed to remove the old search index %s: %w", path, err)
+	}
+	if err := os.Rename(building, path); err != nil {
+		return 0, fmt.Errorf("failed to replace the search index %s: %w", path, err)
+	}
+
+	return count, nil
+}
+
+// Fill - Indexes all the users in the database in batches in the order of their IDs, and returns the number of them
+func Fill(ctx context.Context, index *Index, repository repository.IUsersRepository) (int, error) {
+	var cursor *entity.Cursor
+	count := 0
+	for {
+		users, err := repository.GetPage(ctx, &entity.Filter{}, entity.DefaultSort, cursor, rebuildBatchSize)
+		if err != nil {
+			return count, err
+		}
+
+		batch := index.index.NewBatch()
+		for _, user := range users {
+			if err := batch.Index(documentID(user.ID), document(user)); err != nil {
+				return count, fmt.Errorf("failed to index user %d: %w", user.ID, err)
+			}
+		}
+		if err := index.index.Batch(batch); err != nil {
+			return count, fmt.Errorf("failed to index the users: %w", err)
+		}
+
+		count += len(users)
+		if len(users) < rebuildBatchSize {
+			return count, nil
+		}
+		cursor = utils.CursorOf(users[len(users)-1], entity.DefaultSort, false)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	rbacEntity "faceit/domain/rbac/entity"
+	rbacService "faceit/domain/rbac/service"
+	"faceit/domain/user/dto"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/repository"
+	"faceit/domain/user/utils"
+)
+
+type IAuditService interface {
+	GetHistory(ctx context.Context, userID int64, page, pageSize int64) ([]*dto.AuditEntry, error)
+	Get(ctx context.Context, filter *dto.AuditFilter, page, pageSize int64) ([]*dto.AuditEntry, error)
+}
+
+// AuditService - Reads the changes made to the users, with who made them and where the requests came from
+type AuditService struct {
+	repository repository.IAuditRepository
+	authorizer rbacService.IAuthorizer
+}
+
+func NewAuditService(repository repository.IAuditRepository, authorizer rbacService.IAuthorizer) *AuditService {
+	return &AuditService{repository: repository, authorizer: authorizer}
+}
+
+// GetHistory - Returns a page of the changes made to the user, the latest first.
+// The history of the removed and the purged users is kept, so it's empty rather than missing for an unknown user.
+func (a *AuditService) GetHistory(ctx context.Context, userID int64, page, pageSize int64) ([]*dto.AuditEntry, error) {
+	return a.Get(ctx, &dto.AuditFilter{UserID: &userID}, page, pageSize)
+}
+
+// Get - Returns a page of the changes made to all the users with the filter, the latest first.
+// The values of the names and the emails in the changes are redacted without the users:read:pii permission.
+func (a *AuditService) Get(ctx context.Context, filter *dto.AuditFilter, page, pageSize int64) ([]*dto.AuditEntry, error) {
+	// the changes reveal the personal information of the users and the addresses of the actors
+	if err := a.authorizer.Authorize(ctx, rbacEntity.PermissionUsersAudit); err != nil {
+		return nil, err
+	}
+
+	canReadPII, err := a.authorizer.HasPermission(ctx, rbacEntity.PermissionUsersReadPII)
+	if err != nil {
+		return nil, err
+	}
+
+	entryEntities, err := a.repository.Get(ctx, entity.AuditFilterEntityFromDTO(filter), page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	entryDTOs := make([]*dto.AuditEntry, len(entryEntities))
+	for i, entryEntity := range entryEntities {
+		entryDTOs[i] = utils.AuditEntryDTOFromEntity(entryEntity)
+		if !canReadPII {
+			utils.RedactAuditPII(entryDTOs[i])
+		}
+	}
+
+	return entryDTOs, nil
+}
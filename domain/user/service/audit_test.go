@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"faceit/domain/constants"
+	rbacEntity "faceit/domain/rbac/entity"
+	"faceit/domain/user/dto"
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	rbacMocks "faceit/mocks/domain/rbac/service"
+	mocks "faceit/mocks/domain/user/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditServiceTestSuite struct {
+	suite.Suite
+}
+
+func (a *AuditServiceTestSuite) TestGetHistory() {
+	userID, actorID := int64(3), int64(7)
+	createdAt := time.Now()
+	changes := map[string]events.Change{
+		"first_name": {Before: "", After: "Mehran"},
+		"email":      {Before: "a@gmail.com", After: "b@gmail.com"},
+		"country":    {Before: "UK", After: "NL"},
+		"password":   {Before: events.Redacted, After: events.Redacted},
+	}
+	redactedChanges := map[string]events.Change{
+		"first_name": {Before: "", After: events.Redacted},
+		"email":      {Before: events.Redacted, After: events.Redacted},
+		"country":    {Before: "UK", After: "NL"},
+		"password":   {Before: events.Redacted, After: events.Redacted},
+	}
+	testCases := []struct {
+		authorizeError  error
+		canReadPII      bool
+		expectedEntries []*dto.AuditEntry
+		expectedError   error
+	}{
+		{
+			// admin
+			canReadPII:      true,
+			expectedEntries: []*dto.AuditEntry{{ID: 1, UserID: userID, Action: "user.updated", ActorID: &actorID, RequestID: "req-1", SourceIP: "203.0.113.7", Changes: changes, CreatedAt: createdAt}},
+		},
+		{
+			// support, which can read the audit log but not the PII of the users
+			expectedEntries: []*dto.AuditEntry{{ID: 1, UserID: userID, Action: "user.updated", ActorID: &actorID, RequestID: "req-1", SourceIP: "203.0.113.7", Changes: redactedChanges, CreatedAt: createdAt}},
+		},
+		// user
+		{authorizeError: constants.ErrForbidden, expectedError: constants.ErrForbidden},
+		{authorizeError: constants.ErrUnauthenticated, expectedError: constants.ErrUnauthenticated},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IAuditRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersAudit).Return(tc.authorizeError)
+		authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(tc.canReadPII, nil)
+		repositoryMock.On("Get", mock.Anything, &entity.AuditFilter{UserID: &userID}, int64(1), int64(20)).
+			Return([]*entity.AuditEntry{{ID: 1, UserID: userID, Action: "user.updated", ActorID: &actorID, RequestID: "req-1", SourceIP: "203.0.113.7", Changes: changes, CreatedAt: createdAt}}, nil)
+
+		entries, err := NewAuditService(&repositoryMock, &authorizerMock).GetHistory(context.Background(), userID, 1, 20)
+		assert.Equal(a.T(), tc.expectedError, err)
+		assert.Equal(a.T(), tc.expectedEntries, entries)
+		if tc.authorizeError != nil {
+			repositoryMock.AssertNotCalled(a.T(), "Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		}
+	}
+
+	// the changes of the entities are not redacted in place
+	assert.Equal(a.T(), "a@gmail.com", changes["email"].Before)
+}
+
+func (a *AuditServiceTestSuite) TestGet() {
+	actorID := int64(7)
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := &dto.AuditFilter{ActorID: &actorID, Actions: []string{"user.deleted"}, CreatedAt: dto.TimeRange{Gte: &from}}
+
+	repositoryMock := mocks.IAuditRepository{}
+	authorizerMock := rbacMocks.IAuthorizer{}
+	authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersAudit).Return(nil)
+	authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(true, nil)
+	repositoryMock.On("Get", mock.Anything, &entity.AuditFilter{ActorID: &actorID, Actions: []string{"user.deleted"}, CreatedAt: dto.TimeRange{Gte: &from}}, int64(2), int64(50)).
+		Return([]*entity.AuditEntry{}, nil).Once()
+
+	entries, err := NewAuditService(&repositoryMock, &authorizerMock).Get(context.Background(), filter, 2, 50)
+	a.Require().NoError(err)
+	assert.Empty(a.T(), entries)
+	repositoryMock.AssertExpectations(a.T())
+}
+
+func TestAuditServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditServiceTestSuite))
+}
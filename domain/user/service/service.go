@@ -4,51 +4,81 @@ import (
 	"context"
 	"errors"
 	"faceit/domain/constants"
+	rbacEntity "faceit/domain/rbac/entity"
+	rbacService "faceit/domain/rbac/service"
 	"faceit/domain/user/dto"
 	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
 	"faceit/domain/user/repository"
+	"faceit/domain/user/search"
 	"faceit/domain/user/utils"
+	"faceit/infrastructure/hasher"
+	"log"
+	"reflect"
 	"strings"
+	"sync"
 )
 
 type IUserService interface {
 	Create(ctx context.Context, user *dto.User, password string) (*dto.User, error)
 	Update(ctx context.Context, user *dto.User, password string) error
 	Remove(ctx context.Context, id int64) error
-	Get(ctx context.Context, filter *dto.Filter, page, pageSize int64) ([]*dto.User, uint64, error)
+	Restore(ctx context.Context, id int64) error
+	GetDeleted(ctx context.Context, page, pageSize int64) ([]*dto.User, uint64, error)
+	Get(ctx context.Context, filter *dto.Filter, sort []dto.SortKey, page, pageSize int64) ([]*dto.User, uint64, error)
+	GetPage(ctx context.Context, filter *dto.Filter, sort []dto.SortKey, cursor string, pageSize int64, withCount bool) (*dto.Page, error)
+	GetByID(ctx context.Context, id int64) (*dto.User, error)
+	GetByEmail(ctx context.Context, email string) (*dto.User, error)
+	GetByNickName(ctx context.Context, nickName string) (*dto.User, error)
+	GetByIDs(ctx context.Context, IDs []int64) ([]*dto.User, []int64, error)
+	Search(ctx context.Context, text string, limit int) (*dto.SearchResult, error)
+	Authenticate(ctx context.Context, login, password string) (*dto.User, error)
 }
 
+// MaxBatchIDs - The maximum number of distinct IDs which can be looked up at once
+const MaxBatchIDs = 1000
+
 type UserService struct {
 	repository repository.IUsersRepository
+	hasher     hasher.IHasher
+	authorizer rbacService.IAuthorizer
+	cursors    *utils.CursorCodec
+	index      search.IIndex
+	// dummyHash - Verified against the passwords of the unknown logins, so they take as long as the wrong passwords
+	dummyHash     string
+	dummyHashOnce sync.Once
 }
 
-func NewUserService(repository repository.IUsersRepository) *UserService {
-	return &UserService{repository: repository}
+func NewUserService(repository repository.IUsersRepository, hasher hasher.IHasher, authorizer rbacService.IAuthorizer, cursors *utils.CursorCodec, index search.IIndex) *UserService {
+	return &UserService{repository: repository, hasher: hasher, authorizer: authorizer, cursors: cursors, index: index}
 }
 
 func (u *UserService) Create(ctx context.Context, user *dto.User, password string) (*dto.User, error) {
+	if len(password) > hasher.MaxPasswordLength {
+		return nil, constants.ErrPasswordTooLong
+	}
+
 	// check for email and nickname uniqueness
-	foundUserEntity, err := u.repository.GetByEmail(ctx, user.Email)
-	if err != nil && !errors.Is(err, constants.ErrUserNotFound) {
+	if err := u.checkIdentities(ctx, 0, user.Email, user.NickName); err != nil {
 		return nil, err
 	}
-	if foundUserEntity != nil {
-		return nil, constants.ErrUserExists
-	}
 
-	foundUserEntity, err = u.repository.GetByNickName(ctx, user.NickName)
-	if err != nil && !errors.Is(err, constants.ErrUserNotFound) {
+	passwordHash, err := u.hasher.Hash(password)
+	if err != nil {
 		return nil, err
 	}
-	if foundUserEntity != nil {
-		return nil, constants.ErrUserExists
-	}
 
 	userEntity := utils.UserEntityFromDTO(user)
-	userEntity.Password = password
+	userEntity.Password = passwordHash
 	// convert the user's country to uppercase for consistency
 	userEntity.Country = strings.ToUpper(userEntity.Country)
-	createdUserEntity, err := u.repository.Create(ctx, userEntity)
+	// the ID of the user is set on the event by the repository once the user is stored
+	event, err := events.New(events.TypeUserCreated, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	createdUserEntity, err := u.repository.Create(ctx, userEntity, event)
 	if err != nil {
 		return nil, err
 	}
@@ -56,51 +86,262 @@ func (u *UserService) Create(ctx context.Context, user *dto.User, password strin
 	return utils.UserDTOFromEntity(createdUserEntity), nil
 }
 
+// Update - Updates the user with the given fields, it returns constants.ErrVersionConflict if the user has been changed since the given version.
+// Without a version the user is updated at the version read here, so the changes of another update made in between are not overwritten either.
 func (u *UserService) Update(ctx context.Context, user *dto.User, password string) error {
-	// check if the user exists
+	if err := u.authorizer.AuthorizeUser(ctx, user.ID, rbacEntity.PermissionUsersUpdate); err != nil {
+		return err
+	}
+
+	if len(password) > hasher.MaxPasswordLength {
+		return constants.ErrPasswordTooLong
+	}
+
+	// check if the user exists, and it's still at the version the changes are based on
 	foundUserEntity, err := u.repository.GetByID(ctx, user.ID)
 	if err != nil {
 		return err
 	}
 
-	// check if there are any changes, if not return error
-	var hasChanges bool
-	if user.FirstName != "" && user.FirstName != foundUserEntity.FirstName {
-		hasChanges = true
+	if user.Version != 0 && user.Version != foundUserEntity.Version {
+		return constants.ErrVersionConflict
 	}
-	if user.LastName != "" && user.LastName != foundUserEntity.LastName {
-		hasChanges = true
+
+	// the password and the email let whoever sets them log in as the user, they can't be changed by someone less privileged
+	if password != "" || (user.Email != "" && user.Email != foundUserEntity.Email) {
+		if err := u.authorizer.AuthorizeCredentials(ctx, user.ID); err != nil {
+			return err
+		}
 	}
-	if user.NickName != "" && user.NickName != foundUserEntity.NickName {
-		hasChanges = true
+
+	// the stored password is hashed, so the new one can only be compared by verifying it against the hash
+	var passwordHash string
+	if password != "" {
+		samePassword, err := u.verifyPassword(ctx, foundUserEntity, password)
+		if err != nil {
+			return err
+		}
+
+		if !samePassword {
+			passwordHash, err = u.hasher.Hash(password)
+			if err != nil {
+				return err
+			}
+		}
 	}
-	if user.Email != "" && user.Email != foundUserEntity.Email {
-		hasChanges = true
+
+	userEntity := utils.UserEntityFromDTO(user)
+	userEntity.Password = passwordHash
+	// the changes are found against the read user, so it's only updated if nobody has changed it since
+	userEntity.Version = foundUserEntity.Version
+
+	// check if there are any changes, if not return error
+	changes := utils.UserChanges(foundUserEntity, userEntity)
+	if len(changes) == 0 {
+		return constants.ErrHasNoChanges
 	}
-	if user.Country != "" && user.Country != foundUserEntity.Country {
-		hasChanges = true
+
+	// the new email and nickname must not belong to another user
+	var email, nickName string
+	if _, ok := changes["email"]; ok {
+		email = userEntity.Email
 	}
-	if password != "" && password != foundUserEntity.Password {
-		hasChanges = true
+	if _, ok := changes["nick_name"]; ok {
+		nickName = userEntity.NickName
 	}
-	if !hasChanges {
-		return constants.ErrHasNoChanges
+	if err := u.checkIdentities(ctx, user.ID, email, nickName); err != nil {
+		return err
 	}
 
-	userEntity := utils.UserEntityFromDTO(user)
-	userEntity.Password = password
-	err = u.repository.Update(ctx, userEntity)
+	event, err := events.New(events.TypeUserUpdated, user.ID, changes)
+	if err != nil {
+		return err
+	}
+
+	err = u.repository.Update(ctx, userEntity, event)
 	return err
 }
 
+// checkIdentities - Returns constants.ErrEmailExists or constants.ErrNickNameExists if a user other than the given ID has the email or the nickname,
+// the empty ones are not checked. The concurrent requests can still take them after the check, so the repository reports the violations of the unique keys as well.
+func (u *UserService) checkIdentities(ctx context.Context, ID int64, email, nickName string) error {
+	if email != "" {
+		foundUserEntity, err := u.repository.GetByEmail(ctx, email)
+		if err != nil && !errors.Is(err, constants.ErrUserNotFound) {
+			return err
+		}
+		if foundUserEntity != nil && foundUserEntity.ID != ID {
+			return constants.ErrEmailExists
+		}
+	}
+
+	if nickName != "" {
+		foundUserEntity, err := u.repository.GetByNickName(ctx, nickName)
+		if err != nil && !errors.Is(err, constants.ErrUserNotFound) {
+			return err
+		}
+		if foundUserEntity != nil && foundUserEntity.ID != ID {
+			return constants.ErrNickNameExists
+		}
+	}
+
+	return nil
+}
+
+// Authenticate - Finds the user by email or nickname and verifies the password.
+// It returns the same error whether the user doesn't exist or the password is wrong, so the users can't be enumerated.
+func (u *UserService) Authenticate(ctx context.Context, login, password string) (*dto.User, error) {
+	var userEntity *entity.User
+	var err error
+	if strings.Contains(login, "@") {
+		userEntity, err = u.repository.GetByEmail(ctx, login)
+	} else {
+		userEntity, err = u.repository.GetByNickName(ctx, login)
+	}
+	if errors.Is(err, constants.ErrUserNotFound) {
+		u.verifyDummy(password)
+		return nil, constants.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := u.verifyPassword(ctx, userEntity, password)
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return nil, constants.ErrInvalidCredentials
+	}
+
+	return utils.UserDTOFromEntity(userEntity), nil
+}
+
+// verifyDummy - Verifies the password against a hash of the configured algorithm and ignores the result,
+// so an unknown login can't be told apart from a wrong password by the time it takes
+func (u *UserService) verifyDummy(password string) {
+	u.dummyHashOnce.Do(func() {
+		dummyHash, err := u.hasher.Hash("dummy-password")
+		if err != nil {
+			log.Printf("failed to create the dummy password hash: %s", err)
+			return
+		}
+		u.dummyHash = dummyHash
+	})
+
+	if u.dummyHash != "" {
+		_, _, _ = u.hasher.Verify(password, u.dummyHash)
+	}
+}
+
+// verifyPassword - Checks the password against the user's stored hash.
+// When the hash was produced with an outdated algorithm or cost parameters, it is transparently replaced with a new one.
+func (u *UserService) verifyPassword(ctx context.Context, userEntity *entity.User, password string) (bool, error) {
+	match, needsRehash, err := u.hasher.Verify(password, userEntity.Password)
+	if err != nil {
+		return false, err
+	}
+
+	if match && needsRehash {
+		// failing to upgrade the hash must not fail the verification, it will be retried on the next one
+		passwordHash, err := u.hasher.Hash(password)
+		if err != nil {
+			log.Printf("failed to rehash the password of user %d: %s", userEntity.ID, err)
+			return match, nil
+		}
+
+		if err := u.repository.UpdatePassword(ctx, userEntity.ID, userEntity.Version, passwordHash); err != nil {
+			log.Printf("failed to rehash the password of user %d: %s", userEntity.ID, err)
+			return match, nil
+		}
+		userEntity.Password = passwordHash
+	}
+
+	return match, nil
+}
+
 func (u *UserService) Remove(ctx context.Context, id int64) error {
-	err := u.repository.Remove(ctx, id)
+	if err := u.authorizer.AuthorizeUser(ctx, id, rbacEntity.PermissionUsersDelete); err != nil {
+		return err
+	}
+
+	event, err := events.New(events.TypeUserDeleted, id, nil)
+	if err != nil {
+		return err
+	}
+
+	err = u.repository.Remove(ctx, id, event)
 	return err
 }
 
-func (u *UserService) Get(ctx context.Context, filter *dto.Filter, page, pageSize int64) ([]*dto.User, uint64, error) {
+// Restore - Brings back a removed user which is not purged yet.
+// The email and the nickname of the user may be taken by another user since it was removed, then it can't be restored.
+func (u *UserService) Restore(ctx context.Context, id int64) error {
+	if err := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRestore); err != nil {
+		return err
+	}
+
+	event, err := events.New(events.TypeUserRestored, id, nil)
+	if err != nil {
+		return err
+	}
+
+	return u.repository.Restore(ctx, id, event)
+}
+
+// GetDeleted - Returns the removed users which are not purged yet, the most recently removed first, with the total count of them
+func (u *UserService) GetDeleted(ctx context.Context, page, pageSize int64) ([]*dto.User, uint64, error) {
+	if err := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRestore); err != nil {
+		return nil, 0, err
+	}
+
+	canReadPII, err := u.authorizer.HasPermission(ctx, rbacEntity.PermissionUsersReadPII)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userEntities, err := u.repository.GetDeleted(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userDTOs := make([]*dto.User, len(userEntities))
+	for i, userEntity := range userEntities {
+		userDTOs[i] = utils.UserDTOFromEntity(userEntity)
+		if !canReadPII {
+			utils.RedactPII(userDTOs[i])
+		}
+	}
+
+	count, err := u.repository.GetDeletedCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return userDTOs, count, nil
+}
+
+func (u *UserService) Get(ctx context.Context, filter *dto.Filter, sort []dto.SortKey, page, pageSize int64) ([]*dto.User, uint64, error) {
+	// listing the users exposes everyone's record
+	if err := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRead); err != nil {
+		return nil, 0, err
+	}
+
+	sortEntity, err := utils.NormalizeSort(entity.SortEntityFromDTO(sort))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	canReadPII, err := u.authorizer.HasPermission(ctx, rbacEntity.PermissionUsersReadPII)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter.HasPII() && !canReadPII {
+		return nil, 0, constants.ErrForbidden
+	}
+
 	filterEntity := entity.FilterEntityFromDTO(filter)
-	userEntities, err := u.repository.Get(ctx, filterEntity, page, pageSize)
+	userEntities, err := u.repository.Get(ctx, filterEntity, sortEntity, page, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -109,6 +350,9 @@ func (u *UserService) Get(ctx context.Context, filter *dto.Filter, page, pageSiz
 
 	for i, userEntity := range userEntities {
 		userDTOs[i] = utils.UserDTOFromEntity(userEntity)
+		if !canReadPII {
+			utils.RedactPII(userDTOs[i])
+		}
 	}
 
 	// get the total count of the users for pagination
@@ -119,3 +363,267 @@ func (u *UserService) Get(ctx context.Context, filter *dto.Filter, page, pageSiz
 
 	return userDTOs, count, nil
 }
+
+// GetPage - Returns the page of the users right after or before the cursor, or the first page without a cursor.
+// Unlike the pages of Get, the pages don't skip or repeat users when the users change in between, and deep pages are as fast as the first one.
+// The total count of the users is only read when it's asked for.
+func (u *UserService) GetPage(ctx context.Context, filter *dto.Filter, sort []dto.SortKey, cursor string, pageSize int64, withCount bool) (*dto.Page, error) {
+	if err := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	canReadPII, err := u.authorizer.HasPermission(ctx, rbacEntity.PermissionUsersReadPII)
+	if err != nil {
+		return nil, err
+	}
+	// the users found by their PII tell it as much as reading it does
+	if filter.HasPII() && !canReadPII {
+		return nil, constants.ErrForbidden
+	}
+
+	sortEntity, err := utils.NormalizeSort(entity.SortEntityFromDTO(sort))
+	if err != nil {
+		return nil, err
+	}
+
+	var position *entity.Cursor
+	if cursor != "" {
+		position, err = u.cursors.Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(position.Sort, sortEntity) {
+			return nil, constants.ErrInvalidCursor
+		}
+	}
+	backward := position != nil && position.Backward
+
+	// one more user tells if there is another page in the direction of the cursor
+	filterEntity := entity.FilterEntityFromDTO(filter)
+	userEntities, err := u.repository.GetPage(ctx, filterEntity, sortEntity, position, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := int64(len(userEntities)) > pageSize
+	if hasMore && backward {
+		userEntities = userEntities[1:]
+	} else if hasMore {
+		userEntities = userEntities[:pageSize]
+	}
+
+	page := &dto.Page{Users: make([]*dto.User, len(userEntities))}
+	for i, userEntity := range userEntities {
+		page.Users[i] = utils.UserDTOFromEntity(userEntity)
+		if !canReadPII {
+			utils.RedactPII(page.Users[i])
+		}
+	}
+
+	// a page reached backward always has a next page, the one it was reached from, and the other way around
+	hasNext := hasMore || backward
+	hasPrev := hasMore && backward || position != nil && !backward
+	if len(userEntities) > 0 {
+		if hasNext {
+			if page.NextCursor, err = u.cursors.Encode(utils.CursorOf(userEntities[len(userEntities)-1], sortEntity, false)); err != nil {
+				return nil, err
+			}
+		}
+		if hasPrev {
+			if page.PrevCursor, err = u.cursors.Encode(utils.CursorOf(userEntities[0], sortEntity, true)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if withCount {
+		count, err := u.repository.GetCount(ctx, filterEntity)
+		if err != nil {
+			return nil, err
+		}
+		page.Count = &count
+	}
+
+	return page, nil
+}
+
+// GetByID - Returns the user with the given ID. Users can always see their own record,
+// the other users need the users:read permission and their PII is removed without the users:read:pii permission.
+func (u *UserService) GetByID(ctx context.Context, id int64) (*dto.User, error) {
+	if err := u.authorizer.AuthorizeUser(ctx, id, rbacEntity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	userEntity, err := u.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.visibleUser(ctx, userEntity)
+}
+
+// GetByEmail - Gets the user with the exact email. Looking up the email of another user needs the users:read:pii permission,
+// and without it a missing user is reported as forbidden as well, so the lookups can't tell which emails are registered.
+func (u *UserService) GetByEmail(ctx context.Context, email string) (*dto.User, error) {
+	userEntity, err := u.repository.GetByEmail(ctx, email)
+	if errors.Is(err, constants.ErrUserNotFound) {
+		if authErr := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersReadPII); authErr != nil {
+			return nil, authErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizer.AuthorizeUser(ctx, userEntity.ID, rbacEntity.PermissionUsersReadPII); err != nil {
+		return nil, err
+	}
+
+	return utils.UserDTOFromEntity(userEntity), nil
+}
+
+// GetByNickName - Gets the user with the exact nickname, it needs the same permissions as getting the user by ID.
+// A missing user is reported as forbidden to the principals who can't read the other users.
+func (u *UserService) GetByNickName(ctx context.Context, nickName string) (*dto.User, error) {
+	userEntity, err := u.repository.GetByNickName(ctx, nickName)
+	if errors.Is(err, constants.ErrUserNotFound) {
+		if authErr := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRead); authErr != nil {
+			return nil, authErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.authorizer.AuthorizeUser(ctx, userEntity.ID, rbacEntity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	return u.visibleUser(ctx, userEntity)
+}
+
+// GetByIDs - Gets the users with the given IDs in the order of the IDs, a repeated ID is only returned once.
+// The IDs which don't belong to any user are returned as missing.
+func (u *UserService) GetByIDs(ctx context.Context, IDs []int64) ([]*dto.User, []int64, error) {
+	if err := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRead); err != nil {
+		return nil, nil, err
+	}
+
+	uniqueIDs := make([]int64, 0, len(IDs))
+	seen := make(map[int64]bool, len(IDs))
+	for _, ID := range IDs {
+		if !seen[ID] {
+			seen[ID] = true
+			uniqueIDs = append(uniqueIDs, ID)
+		}
+	}
+
+	if len(uniqueIDs) > MaxBatchIDs {
+		return nil, nil, constants.ErrTooManyIDs
+	}
+
+	canReadPII, err := u.authorizer.HasPermission(ctx, rbacEntity.PermissionUsersReadPII)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userEntities, err := u.repository.GetByIDs(ctx, uniqueIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make(map[int64]*entity.User, len(userEntities))
+	for _, userEntity := range userEntities {
+		found[userEntity.ID] = userEntity
+	}
+
+	userDTOs := make([]*dto.User, 0, len(userEntities))
+	missingIDs := make([]int64, 0)
+	for _, ID := range uniqueIDs {
+		userEntity, ok := found[ID]
+		if !ok {
+			missingIDs = append(missingIDs, ID)
+			continue
+		}
+
+		userDTO := utils.UserDTOFromEntity(userEntity)
+		if !canReadPII {
+			utils.RedactPII(userDTO)
+		}
+		userDTOs = append(userDTOs, userDTO)
+	}
+
+	return userDTOs, missingIDs, nil
+}
+
+// Search - Returns up to limit users best matching the text, the best first, and the total number of the matching users.
+// The names and the email are only searched with the users:read:pii permission, as the matches reveal them.
+// The users come from the database, the ones removed since they were indexed are skipped.
+func (u *UserService) Search(ctx context.Context, text string, limit int) (*dto.SearchResult, error) {
+	if err := u.authorizer.Authorize(ctx, rbacEntity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	canReadPII, err := u.authorizer.HasPermission(ctx, rbacEntity.PermissionUsersReadPII)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := search.PublicFields
+	if canReadPII {
+		fields = search.AllFields
+	}
+
+	hits, total, err := u.index.Search(text, fields, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.SearchResult{Hits: make([]*dto.SearchHit, 0, len(hits)), Total: total}
+	if len(hits) == 0 {
+		return result, nil
+	}
+
+	IDs := make([]int64, len(hits))
+	for i, hit := range hits {
+		IDs[i] = hit.ID
+	}
+
+	userEntities, err := u.repository.GetByIDs(ctx, IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int64]*entity.User, len(userEntities))
+	for _, userEntity := range userEntities {
+		found[userEntity.ID] = userEntity
+	}
+
+	for _, hit := range hits {
+		userEntity, ok := found[hit.ID]
+		if !ok {
+			continue
+		}
+
+		userDTO := utils.UserDTOFromEntity(userEntity)
+		if !canReadPII {
+			utils.RedactPII(userDTO)
+		}
+		result.Hits = append(result.Hits, &dto.SearchHit{User: userDTO, Score: hit.Score})
+	}
+
+	return result, nil
+}
+
+// visibleUser - Returns the user without the personal information, unless the principal is the user or has the users:read:pii permission
+func (u *UserService) visibleUser(ctx context.Context, userEntity *entity.User) (*dto.User, error) {
+	userDTO := utils.UserDTOFromEntity(userEntity)
+	if err := u.authorizer.AuthorizeUser(ctx, userEntity.ID, rbacEntity.PermissionUsersReadPII); err != nil {
+		if !errors.Is(err, constants.ErrForbidden) {
+			return nil, err
+		}
+		utils.RedactPII(userDTO)
+	}
+
+	return userDTO, nil
+}
@@ -3,10 +3,19 @@ package service
 import (
 	"context"
 	"faceit/domain/constants"
+	rbacEntity "faceit/domain/rbac/entity"
 	"faceit/domain/user/dto"
 	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	"faceit/domain/user/search"
+	"faceit/domain/user/utils"
+	rbacMocks "faceit/mocks/domain/rbac/service"
 	mocks "faceit/mocks/domain/user/repository"
+	searchMocks "faceit/mocks/domain/user/search"
+	hasherMocks "faceit/mocks/infrastructure/hasher"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,11 +26,16 @@ type ServiceTestSuite struct {
 	suite.Suite
 }
 
+var testCursors = utils.NewCursorCodec([]byte("secret"))
+
 func (s *ServiceTestSuite) TestCreate() {
 	testCases := []struct {
 		userEntity         *entity.User
 		userDTO            *dto.User
 		password           string
+		emailOwner         *entity.User
+		nickNameOwner      *entity.User
+		createError        error
 		expectedUserEntity *entity.User
 		expectedUserDTO    *dto.User
 		expectedError      error
@@ -31,7 +45,7 @@ func (s *ServiceTestSuite) TestCreate() {
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
-				Password:  "pass",
+				Password:  "hashed-pass",
 				Email:     "test@gmail.com",
 				Country:   "UK",
 			},
@@ -48,7 +62,7 @@ func (s *ServiceTestSuite) TestCreate() {
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
-				Password:  "pass",
+				Password:  "hashed-pass",
 				Email:     "test@gmail.com",
 				Country:   "UK",
 			},
@@ -62,28 +76,76 @@ func (s *ServiceTestSuite) TestCreate() {
 			},
 			expectedError: nil,
 		},
+		{
+			userEntity:    &entity.User{NickName: "test", Password: "hashed-pass", Email: "test@gmail.com"},
+			userDTO:       &dto.User{NickName: "test", Email: "test@gmail.com"},
+			password:      "pass",
+			emailOwner:    &entity.User{ID: 2},
+			expectedError: constants.ErrEmailExists,
+		},
+		{
+			userEntity:    &entity.User{NickName: "test", Password: "hashed-pass", Email: "test@gmail.com"},
+			userDTO:       &dto.User{NickName: "test", Email: "test@gmail.com"},
+			password:      "pass",
+			nickNameOwner: &entity.User{ID: 2},
+			expectedError: constants.ErrNickNameExists,
+		},
+		{
+			// the nickname is taken by a concurrent request after it's checked
+			userEntity:    &entity.User{NickName: "test", Password: "hashed-pass", Email: "test@gmail.com"},
+			userDTO:       &dto.User{NickName: "test", Email: "test@gmail.com"},
+			password:      "pass",
+			createError:   constants.ErrNickNameExists,
+			expectedError: constants.ErrNickNameExists,
+		},
+		{
+			// bcrypt only uses the first 72 bytes of the password
+			userEntity:    &entity.User{NickName: "test", Email: "test@gmail.com"},
+			userDTO:       &dto.User{NickName: "test", Email: "test@gmail.com"},
+			password:      strings.Repeat("p", 73),
+			expectedError: constants.ErrPasswordTooLong,
+		},
 	}
 
-	repositoryMock := mocks.IUsersRepository{}
 	for _, tc := range testCases {
-		repositoryMock.On("Create", mock.Anything, tc.userEntity).Return(tc.expectedUserEntity, nil)
-		repositoryMock.On("GetByEmail", mock.Anything, tc.userEntity.Email).Return(nil, constants.ErrUserNotFound)
-		repositoryMock.On("GetByNickName", mock.Anything, tc.userEntity.NickName).Return(nil, constants.ErrUserNotFound)
+		repositoryMock := mocks.IUsersRepository{}
+		hasherMock := hasherMocks.IHasher{}
+		repositoryMock.On("Create", mock.Anything, tc.userEntity, mock.MatchedBy(func(event *events.Event) bool {
+			return event.Type == events.TypeUserCreated && event.ID != ""
+		})).Return(tc.expectedUserEntity, tc.createError)
+		repositoryMock.On("GetByEmail", mock.Anything, tc.userEntity.Email).Return(ownerOrNotFound(tc.emailOwner))
+		repositoryMock.On("GetByNickName", mock.Anything, tc.userEntity.NickName).Return(ownerOrNotFound(tc.nickNameOwner))
+		hasherMock.On("Hash", tc.password).Return(tc.userEntity.Password, nil)
 
-		userService := NewUserService(&repositoryMock)
+		userService := NewUserService(&repositoryMock, &hasherMock, &rbacMocks.IAuthorizer{}, testCursors, nil)
 		userDTO, err := userService.Create(context.Background(), tc.userDTO, tc.password)
 		assert.Equal(s.T(), tc.expectedError, err)
 		assert.Equal(s.T(), tc.expectedUserDTO, userDTO)
 	}
 }
 
+// ownerOrNotFound - Returns what the repository returns for a user looked up by its email or nickname
+func ownerOrNotFound(owner *entity.User) (*entity.User, error) {
+	if owner == nil {
+		return nil, constants.ErrUserNotFound
+	}
+	return owner, nil
+}
+
 func (s *ServiceTestSuite) TestUpdate() {
 	testCases := []struct {
-		userEntity         *entity.User
-		userDTO            *dto.User
-		password           string
-		expectedUserEntity *entity.User
-		expectedError      error
+		userEntity          *entity.User
+		userDTO             *dto.User
+		password            string
+		samePassword        bool
+		passwordNeedsRehash bool
+		authorizeError      error
+		credentialsError    error
+		emailOwner          *entity.User
+		nickNameOwner       *entity.User
+		expectedUserEntity  *entity.User
+		expectedChanges     map[string]events.Change
+		expectedError       error
 	}{
 		{
 			userEntity: &entity.User{
@@ -91,9 +153,9 @@ func (s *ServiceTestSuite) TestUpdate() {
 				FirstName: "test2",
 				LastName:  "test",
 				NickName:  "test",
-				Password:  "pass",
 				Email:     "test@gmail.com",
 				Country:   "UK",
+				Version:   5,
 			},
 			userDTO: &dto.User{
 				ID:        1,
@@ -102,59 +164,763 @@ func (s *ServiceTestSuite) TestUpdate() {
 				NickName:  "test",
 				Email:     "test@gmail.com",
 				Country:   "UK",
+				Version:   5,
 			},
-			password: "pass",
+			password:     "pass",
+			samePassword: true,
 			expectedUserEntity: &entity.User{
 				ID:        1,
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
-				Password:  "pass",
+				Password:  "hashed-pass",
 				Email:     "test@gmail.com",
 				Country:   "UK",
+				Version:   5,
+			},
+			expectedChanges: map[string]events.Change{
+				"first_name": {Before: "test", After: "test2"},
+			},
+			expectedError: nil,
+		},
+		{
+			// without a version the user is updated at the version it's read at
+			userEntity: &entity.User{
+				ID:       2,
+				Password: "hashed-new-pass",
+				Version:  2,
+			},
+			userDTO: &dto.User{
+				ID: 2,
+			},
+			password:     "new-pass",
+			samePassword: false,
+			expectedUserEntity: &entity.User{
+				ID:       2,
+				Password: "hashed-pass",
+				Version:  2,
+			},
+			expectedChanges: map[string]events.Change{
+				"password": {Before: events.Redacted, After: events.Redacted},
 			},
 			expectedError: nil,
 		},
+		{
+			userEntity: &entity.User{
+				ID: 3,
+			},
+			userDTO: &dto.User{
+				ID: 3,
+			},
+			password:            "pass",
+			samePassword:        true,
+			passwordNeedsRehash: true,
+			expectedUserEntity: &entity.User{
+				ID:       3,
+				Password: "hashed-pass",
+				Version:  4,
+			},
+			expectedError: constants.ErrHasNoChanges,
+		},
+		{
+			// changed since the given version
+			userEntity: &entity.User{
+				ID:        5,
+				FirstName: "test2",
+			},
+			userDTO: &dto.User{
+				ID:        5,
+				FirstName: "test2",
+				Version:   2,
+			},
+			expectedUserEntity: &entity.User{
+				ID:        5,
+				FirstName: "test",
+				Version:   3,
+			},
+			expectedError: constants.ErrVersionConflict,
+		},
+		{
+			// the new email belongs to another user
+			userEntity: &entity.User{
+				ID:    6,
+				Email: "taken@gmail.com",
+			},
+			userDTO: &dto.User{
+				ID:    6,
+				Email: "taken@gmail.com",
+			},
+			emailOwner: &entity.User{ID: 9},
+			expectedUserEntity: &entity.User{
+				ID:    6,
+				Email: "old@gmail.com",
+			},
+			expectedError: constants.ErrEmailExists,
+		},
+		{
+			// the nickname only changes its case, the user itself is found by it
+			userEntity: &entity.User{
+				ID:       7,
+				NickName: "Mine",
+			},
+			userDTO: &dto.User{
+				ID:       7,
+				NickName: "Mine",
+			},
+			nickNameOwner: &entity.User{ID: 7},
+			expectedUserEntity: &entity.User{
+				ID:       7,
+				NickName: "mine",
+			},
+			expectedChanges: map[string]events.Change{
+				"nick_name": {Before: "mine", After: "Mine"},
+			},
+			expectedError: nil,
+		},
+		{
+			// the caller doesn't have every role of the user
+			userEntity: &entity.User{
+				ID: 8,
+			},
+			userDTO: &dto.User{
+				ID: 8,
+			},
+			password:         "taken-over",
+			credentialsError: constants.ErrForbidden,
+			expectedUserEntity: &entity.User{
+				ID:       8,
+				Password: "hashed-pass",
+			},
+			expectedError: constants.ErrForbidden,
+		},
+		{
+			userEntity: &entity.User{
+				ID:        4,
+				FirstName: "test2",
+			},
+			userDTO: &dto.User{
+				ID:        4,
+				FirstName: "test2",
+			},
+			authorizeError: constants.ErrForbidden,
+			expectedUserEntity: &entity.User{
+				ID:        4,
+				FirstName: "test",
+			},
+			expectedError: constants.ErrForbidden,
+		},
+		{
+			userEntity: &entity.User{
+				ID:        1,
+				FirstName: "test2",
+			},
+			userDTO: &dto.User{
+				ID:        1,
+				FirstName: "test2",
+			},
+			authorizeError: constants.ErrUnauthenticated,
+			expectedUserEntity: &entity.User{
+				ID:        1,
+				FirstName: "test",
+			},
+			expectedError: constants.ErrUnauthenticated,
+		},
+		{
+			// the password is limited in bytes, not in letters
+			userEntity: &entity.User{
+				ID: 10,
+			},
+			userDTO: &dto.User{
+				ID: 10,
+			},
+			password: strings.Repeat("é", 37),
+			expectedUserEntity: &entity.User{
+				ID:       10,
+				Password: "hashed-pass",
+			},
+			expectedError: constants.ErrPasswordTooLong,
+		},
 	}
 
 	repositoryMock := mocks.IUsersRepository{}
+	hasherMock := hasherMocks.IHasher{}
+	authorizerMock := rbacMocks.IAuthorizer{}
 	for _, tc := range testCases {
-		repositoryMock.On("Update", mock.Anything, tc.userEntity).Return(nil)
+		authorizerMock.On("AuthorizeUser", mock.Anything, tc.userDTO.ID, rbacEntity.PermissionUsersUpdate).Return(tc.authorizeError).Once()
+		authorizerMock.On("AuthorizeCredentials", mock.Anything, tc.userDTO.ID).Return(tc.credentialsError).Once()
+		userID, expectedChanges := tc.userEntity.ID, tc.expectedChanges
+		repositoryMock.On("Update", mock.Anything, tc.userEntity, mock.MatchedBy(func(event *events.Event) bool {
+			return event.Type == events.TypeUserUpdated && event.UserID == userID && assert.ObjectsAreEqual(expectedChanges, event.Changes)
+		})).Return(nil).Once()
 		repositoryMock.On("GetByID", mock.Anything, tc.userEntity.ID).Return(tc.expectedUserEntity, nil)
+		hasherMock.On("Verify", tc.password, "hashed-pass").Return(tc.samePassword, tc.passwordNeedsRehash, nil).Once()
+		hasherMock.On("Hash", tc.password).Return("hashed-"+tc.password, nil).Once()
+		if tc.emailOwner != nil {
+			repositoryMock.On("GetByEmail", mock.Anything, tc.userDTO.Email).Return(tc.emailOwner, nil).Once()
+		}
+		if tc.nickNameOwner != nil {
+			repositoryMock.On("GetByNickName", mock.Anything, tc.userDTO.NickName).Return(tc.nickNameOwner, nil).Once()
+		}
+		if tc.passwordNeedsRehash {
+			repositoryMock.On("UpdatePassword", mock.Anything, tc.userEntity.ID, tc.expectedUserEntity.Version, "hashed-"+tc.password).Return(nil).Once()
+		}
 
-		userService := NewUserService(&repositoryMock)
+		userService := NewUserService(&repositoryMock, &hasherMock, &authorizerMock, testCursors, nil)
 		err := userService.Update(context.Background(), tc.userDTO, tc.password)
 		assert.Equal(s.T(), tc.expectedError, err)
 	}
+	authorizerMock.AssertNumberOfCalls(s.T(), "AuthorizeCredentials", 5)
+	repositoryMock.AssertNumberOfCalls(s.T(), "UpdatePassword", 1)
+	repositoryMock.AssertNumberOfCalls(s.T(), "Update", 3)
+	repositoryMock.AssertNumberOfCalls(s.T(), "GetByEmail", 1)
+	repositoryMock.AssertNumberOfCalls(s.T(), "GetByNickName", 1)
 }
 
 func (s *ServiceTestSuite) TestRemove() {
 	testCases := []struct {
-		id            int64
-		expectedError error
+		id             int64
+		authorizeError error
+		expectedError  error
 	}{
 		{
-			id:            1,
-			expectedError: nil,
+			id:             1,
+			authorizeError: nil,
+			expectedError:  nil,
+		},
+		{
+			id:             2,
+			authorizeError: constants.ErrForbidden,
+			expectedError:  constants.ErrForbidden,
+		},
+		{
+			id:             3,
+			authorizeError: constants.ErrUnauthenticated,
+			expectedError:  constants.ErrUnauthenticated,
 		},
 	}
 
 	repositoryMock := mocks.IUsersRepository{}
+	authorizerMock := rbacMocks.IAuthorizer{}
 	for _, tc := range testCases {
-		repositoryMock.On("Remove", mock.Anything, tc.id).Return(nil)
+		authorizerMock.On("AuthorizeUser", mock.Anything, tc.id, rbacEntity.PermissionUsersDelete).Return(tc.authorizeError)
+		id := tc.id
+		repositoryMock.On("Remove", mock.Anything, tc.id, mock.MatchedBy(func(event *events.Event) bool {
+			return event.Type == events.TypeUserDeleted && event.UserID == id
+		})).Return(nil)
 
-		userService := NewUserService(&repositoryMock)
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
 		err := userService.Remove(context.Background(), tc.id)
 		assert.Equal(s.T(), tc.expectedError, err)
 	}
 }
 
+func (s *ServiceTestSuite) TestRestore() {
+	testCases := []struct {
+		id              int64
+		authorizeError  error
+		repositoryError error
+		expectedError   error
+	}{
+		{id: 1},
+		{id: 2, authorizeError: constants.ErrForbidden, expectedError: constants.ErrForbidden},
+		{id: 3, repositoryError: constants.ErrUserExists, expectedError: constants.ErrUserExists},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRestore).Return(tc.authorizeError)
+		id := tc.id
+		repositoryMock.On("Restore", mock.Anything, tc.id, mock.MatchedBy(func(event *events.Event) bool {
+			return event.Type == events.TypeUserRestored && event.UserID == id
+		})).Return(tc.repositoryError)
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		err := userService.Restore(context.Background(), tc.id)
+		assert.Equal(s.T(), tc.expectedError, err)
+		if tc.authorizeError != nil {
+			repositoryMock.AssertNotCalled(s.T(), "Restore", mock.Anything, mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func (s *ServiceTestSuite) TestGetDeleted() {
+	deletedAt := time.Now()
+	userEntity := &entity.User{ID: 1, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", DeletedAt: &deletedAt}
+	testCases := []struct {
+		authorizeError   error
+		readPIIError     error
+		expectedUserDTOs []*dto.User
+		expectedCount    uint64
+		expectedError    error
+	}{
+		{
+			expectedUserDTOs: []*dto.User{{ID: 1, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK", DeletedAt: &deletedAt}},
+			expectedCount:    1,
+		},
+		{
+			readPIIError:     constants.ErrForbidden,
+			expectedUserDTOs: []*dto.User{{ID: 1, NickName: "test", Country: "UK", DeletedAt: &deletedAt}},
+			expectedCount:    1,
+		},
+		{authorizeError: constants.ErrForbidden, expectedError: constants.ErrForbidden},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRestore).Return(tc.authorizeError)
+		authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(tc.readPIIError == nil, nil)
+		repositoryMock.On("GetDeleted", mock.Anything, int64(1), int64(10)).Return([]*entity.User{userEntity}, nil)
+		repositoryMock.On("GetDeletedCount", mock.Anything).Return(uint64(1), nil)
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		userDTOs, count, err := userService.GetDeleted(context.Background(), 1, 10)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedUserDTOs, userDTOs)
+		assert.Equal(s.T(), tc.expectedCount, count)
+	}
+}
+
+func (s *ServiceTestSuite) TestGetByID() {
+	userEntity := &entity.User{ID: 2, FirstName: "test", LastName: "test", NickName: "test", Password: "hashed-pass", Email: "test@gmail.com", Country: "UK"}
+	testCases := []struct {
+		id              int64
+		authorizeError  error
+		readPIIError    error
+		repositoryError error
+		expectedUserDTO *dto.User
+		expectedError   error
+	}{
+		{
+			id:              2,
+			expectedUserDTO: &dto.User{ID: 2, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK"},
+		},
+		{
+			// the names and the email are removed without the users:read:pii permission
+			id:              2,
+			readPIIError:    constants.ErrForbidden,
+			expectedUserDTO: &dto.User{ID: 2, NickName: "test", Country: "UK"},
+		},
+		{
+			id:             2,
+			authorizeError: constants.ErrForbidden,
+			expectedError:  constants.ErrForbidden,
+		},
+		{
+			id:              3,
+			repositoryError: constants.ErrUserNotFound,
+			expectedError:   constants.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("AuthorizeUser", mock.Anything, tc.id, rbacEntity.PermissionUsersRead).Return(tc.authorizeError)
+		authorizerMock.On("AuthorizeUser", mock.Anything, tc.id, rbacEntity.PermissionUsersReadPII).Return(tc.readPIIError)
+		if tc.repositoryError != nil {
+			repositoryMock.On("GetByID", mock.Anything, tc.id).Return(nil, tc.repositoryError)
+		} else {
+			found := *userEntity
+			repositoryMock.On("GetByID", mock.Anything, tc.id).Return(&found, nil)
+		}
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		userDTO, err := userService.GetByID(context.Background(), tc.id)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedUserDTO, userDTO)
+	}
+}
+
+func (s *ServiceTestSuite) TestGetByEmail() {
+	userEntity := &entity.User{ID: 2, FirstName: "test", LastName: "test", NickName: "test", Password: "hashed-pass", Email: "test@gmail.com", Country: "UK"}
+	testCases := []struct {
+		email           string
+		readPIIError    error
+		repositoryError error
+		expectedUserDTO *dto.User
+		expectedError   error
+	}{
+		{
+			email:           "test@gmail.com",
+			expectedUserDTO: &dto.User{ID: 2, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK"},
+		},
+		{
+			email:         "test@gmail.com",
+			readPIIError:  constants.ErrForbidden,
+			expectedError: constants.ErrForbidden,
+		},
+		{
+			email:           "missing@gmail.com",
+			repositoryError: constants.ErrUserNotFound,
+			expectedError:   constants.ErrUserNotFound,
+		},
+		{
+			// the principals who can't read the emails can't tell if an email is registered
+			email:           "missing@gmail.com",
+			readPIIError:    constants.ErrForbidden,
+			repositoryError: constants.ErrUserNotFound,
+			expectedError:   constants.ErrForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(tc.readPIIError)
+		authorizerMock.On("AuthorizeUser", mock.Anything, userEntity.ID, rbacEntity.PermissionUsersReadPII).Return(tc.readPIIError)
+		if tc.repositoryError != nil {
+			repositoryMock.On("GetByEmail", mock.Anything, tc.email).Return(nil, tc.repositoryError)
+		} else {
+			found := *userEntity
+			repositoryMock.On("GetByEmail", mock.Anything, tc.email).Return(&found, nil)
+		}
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		userDTO, err := userService.GetByEmail(context.Background(), tc.email)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedUserDTO, userDTO)
+	}
+}
+
+func (s *ServiceTestSuite) TestGetByNickName() {
+	userEntity := &entity.User{ID: 2, FirstName: "test", LastName: "test", NickName: "test", Password: "hashed-pass", Email: "test@gmail.com", Country: "UK"}
+	testCases := []struct {
+		nickName        string
+		readError       error
+		readPIIError    error
+		repositoryError error
+		expectedUserDTO *dto.User
+		expectedError   error
+	}{
+		{
+			nickName:        "test",
+			expectedUserDTO: &dto.User{ID: 2, FirstName: "test", LastName: "test", NickName: "test", Email: "test@gmail.com", Country: "UK"},
+		},
+		{
+			nickName:        "test",
+			readPIIError:    constants.ErrForbidden,
+			expectedUserDTO: &dto.User{ID: 2, NickName: "test", Country: "UK"},
+		},
+		{
+			nickName:      "test",
+			readError:     constants.ErrForbidden,
+			expectedError: constants.ErrForbidden,
+		},
+		{
+			nickName:        "missing",
+			repositoryError: constants.ErrUserNotFound,
+			expectedError:   constants.ErrUserNotFound,
+		},
+		{
+			nickName:        "missing",
+			readError:       constants.ErrUnauthenticated,
+			repositoryError: constants.ErrUserNotFound,
+			expectedError:   constants.ErrUnauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRead).Return(tc.readError)
+		authorizerMock.On("AuthorizeUser", mock.Anything, userEntity.ID, rbacEntity.PermissionUsersRead).Return(tc.readError)
+		authorizerMock.On("AuthorizeUser", mock.Anything, userEntity.ID, rbacEntity.PermissionUsersReadPII).Return(tc.readPIIError)
+		if tc.repositoryError != nil {
+			repositoryMock.On("GetByNickName", mock.Anything, tc.nickName).Return(nil, tc.repositoryError)
+		} else {
+			found := *userEntity
+			repositoryMock.On("GetByNickName", mock.Anything, tc.nickName).Return(&found, nil)
+		}
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		userDTO, err := userService.GetByNickName(context.Background(), tc.nickName)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedUserDTO, userDTO)
+	}
+}
+
+func (s *ServiceTestSuite) TestGetByIDs() {
+	userEntities := []*entity.User{
+		{ID: 1, FirstName: "a", LastName: "a", NickName: "a", Email: "a@gmail.com", Country: "UK"},
+		{ID: 3, FirstName: "c", LastName: "c", NickName: "c", Email: "c@gmail.com", Country: "NL"},
+	}
+	tooManyIDs := make([]int64, MaxBatchIDs+1)
+	for i := range tooManyIDs {
+		tooManyIDs[i] = int64(i)
+	}
+
+	testCases := []struct {
+		IDs                []int64
+		authorizeError     error
+		canReadPII         bool
+		expectedQueriedIDs []int64
+		expectedUserDTOs   []*dto.User
+		expectedMissingIDs []int64
+		expectedError      error
+	}{
+		{
+			// the users are in the order of the IDs and the repeated IDs are only looked up once
+			IDs:                []int64{3, 2, 1, 3},
+			canReadPII:         true,
+			expectedQueriedIDs: []int64{3, 2, 1},
+			expectedUserDTOs: []*dto.User{
+				{ID: 3, FirstName: "c", LastName: "c", NickName: "c", Email: "c@gmail.com", Country: "NL"},
+				{ID: 1, FirstName: "a", LastName: "a", NickName: "a", Email: "a@gmail.com", Country: "UK"},
+			},
+			expectedMissingIDs: []int64{2},
+		},
+		{
+			IDs:                []int64{1, 3},
+			expectedQueriedIDs: []int64{1, 3},
+			expectedUserDTOs:   []*dto.User{{ID: 1, NickName: "a", Country: "UK"}, {ID: 3, NickName: "c", Country: "NL"}},
+			expectedMissingIDs: []int64{},
+		},
+		{
+			IDs:            []int64{1},
+			authorizeError: constants.ErrForbidden,
+			expectedError:  constants.ErrForbidden,
+		},
+		{
+			IDs:           tooManyIDs,
+			expectedError: constants.ErrTooManyIDs,
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRead).Return(tc.authorizeError)
+		authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(tc.canReadPII, nil)
+		if tc.expectedQueriedIDs != nil {
+			found := make([]*entity.User, len(userEntities))
+			for i, userEntity := range userEntities {
+				copied := *userEntity
+				found[i] = &copied
+			}
+			repositoryMock.On("GetByIDs", mock.Anything, tc.expectedQueriedIDs).Return(found, nil).Once()
+		}
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		userDTOs, missingIDs, err := userService.GetByIDs(context.Background(), tc.IDs)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedUserDTOs, userDTOs)
+		assert.Equal(s.T(), tc.expectedMissingIDs, missingIDs)
+		repositoryMock.AssertExpectations(s.T())
+	}
+}
+
+func (s *ServiceTestSuite) TestSearch() {
+	userEntities := []*entity.User{
+		{ID: 1, FirstName: "a", LastName: "a", NickName: "a", Email: "a@gmail.com", Country: "UK"},
+		{ID: 3, FirstName: "c", LastName: "c", NickName: "c", Email: "c@gmail.com", Country: "NL"},
+	}
+
+	testCases := []struct {
+		authorizeError error
+		canReadPII     bool
+		expectedFields []string
+		hits           []search.Hit
+		expectedResult *dto.SearchResult
+		expectedError  error
+	}{
+		{
+			// the users are in the order of the hits, and the ones removed since they were indexed are skipped
+			canReadPII:     true,
+			expectedFields: search.AllFields,
+			hits:           []search.Hit{{ID: 3, Score: 2}, {ID: 2, Score: 1.5}, {ID: 1, Score: 1}},
+			expectedResult: &dto.SearchResult{
+				Hits: []*dto.SearchHit{
+					{User: &dto.User{ID: 3, FirstName: "c", LastName: "c", NickName: "c", Email: "c@gmail.com", Country: "NL"}, Score: 2},
+					{User: &dto.User{ID: 1, FirstName: "a", LastName: "a", NickName: "a", Email: "a@gmail.com", Country: "UK"}, Score: 1},
+				},
+				Total: 3,
+			},
+		},
+		{
+			// only the nicknames are searched without the users:read:pii permission
+			expectedFields: search.PublicFields,
+			hits:           []search.Hit{{ID: 1, Score: 1}},
+			expectedResult: &dto.SearchResult{
+				Hits:  []*dto.SearchHit{{User: &dto.User{ID: 1, NickName: "a", Country: "UK"}, Score: 1}},
+				Total: 1,
+			},
+		},
+		{
+			expectedFields: search.PublicFields,
+			hits:           []search.Hit{},
+			expectedResult: &dto.SearchResult{Hits: []*dto.SearchHit{}},
+		},
+		{
+			authorizeError: constants.ErrForbidden,
+			expectedError:  constants.ErrForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		indexMock := searchMocks.IIndex{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRead).Return(tc.authorizeError)
+		authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(tc.canReadPII, nil)
+		indexMock.On("Search", "text", tc.expectedFields, 10).Return(tc.hits, uint64(len(tc.hits)), nil)
+		found := make([]*entity.User, len(userEntities))
+		for i, userEntity := range userEntities {
+			copied := *userEntity
+			found[i] = &copied
+		}
+		IDs := make([]int64, len(tc.hits))
+		for i, hit := range tc.hits {
+			IDs[i] = hit.ID
+		}
+		repositoryMock.On("GetByIDs", mock.Anything, IDs).Return(found, nil)
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, &indexMock)
+		result, err := userService.Search(context.Background(), "text", 10)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedResult, result)
+	}
+}
+
+func (s *ServiceTestSuite) TestGetPage() {
+	cursorAt := func(ID int64, backward bool) string {
+		cursor, err := testCursors.Encode(&entity.Cursor{Sort: entity.DefaultSort, Values: []interface{}{ID}, Backward: backward})
+		s.Require().NoError(err)
+		return cursor
+	}
+	otherSort, err := testCursors.Encode(&entity.Cursor{Sort: []entity.SortKey{{Column: "id", Descending: true}}, Values: []interface{}{int64(1)}})
+	s.Require().NoError(err)
+	users := func(IDs ...int64) []*entity.User {
+		userEntities := make([]*entity.User, len(IDs))
+		for i, ID := range IDs {
+			userEntities[i] = &entity.User{ID: ID, NickName: "test"}
+		}
+		return userEntities
+	}
+	count := uint64(5)
+
+	testCases := []struct {
+		cursor           string
+		withCount        bool
+		authorizeError   error
+		expectedPosition *entity.Cursor
+		repositoryUsers  []*entity.User
+		expectedIDs      []int64
+		expectedNext     string
+		expectedPrev     string
+		expectedCount    *uint64
+		expectedError    error
+	}{
+		{
+			// the first page
+			repositoryUsers: users(1, 2, 3),
+			expectedIDs:     []int64{1, 2},
+			expectedNext:    cursorAt(2, false),
+		},
+		{
+			// the last page
+			cursor:           cursorAt(4, false),
+			withCount:        true,
+			expectedPosition: &entity.Cursor{Sort: entity.DefaultSort, Values: []interface{}{int64(4)}},
+			repositoryUsers:  users(5),
+			expectedIDs:      []int64{5},
+			expectedPrev:     cursorAt(5, true),
+			expectedCount:    &count,
+		},
+		{
+			// back to the first page
+			cursor:           cursorAt(3, true),
+			expectedPosition: &entity.Cursor{Sort: entity.DefaultSort, Values: []interface{}{int64(3)}, Backward: true},
+			repositoryUsers:  users(1, 2),
+			expectedIDs:      []int64{1, 2},
+			expectedNext:     cursorAt(2, false),
+		},
+		{
+			// back to a page in the middle, the farthest user from the cursor is dropped
+			cursor:           cursorAt(5, true),
+			expectedPosition: &entity.Cursor{Sort: entity.DefaultSort, Values: []interface{}{int64(5)}, Backward: true},
+			repositoryUsers:  users(2, 3, 4),
+			expectedIDs:      []int64{3, 4},
+			expectedNext:     cursorAt(4, false),
+			expectedPrev:     cursorAt(3, true),
+		},
+		{
+			cursor:        "forged",
+			expectedError: constants.ErrInvalidCursor,
+		},
+		{
+			cursor:        otherSort,
+			expectedError: constants.ErrInvalidCursor,
+		},
+		{
+			authorizeError: constants.ErrForbidden,
+			expectedError:  constants.ErrForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		repositoryMock := mocks.IUsersRepository{}
+		authorizerMock := rbacMocks.IAuthorizer{}
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRead).Return(tc.authorizeError)
+		authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(true, nil)
+		repositoryMock.On("GetPage", mock.Anything, &entity.Filter{Countries: []string{"UK"}}, entity.DefaultSort, tc.expectedPosition, int64(3)).Return(tc.repositoryUsers, nil)
+		repositoryMock.On("GetCount", mock.Anything, &entity.Filter{Countries: []string{"UK"}}).Return(count, nil)
+
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		page, err := userService.GetPage(context.Background(), &dto.Filter{Countries: []string{"UK"}}, nil, tc.cursor, 2, tc.withCount)
+		assert.Equal(s.T(), tc.expectedError, err)
+		if tc.expectedError != nil {
+			assert.Nil(s.T(), page)
+			continue
+		}
+
+		IDs := make([]int64, len(page.Users))
+		for i, user := range page.Users {
+			IDs[i] = user.ID
+		}
+		assert.Equal(s.T(), tc.expectedIDs, IDs)
+		assert.Equal(s.T(), tc.expectedNext, page.NextCursor)
+		assert.Equal(s.T(), tc.expectedPrev, page.PrevCursor)
+		assert.Equal(s.T(), tc.expectedCount, page.Count)
+	}
+}
+
+func (s *ServiceTestSuite) TestGetPageSorted() {
+	sort := []dto.SortKey{{Field: "created_at", Descending: true}}
+	sortEntity := []entity.SortKey{{Column: "created_at", Descending: true}, {Column: "id"}}
+	createdAt := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	repositoryMock := mocks.IUsersRepository{}
+	authorizerMock := rbacMocks.IAuthorizer{}
+	authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRead).Return(nil)
+	authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(true, nil)
+	repositoryMock.On("GetPage", mock.Anything, &entity.Filter{}, sortEntity, (*entity.Cursor)(nil), int64(2)).
+		Return([]*entity.User{{ID: 2, CreatedAt: createdAt}, {ID: 1, CreatedAt: createdAt}}, nil)
+
+	userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+	page, err := userService.GetPage(context.Background(), &dto.Filter{}, sort, "", 1, false)
+	s.Require().NoError(err)
+
+	next, err := testCursors.Decode(page.NextCursor)
+	s.Require().NoError(err)
+	assert.Equal(s.T(), &entity.Cursor{Sort: sortEntity, Values: []interface{}{createdAt, int64(2)}}, next)
+
+	// the cursor of another order is rejected
+	_, err = userService.GetPage(context.Background(), &dto.Filter{}, nil, page.NextCursor, 1, false)
+	assert.Equal(s.T(), constants.ErrInvalidCursor, err)
+
+	_, err = userService.GetPage(context.Background(), &dto.Filter{}, []dto.SortKey{{Field: "password"}}, "", 1, false)
+	assert.Equal(s.T(), constants.ErrInvalidSort, err)
+}
 func (s *ServiceTestSuite) TestGet() {
 	testCases := []struct {
 		filter               *dto.Filter
 		entityFilter         *entity.Filter
 		page                 int64
 		pageSize             int64
+		authorizeError       error
+		canReadPII           bool
 		expectedCount        uint64
 		expectedUserDTOs     []*dto.User
 		expectedUserEntities []*entity.User
@@ -162,13 +928,14 @@ func (s *ServiceTestSuite) TestGet() {
 	}{
 		{
 			filter: &dto.Filter{
-				Country: "UK",
+				Countries: []string{"UK"},
 			},
 			entityFilter: &entity.Filter{
-				Country: "UK",
+				Countries: []string{"UK"},
 			},
 			page:          0,
 			pageSize:      10,
+			canReadPII:    true,
 			expectedCount: 1,
 			expectedUserDTOs: []*dto.User{
 				{
@@ -193,21 +960,150 @@ func (s *ServiceTestSuite) TestGet() {
 			},
 			expectedError: nil,
 		},
+		{
+			filter: &dto.Filter{
+				Countries: []string{"DE"},
+			},
+			entityFilter: &entity.Filter{
+				Countries: []string{"DE"},
+			},
+			page:          0,
+			pageSize:      10,
+			canReadPII:    false,
+			expectedCount: 1,
+			expectedUserDTOs: []*dto.User{
+				{
+					ID:       2,
+					NickName: "test2",
+					Country:  "DE",
+				},
+			},
+			expectedUserEntities: []*entity.User{
+				{
+					ID:        2,
+					FirstName: "test2",
+					LastName:  "test2",
+					NickName:  "test2",
+					Password:  "pass",
+					Email:     "test2@gmail.com",
+					Country:   "DE",
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			filter:           &dto.Filter{},
+			entityFilter:     &entity.Filter{},
+			page:             1,
+			pageSize:         10,
+			authorizeError:   constants.ErrForbidden,
+			expectedCount:    0,
+			expectedUserDTOs: nil,
+			expectedError:    constants.ErrForbidden,
+		},
+		{
+			// the users found by their email tell it without the users:read:pii permission
+			filter:           &dto.Filter{Email: dto.TextFilter{Value: "test@gmail.com", Match: dto.MatchExact}},
+			entityFilter:     &entity.Filter{Email: dto.TextFilter{Value: "test@gmail.com", Match: dto.MatchExact}},
+			page:             1,
+			pageSize:         10,
+			canReadPII:       false,
+			expectedCount:    0,
+			expectedUserDTOs: nil,
+			expectedError:    constants.ErrForbidden,
+		},
 	}
 
 	repositoryMock := mocks.IUsersRepository{}
+	authorizerMock := rbacMocks.IAuthorizer{}
 	for _, tc := range testCases {
-		repositoryMock.On("Get", mock.Anything, tc.entityFilter, tc.page, tc.pageSize).Return(tc.expectedUserEntities, tc.expectedError)
+		authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionUsersRead).Return(tc.authorizeError).Once()
+		authorizerMock.On("HasPermission", mock.Anything, rbacEntity.PermissionUsersReadPII).Return(tc.canReadPII, nil).Once()
+		repositoryMock.On("Get", mock.Anything, tc.entityFilter, entity.DefaultSort, tc.page, tc.pageSize).Return(tc.expectedUserEntities, tc.expectedError)
 		repositoryMock.On("GetCount", mock.Anything, tc.entityFilter).Return(tc.expectedCount, tc.expectedError)
 
-		userService := NewUserService(&repositoryMock)
-		userDTOs, count, err := userService.Get(context.Background(), tc.filter, tc.page, tc.pageSize)
+		userService := NewUserService(&repositoryMock, &hasherMocks.IHasher{}, &authorizerMock, testCursors, nil)
+		userDTOs, count, err := userService.Get(context.Background(), tc.filter, nil, tc.page, tc.pageSize)
 		assert.Equal(s.T(), tc.expectedError, err)
 		assert.Equal(s.T(), tc.expectedCount, count)
 		assert.Equal(s.T(), tc.expectedUserDTOs, userDTOs)
 	}
 }
 
+func (s *ServiceTestSuite) TestAuthenticate() {
+	userEntity := &entity.User{
+		ID:       1,
+		NickName: "test",
+		Password: "hashed-pass",
+		Email:    "test@gmail.com",
+		Country:  "UK",
+	}
+
+	testCases := []struct {
+		login           string
+		password        string
+		samePassword    bool
+		expectedUserDTO *dto.User
+		expectedError   error
+	}{
+		{
+			login:        "test@gmail.com",
+			password:     "pass",
+			samePassword: true,
+			expectedUserDTO: &dto.User{
+				ID:       1,
+				NickName: "test",
+				Email:    "test@gmail.com",
+				Country:  "UK",
+			},
+			expectedError: nil,
+		},
+		{
+			login:        "test",
+			password:     "pass",
+			samePassword: true,
+			expectedUserDTO: &dto.User{
+				ID:       1,
+				NickName: "test",
+				Email:    "test@gmail.com",
+				Country:  "UK",
+			},
+			expectedError: nil,
+		},
+		{
+			login:           "test",
+			password:        "wrong",
+			samePassword:    false,
+			expectedUserDTO: nil,
+			expectedError:   constants.ErrInvalidCredentials,
+		},
+		{
+			login:           "unknown@gmail.com",
+			password:        "pass",
+			expectedUserDTO: nil,
+			expectedError:   constants.ErrInvalidCredentials,
+		},
+	}
+
+	repositoryMock := mocks.IUsersRepository{}
+	hasherMock := hasherMocks.IHasher{}
+	repositoryMock.On("GetByEmail", mock.Anything, "test@gmail.com").Return(userEntity, nil)
+	repositoryMock.On("GetByEmail", mock.Anything, "unknown@gmail.com").Return(nil, constants.ErrUserNotFound)
+	repositoryMock.On("GetByNickName", mock.Anything, "test").Return(userEntity, nil)
+	// the unknown logins are verified against a dummy hash, so they take as long as the wrong passwords
+	hasherMock.On("Hash", "dummy-password").Return("hashed-dummy-password", nil).Once()
+	hasherMock.On("Verify", mock.Anything, "hashed-dummy-password").Return(false, false, nil)
+	for _, tc := range testCases {
+		hasherMock.On("Verify", tc.password, userEntity.Password).Return(tc.samePassword, false, nil)
+
+		userService := NewUserService(&repositoryMock, &hasherMock, &rbacMocks.IAuthorizer{}, testCursors, nil)
+		userDTO, err := userService.Authenticate(context.Background(), tc.login, tc.password)
+		assert.Equal(s.T(), tc.expectedError, err)
+		assert.Equal(s.T(), tc.expectedUserDTO, userDTO)
+	}
+	hasherMock.AssertCalled(s.T(), "Verify", "pass", "hashed-dummy-password")
+}
+
 func TestServiceTestSuite(t *testing.T) {
 	suite.Run(t, new(ServiceTestSuite))
 }
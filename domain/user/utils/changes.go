@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+)
+
+// UserChanges - Compares the stored user with an update, where the empty fields of the update are not changed.
+// The password of the update is expected to be a new hash, so its values are redacted.
+func UserChanges(before, update *entity.User) map[string]events.Change {
+	changes := make(map[string]events.Change)
+	addChange := func(field, before, after string) {
+		if after != "" && after != before {
+			changes[field] = events.Change{Before: before, After: after}
+		}
+	}
+
+	addChange("first_name", before.FirstName, update.FirstName)
+	addChange("last_name", before.LastName, update.LastName)
+	addChange("nick_name", before.NickName, update.NickName)
+	addChange("email", before.Email, update.Email)
+	addChange("country", before.Country, update.Country)
+	if update.Password != "" {
+		changes["password"] = events.Change{Before: events.Redacted, After: events.Redacted}
+	}
+
+	return changes
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChangesTestSuite struct {
+	suite.Suite
+}
+
+func (c *ChangesTestSuite) TestUserChanges() {
+	before := &entity.User{
+		ID:        1,
+		FirstName: "test",
+		LastName:  "test",
+		NickName:  "test",
+		Password:  "hash",
+		Email:     "test@gmail.com",
+		Country:   "UK",
+	}
+
+	testCases := []struct {
+		update          *entity.User
+		expectedChanges map[string]events.Change
+	}{
+		{
+			update:          &entity.User{ID: 1},
+			expectedChanges: map[string]events.Change{},
+		},
+		{
+			update:          &entity.User{ID: 1, FirstName: "test", Country: "UK"},
+			expectedChanges: map[string]events.Change{},
+		},
+		{
+			update: &entity.User{ID: 1, FirstName: "new", Email: "new@gmail.com"},
+			expectedChanges: map[string]events.Change{
+				"first_name": {Before: "test", After: "new"},
+				"email":      {Before: "test@gmail.com", After: "new@gmail.com"},
+			},
+		},
+		{
+			update: &entity.User{ID: 1, Password: "new-hash"},
+			expectedChanges: map[string]events.Change{
+				"password": {Before: events.Redacted, After: events.Redacted},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(c.T(), tc.expectedChanges, UserChanges(before, tc.update))
+	}
+}
+
+func TestChangesTestSuite(t *testing.T) {
+	suite.Run(t, new(ChangesTestSuite))
+}
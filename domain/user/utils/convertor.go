@@ -3,6 +3,7 @@ package utils
 import (
 	"faceit/domain/user/dto"
 	"faceit/domain/user/entity"
+	"faceit/domain/user/events"
 )
 
 func UserDTOFromEntity(entity *entity.User) *dto.User {
@@ -15,6 +16,8 @@ func UserDTOFromEntity(entity *entity.User) *dto.User {
 		Country:   entity.Country,
 		CreatedAt: entity.CreatedAt,
 		UpdatedAt: entity.UpdatedAt,
+		Version:   entity.Version,
+		DeletedAt: entity.DeletedAt,
 	}
 }
 
@@ -28,5 +31,58 @@ func UserEntityFromDTO(dto *dto.User) *entity.User {
 		Country:   dto.Country,
 		CreatedAt: dto.CreatedAt,
 		UpdatedAt: dto.UpdatedAt,
+		Version:   dto.Version,
 	}
 }
+
+func AuditEntryDTOFromEntity(entity *entity.AuditEntry) *dto.AuditEntry {
+	return &dto.AuditEntry{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		Action:    entity.Action,
+		ActorID:   entity.ActorID,
+		RequestID: entity.RequestID,
+		SourceIP:  entity.SourceIP,
+		Changes:   entity.Changes,
+		CreatedAt: entity.CreatedAt,
+	}
+}
+
+// RedactPII - Removes the personally identifiable information of the user
+func RedactPII(dto *dto.User) {
+	dto.FirstName = ""
+	dto.LastName = ""
+	dto.Email = ""
+}
+
+// piiFields - The fields of the changes in the audit entries which are personally identifiable information
+var piiFields = []string{"first_name", "last_name", "email"}
+
+// RedactAuditPII - Redacts the values of the personally identifiable information in the changes of the entry,
+// which still shows that they are changed. The changes are copied, as they may be shared with the entity.
+func RedactAuditPII(dto *dto.AuditEntry) {
+	if len(dto.Changes) == 0 {
+		return
+	}
+
+	changes := make(map[string]events.Change, len(dto.Changes))
+	for field, change := range dto.Changes {
+		changes[field] = change
+	}
+	for _, field := range piiFields {
+		if change, ok := changes[field]; ok {
+			changes[field] = events.Change{Before: redact(change.Before), After: redact(change.After)}
+		}
+	}
+
+	dto.Changes = changes
+}
+
+// redact - Redacts the value unless it's empty, e.g. the value before the user was created
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return events.Redacted
+}
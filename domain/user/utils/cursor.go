@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"faceit/domain/constants"
+	entity2 "faceit/domain/user/entity"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CursorCodec - Encodes the cursors as opaque strings signed with a secret, so the clients can't forge or change the positions
+type CursorCodec struct {
+	secret []byte
+}
+
+// cursorPayload - The encoded cursor, the keys are the sort columns with a - prefix for the descending ones
+type cursorPayload struct {
+	Keys     []string          `json:"k"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// Encode - Returns the cursor as a url safe string
+func (c *CursorCodec) Encode(cursor *entity2.Cursor) (string, error) {
+	payload := cursorPayload{
+		Keys:     make([]string, len(cursor.Sort)),
+		Values:   make([]json.RawMessage, len(cursor.Values)),
+		Backward: cursor.Backward,
+	}
+	for i, key := range cursor.Sort {
+		payload.Keys[i] = key.Column
+		if key.Descending {
+			payload.Keys[i] = "-" + key.Column
+		}
+	}
+	for i, value := range cursor.Values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cursor value: %w", err)
+		}
+		payload.Values[i] = encoded
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded) + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode - Returns the cursor of the string, constants.ErrInvalidCursor if it's not a cursor given by Encode
+func (c *CursorCodec) Decode(value string) (*entity2.Cursor, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(value, ".")
+	if !found {
+		return nil, constants.ErrInvalidCursor
+	}
+
+	encoded, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, constants.ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(encoded)) {
+		return nil, constants.ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil || len(payload.Keys) != len(payload.Values) {
+		return nil, constants.ErrInvalidCursor
+	}
+
+	cursor := &entity2.Cursor{
+		Sort:     make([]entity2.SortKey, len(payload.Keys)),
+		Values:   make([]interface{}, len(payload.Values)),
+		Backward: payload.Backward,
+	}
+	for i, key := range payload.Keys {
+		cursor.Sort[i] = entity2.SortKey{Column: strings.TrimPrefix(key, "-"), Descending: strings.HasPrefix(key, "-")}
+		cursor.Values[i], err = decodeCursorValue(cursor.Sort[i].Column, payload.Values[i])
+		if err != nil {
+			return nil, constants.ErrInvalidCursor
+		}
+	}
+
+	return cursor, nil
+}
+
+// CursorOf - Returns the cursor at the position of the user in the order of the sort keys
+func CursorOf(user *entity2.User, sort []entity2.SortKey, backward bool) *entity2.Cursor {
+	values := make([]interface{}, len(sort))
+	for i, key := range sort {
+		switch key.Column {
+		case "id":
+			values[i] = user.ID
+		case "nick_name":
+			values[i] = user.NickName
+		case "country":
+			values[i] = user.Country
+		case "created_at":
+			values[i] = user.CreatedAt
+		case "updated_at":
+			values[i] = user.UpdatedAt
+		}
+	}
+
+	return &entity2.Cursor{Sort: sort, Values: values, Backward: backward}
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// decodeCursorValue - Decodes the value of the sort column to the type of the column
+func decodeCursorValue(column string, raw json.RawMessage) (interface{}, error) {
+	switch column {
+	case "id":
+		var value json.Number
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		return value.Int64()
+	case "nick_name", "country":
+		var value string
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	case "created_at", "updated_at":
+		var value time.Time
+		err := json.Unmarshal(raw, &value)
+		return value, err
+	default:
+		return nil, fmt.Errorf("unknown sort column %q", column)
+	}
+}
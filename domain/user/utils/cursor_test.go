@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"faceit/domain/constants"
+	entity2 "faceit/domain/user/entity"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CursorTestSuite struct {
+	suite.Suite
+}
+
+func (c *CursorTestSuite) TestEncodeDecode() {
+	codec := NewCursorCodec([]byte("secret"))
+	testCases := []*entity2.Cursor{
+		{Sort: entity2.DefaultSort, Values: []interface{}{int64(42)}},
+		{Sort: []entity2.SortKey{{Column: "id", Descending: true}}, Values: []interface{}{int64(1 << 60)}, Backward: true},
+		{
+			Sort:   []entity2.SortKey{{Column: "created_at", Descending: true}, {Column: "nick_name"}, {Column: "id"}},
+			Values: []interface{}{time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC), "test", int64(3)},
+		},
+	}
+
+	for _, cursor := range testCases {
+		encoded, err := codec.Encode(cursor)
+		c.Require().NoError(err)
+
+		decoded, err := codec.Decode(encoded)
+		assert.NoError(c.T(), err)
+		assert.Equal(c.T(), cursor, decoded)
+	}
+}
+
+func (c *CursorTestSuite) TestDecodeInvalid() {
+	codec := NewCursorCodec([]byte("secret"))
+	encoded, err := codec.Encode(&entity2.Cursor{Sort: entity2.DefaultSort, Values: []interface{}{int64(42)}})
+	c.Require().NoError(err)
+	payload, signature, _ := strings.Cut(encoded, ".")
+
+	forged, err := NewCursorCodec([]byte("other")).Encode(&entity2.Cursor{Sort: entity2.DefaultSort, Values: []interface{}{int64(1)}})
+	c.Require().NoError(err)
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	for _, value := range []string{
+		"",
+		"abc",
+		payload,
+		payload + ".",
+		payload + "." + signature + "x",
+		// a payload of another cursor with the signature of this one
+		forgedPayload + "." + signature,
+		// a cursor signed with another secret
+		forged,
+	} {
+		cursor, err := codec.Decode(value)
+		assert.Equal(c.T(), constants.ErrInvalidCursor, err, value)
+		assert.Nil(c.T(), cursor)
+	}
+}
+
+func (c *CursorTestSuite) TestCursorOf() {
+	user := &entity2.User{ID: 7, NickName: "test"}
+	assert.Equal(c.T(), &entity2.Cursor{Sort: entity2.DefaultSort, Values: []interface{}{int64(7)}, Backward: true}, CursorOf(user, entity2.DefaultSort, true))
+
+	sort := []entity2.SortKey{{Column: "country"}, {Column: "updated_at", Descending: true}, {Column: "id"}}
+	user.Country, user.UpdatedAt = "UK", time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(c.T(), &entity2.Cursor{Sort: sort, Values: []interface{}{"UK", user.UpdatedAt, int64(7)}}, CursorOf(user, sort, false))
+}
+
+func TestCursorTestSuite(t *testing.T) {
+	suite.Run(t, new(CursorTestSuite))
+}
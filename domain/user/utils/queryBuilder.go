@@ -1,74 +1,253 @@
 package utils
 
 import (
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
 	entity2 "faceit/domain/user/entity"
-	"fmt"
-	"strings"
 )
 
-func QueryBuilder(filter *entity2.Filter, tableName string, page, pageSize int64) string {
-	query := `SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM ` + tableName
+var userColumns = []string{"id", "first_name", "last_name", "nick_name", "email", "country", "created_at", "updated_at", "version"}
 
-	var conditions []string
-	if filter.Country != "" {
-		conditions = append(conditions, fmt.Sprintf("country = \"%s\"", filter.Country))
+var auditColumns = []string{"id", "user_id", "action", "actor_id", "request_id", "source_ip", "changes", "created_at"}
+
+// sortColumns - The only columns which can get into the ORDER BY clauses, the other sort keys are dropped by the builders
+var sortColumns = map[string]bool{"id": true, "nick_name": true, "country": true, "created_at": true, "updated_at": true}
+
+// NormalizeSort - Returns the sort keys with the ID as the last one, which makes the order unique, or the default sort without keys.
+// It returns constants.ErrInvalidSort if a key is not one of the sortable columns or it's repeated.
+func NormalizeSort(sort []entity2.SortKey) ([]entity2.SortKey, error) {
+	if len(sort) == 0 {
+		return entity2.DefaultSort, nil
 	}
-	if filter.NickName != "" {
-		conditions = append(conditions, fmt.Sprintf("nick_name LIKE \"%%%s%%\"", filter.NickName))
+
+	seen := make(map[string]bool, len(sort))
+	for _, key := range sort {
+		if !sortColumns[key.Column] || seen[key.Column] {
+			return nil, constants.ErrInvalidSort
+		}
+		seen[key.Column] = true
 	}
 
-	joinedConditions := strings.Join(conditions, " AND ")
-	if joinedConditions != "" {
-		query += " WHERE " + joinedConditions
+	normalized := append([]entity2.SortKey{}, sort...)
+	if !seen["id"] {
+		normalized = append(normalized, entity2.SortKey{Column: "id"})
 	}
 
-	query += fmt.Sprintf(" ORDER BY id LIMIT %d OFFSET %d", pageSize, (page-1)*pageSize)
+	return normalized, nil
+}
+
+func QueryBuilder(filter *entity2.Filter, tableName string, sort []entity2.SortKey, page, pageSize int64) (string, []interface{}) {
+	builder := Select(tableName, userColumns...).
+		Where(filterConditions(filter)...)
+
+	for _, key := range allowedSort(sort) {
+		builder.OrderBy(key.Column, key.Descending)
+	}
 
-	return query
+	return builder.
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Build()
 }
 
-func CountQueryBuilder(filter *entity2.Filter, tableName string) string {
-	query := `SELECT count(*) as total FROM ` + tableName
+// PageQueryBuilder - Selects a page of the users in the order of the sort keys, after or before the cursor if there is one.
+// The users before the cursor are selected in the reverse order, so the limit keeps the closest ones to the cursor.
+func PageQueryBuilder(filter *entity2.Filter, tableName string, sort []entity2.SortKey, cursor *entity2.Cursor, limit int64) (string, []interface{}) {
+	builder := Select(tableName, userColumns...).
+		Where(filterConditions(filter)...)
 
-	var conditions []string
-	if filter.Country != "" {
-		conditions = append(conditions, fmt.Sprintf("country = \"%s\"", filter.Country))
+	sort = allowedSort(sort)
+	backward := cursor != nil && cursor.Backward
+	if cursor != nil && len(cursor.Values) == len(sort) {
+		builder.Where(keysetCondition(sort, cursor.Values, backward))
 	}
-	if filter.NickName != "" {
-		conditions = append(conditions, fmt.Sprintf("nick_name LIKE \"%%%s%%\"", filter.NickName))
+
+	for _, key := range sort {
+		builder.OrderBy(key.Column, key.Descending != backward)
 	}
 
-	joinedConditions := strings.Join(conditions, " AND ")
-	if joinedConditions != "" {
-		query += " WHERE " + joinedConditions
+	return builder.Limit(limit).Build()
+}
+
+// IDsQueryBuilder - Selects the users with the given IDs which are not removed, the order of the users is not defined
+func IDsQueryBuilder(IDs []int64, tableName string) (string, []interface{}) {
+	values := make([]interface{}, len(IDs))
+	for i, ID := range IDs {
+		values[i] = ID
 	}
 
-	return query
+	return Select(tableName, userColumns...).
+		Where(IsNull("deleted_at"), In("id", values...)).
+		Build()
 }
 
-func UpdateQueryBuilder(user *entity2.User, tableName string) string {
-	query := `UPDATE ` + tableName + ` SET`
-	var updateFields []string
+func CountQueryBuilder(filter *entity2.Filter, tableName string) (string, []interface{}) {
+	return Select(tableName, "count(*) as total").
+		Where(filterConditions(filter)...).
+		Build()
+}
+
+// AuditQueryBuilder - Selects a page of the audit entries with the filter, the latest first
+func AuditQueryBuilder(filter *entity2.AuditFilter, tableName string, page, pageSize int64) (string, []interface{}) {
+	var conditions []Condition
+	if filter.UserID != nil {
+		conditions = append(conditions, Eq("user_id", *filter.UserID))
+	}
+	if filter.ActorID != nil {
+		conditions = append(conditions, Eq("actor_id", *filter.ActorID))
+	}
+	if len(filter.Actions) > 0 {
+		actions := make([]interface{}, len(filter.Actions))
+		for i, action := range filter.Actions {
+			actions[i] = action
+		}
+		conditions = append(conditions, In("action", actions...))
+	}
+	conditions = appendTimeRange(conditions, "created_at", filter.CreatedAt)
+
+	return Select(tableName, auditColumns...).
+		Where(conditions...).
+		OrderBy("id", true).
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Build()
+}
+
+// UpdateQueryBuilder - Updates the given fields of the user and increments its version.
+// It only matches the user at the version of the given user, so an update based on an older version changes nothing.
+func UpdateQueryBuilder(user *entity2.User, tableName string) (string, []interface{}) {
+	builder := Update(tableName)
 	if user.FirstName != "" {
-		updateFields = append(updateFields, fmt.Sprintf("first_name = \"%s\"", user.FirstName))
+		builder.Set("first_name", user.FirstName)
 	}
 	if user.LastName != "" {
-		updateFields = append(updateFields, fmt.Sprintf("last_name = \"%s\"", user.LastName))
+		builder.Set("last_name", user.LastName)
 	}
 	if user.NickName != "" {
-		updateFields = append(updateFields, fmt.Sprintf("nick_name = \"%s\"", user.NickName))
+		builder.Set("nick_name", user.NickName)
 	}
 	if user.Email != "" {
-		updateFields = append(updateFields, fmt.Sprintf("email = \"%s\"", user.Email))
+		builder.Set("email", user.Email)
 	}
 	if user.Country != "" {
-		updateFields = append(updateFields, fmt.Sprintf("country = \"%s\"", user.Country))
+		builder.Set("country", user.Country)
 	}
 	if user.Password != "" {
-		updateFields = append(updateFields, fmt.Sprintf("password = \"%s\"", user.Password))
+		builder.Set("password", user.Password)
+	}
+
+	return builder.
+		SetExpression("updated_at", "NOW()").
+		SetExpression("version", "version + 1").
+		Where(Eq("id", user.ID), IsNull("deleted_at"), Eq("version", user.Version)).
+		Build()
+}
+
+// filterConditions - The conditions of the filter, the removed users never match
+func filterConditions(filter *entity2.Filter) []Condition {
+	conditions := []Condition{IsNull("deleted_at")}
+	if len(filter.Countries) > 0 {
+		countries := make([]interface{}, len(filter.Countries))
+		for i, country := range filter.Countries {
+			countries[i] = country
+		}
+		conditions = append(conditions, In("country", countries...))
+	}
+
+	conditions = appendTextCondition(conditions, "nick_name", filter.NickName)
+	conditions = appendTextCondition(conditions, "email", filter.Email)
+	conditions = appendTextCondition(conditions, "first_name", filter.FirstName)
+	conditions = appendTextCondition(conditions, "last_name", filter.LastName)
+
+	conditions = appendInt64Range(conditions, "id", filter.ID)
+	conditions = appendTimeRange(conditions, "created_at", filter.CreatedAt)
+	conditions = appendTimeRange(conditions, "updated_at", filter.UpdatedAt)
+
+	return conditions
+}
+
+// appendTextCondition - Adds the condition of the text filter unless its value is empty
+func appendTextCondition(conditions []Condition, column string, filter dto.TextFilter) []Condition {
+	if filter.Value == "" {
+		return conditions
+	}
+
+	switch filter.Match {
+	case dto.MatchPrefix:
+		return append(conditions, HasPrefix(column, filter.Value))
+	case dto.MatchContains:
+		return append(conditions, Contains(column, filter.Value))
+	default:
+		return append(conditions, Eq(column, filter.Value))
+	}
+}
+
+// appendInt64Range - Adds the conditions of the bounds of the range which are set
+func appendInt64Range(conditions []Condition, column string, int64Range dto.Int64Range) []Condition {
+	if int64Range.Gt != nil {
+		conditions = append(conditions, Gt(column, *int64Range.Gt))
+	}
+	if int64Range.Gte != nil {
+		conditions = append(conditions, Gte(column, *int64Range.Gte))
+	}
+	if int64Range.Lt != nil {
+		conditions = append(conditions, Lt(column, *int64Range.Lt))
+	}
+	if int64Range.Lte != nil {
+		conditions = append(conditions, Lte(column, *int64Range.Lte))
+	}
+
+	return conditions
+}
+
+// appendTimeRange - Adds the conditions of the bounds of the range which are set
+func appendTimeRange(conditions []Condition, column string, timeRange dto.TimeRange) []Condition {
+	if timeRange.Gt != nil {
+		conditions = append(conditions, Gt(column, *timeRange.Gt))
+	}
+	if timeRange.Gte != nil {
+		conditions = append(conditions, Gte(column, *timeRange.Gte))
+	}
+	if timeRange.Lt != nil {
+		conditions = append(conditions, Lt(column, *timeRange.Lt))
+	}
+	if timeRange.Lte != nil {
+		conditions = append(conditions, Lte(column, *timeRange.Lte))
+	}
+
+	return conditions
+}
+
+// allowedSort - Drops the sort keys which are not sortable columns
+func allowedSort(sort []entity2.SortKey) []entity2.SortKey {
+	allowed := make([]entity2.SortKey, 0, len(sort))
+	for _, key := range sort {
+		if sortColumns[key.Column] {
+			allowed = append(allowed, key)
+		}
+	}
+
+	return allowed
+}
+
+// keysetCondition - The rows after the values in the order of the sort keys, or before them if it's backward:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with < for the descending keys.
+func keysetCondition(sort []entity2.SortKey, values []interface{}, backward bool) Condition {
+	alternatives := make([]Condition, len(sort))
+	for i, key := range sort {
+		conditions := make([]Condition, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, Eq(sort[j].Column, values[j]))
+		}
+
+		if key.Descending != backward {
+			conditions = append(conditions, Lt(key.Column, values[i]))
+		} else {
+			conditions = append(conditions, Gt(key.Column, values[i]))
+		}
+
+		alternatives[i] = And(conditions...)
 	}
 
-	joinedUpdateFields := strings.Join(updateFields, " , ")
-	query += " " + joinedUpdateFields + ` , updated_at = NOW() ` + fmt.Sprintf("WHERE id = %d", user.ID)
-	return query
+	return Or(alternatives...)
 }
@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"faceit/domain/constants"
+	"faceit/domain/user/dto"
 	entity2 "faceit/domain/user/entity"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -13,46 +16,141 @@ type QueryBuilderTestSuite struct {
 }
 
 func (q *QueryBuilderTestSuite) TestQueryBuilder() {
+	minID, maxID := int64(10), int64(20)
+	from, to := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
 	testCases := []struct {
 		filter        *entity2.Filter
 		tableName     string
+		sort          []entity2.SortKey
 		page          int64
 		pageSize      int64
 		expectedQuery string
+		expectedArgs  []interface{}
 	}{
 		{
 			filter: &entity2.Filter{
-				Country: "UK",
+				Countries: []string{"UK"},
 			},
 			tableName:     "users",
 			page:          1,
 			pageSize:      10,
-			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users WHERE country = \"UK\" ORDER BY id LIMIT 10 OFFSET 0",
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND country IN (?) ORDER BY id LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{"UK", int64(10), int64(0)},
 		},
 		{
 			filter: &entity2.Filter{
-				NickName: "test",
+				NickName: dto.TextFilter{Value: "test", Match: dto.MatchContains},
 			},
 			tableName:     "users",
 			page:          1,
 			pageSize:      10,
-			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users WHERE nick_name LIKE \"%test%\" ORDER BY id LIMIT 10 OFFSET 0",
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND nick_name LIKE ? ESCAPE '!' ORDER BY id LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{"%test%", int64(10), int64(0)},
 		},
 		{
 			filter: &entity2.Filter{
-				Country:  "UK",
-				NickName: "test",
+				Countries: []string{"UK"},
+				NickName:  dto.TextFilter{Value: "test", Match: dto.MatchContains},
 			},
 			tableName:     "users",
 			page:          1,
 			pageSize:      10,
-			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at FROM users WHERE country = \"UK\" AND nick_name LIKE \"%test%\" ORDER BY id LIMIT 10 OFFSET 0",
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND country IN (?) AND nick_name LIKE ? ESCAPE '!' ORDER BY id LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{"UK", "%test%", int64(10), int64(0)},
+		},
+		{
+			filter: &entity2.Filter{
+				NickName: dto.TextFilter{Value: "100%_a!", Match: dto.MatchContains},
+			},
+			tableName:     "users",
+			page:          3,
+			pageSize:      20,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND nick_name LIKE ? ESCAPE '!' ORDER BY id LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{"%100!%!_a!!%", int64(20), int64(40)},
+		},
+		{
+			filter: &entity2.Filter{
+				Countries: []string{"UK\" OR 1=1 -- "},
+			},
+			tableName:     "users",
+			page:          1,
+			pageSize:      10,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND country IN (?) ORDER BY id LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{"UK\" OR 1=1 -- ", int64(10), int64(0)},
+		},
+		{
+			filter: &entity2.Filter{
+				Countries: []string{"UK", "DE"},
+				Email:     dto.TextFilter{Value: "test@gmail.com", Match: dto.MatchExact},
+				FirstName: dto.TextFilter{Value: "te_", Match: dto.MatchPrefix},
+				LastName:  dto.TextFilter{Value: "st", Match: dto.MatchContains},
+				ID:        dto.Int64Range{Gt: &minID, Lte: &maxID},
+				CreatedAt: dto.TimeRange{Gte: &from, Lt: &to},
+				UpdatedAt: dto.TimeRange{Lte: &to},
+			},
+			tableName: "users",
+			page:      1,
+			pageSize:  10,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users " +
+				"WHERE deleted_at IS NULL AND country IN (?, ?) AND email = ? AND first_name LIKE ? ESCAPE '!' AND last_name LIKE ? ESCAPE '!' " +
+				"AND id > ? AND id <= ? AND created_at >= ? AND created_at < ? AND updated_at <= ? ORDER BY id LIMIT ? OFFSET ?",
+			expectedArgs: []interface{}{"UK", "DE", "test@gmail.com", "te!_%", "%st%", minID, maxID, from, to, to, int64(10), int64(0)},
+		},
+		{
+			filter:        &entity2.Filter{},
+			tableName:     "users",
+			sort:          []entity2.SortKey{{Column: "created_at", Descending: true}, {Column: "nick_name"}, {Column: "id"}},
+			page:          2,
+			pageSize:      10,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC, nick_name, id LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{int64(10), int64(10)},
+		},
+		{
+			filter:        &entity2.Filter{},
+			tableName:     "users",
+			sort:          []entity2.SortKey{{Column: "password; DROP TABLE users"}, {Column: "id", Descending: true}},
+			page:          1,
+			pageSize:      10,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL ORDER BY id DESC LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{int64(10), int64(0)},
 		},
 	}
 
 	for _, tc := range testCases {
-		query := QueryBuilder(tc.filter, tc.tableName, tc.page, tc.pageSize)
+		sort := tc.sort
+		if sort == nil {
+			sort = entity2.DefaultSort
+		}
+
+		query, args := QueryBuilder(tc.filter, tc.tableName, sort, tc.page, tc.pageSize)
 		assert.Equal(q.T(), tc.expectedQuery, query)
+		assert.Equal(q.T(), tc.expectedArgs, args)
+	}
+}
+
+func (q *QueryBuilderTestSuite) TestNormalizeSort() {
+	testCases := []struct {
+		sort          []entity2.SortKey
+		expectedSort  []entity2.SortKey
+		expectedError error
+	}{
+		{sort: nil, expectedSort: entity2.DefaultSort},
+		{
+			sort:         []entity2.SortKey{{Column: "created_at", Descending: true}},
+			expectedSort: []entity2.SortKey{{Column: "created_at", Descending: true}, {Column: "id"}},
+		},
+		{
+			sort:         []entity2.SortKey{{Column: "id", Descending: true}, {Column: "country"}},
+			expectedSort: []entity2.SortKey{{Column: "id", Descending: true}, {Column: "country"}},
+		},
+		{sort: []entity2.SortKey{{Column: "email"}}, expectedError: constants.ErrInvalidSort},
+		{sort: []entity2.SortKey{{Column: "country"}, {Column: "country", Descending: true}}, expectedError: constants.ErrInvalidSort},
+	}
+
+	for _, tc := range testCases {
+		sort, err := NormalizeSort(tc.sort)
+		assert.Equal(q.T(), tc.expectedError, err)
+		assert.Equal(q.T(), tc.expectedSort, sort)
 	}
 }
 
@@ -61,34 +159,149 @@ func (q *QueryBuilderTestSuite) TestCountQueryBuilder() {
 		filter        *entity2.Filter
 		tableName     string
 		expectedQuery string
+		expectedArgs  []interface{}
 	}{
 		{
 			filter: &entity2.Filter{
-				Country: "UK",
+				Countries: []string{"UK"},
 			},
 			tableName:     "users",
-			expectedQuery: "SELECT count(*) as total FROM users WHERE country = \"UK\"",
+			expectedQuery: "SELECT count(*) as total FROM users WHERE deleted_at IS NULL AND country IN (?)",
+			expectedArgs:  []interface{}{"UK"},
 		},
 		{
 			filter: &entity2.Filter{
-				NickName: "test",
+				NickName: dto.TextFilter{Value: "test", Match: dto.MatchContains},
 			},
 			tableName:     "users",
-			expectedQuery: "SELECT count(*) as total FROM users WHERE nick_name LIKE \"%test%\"",
+			expectedQuery: "SELECT count(*) as total FROM users WHERE deleted_at IS NULL AND nick_name LIKE ? ESCAPE '!'",
+			expectedArgs:  []interface{}{"%test%"},
 		},
 		{
 			filter: &entity2.Filter{
-				Country:  "UK",
-				NickName: "test",
+				Countries: []string{"UK"},
+				NickName:  dto.TextFilter{Value: "test", Match: dto.MatchContains},
 			},
 			tableName:     "users",
-			expectedQuery: "SELECT count(*) as total FROM users WHERE country = \"UK\" AND nick_name LIKE \"%test%\"",
+			expectedQuery: "SELECT count(*) as total FROM users WHERE deleted_at IS NULL AND country IN (?) AND nick_name LIKE ? ESCAPE '!'",
+			expectedArgs:  []interface{}{"UK", "%test%"},
+		},
+	}
+
+	for _, tc := range testCases {
+		query, args := CountQueryBuilder(tc.filter, tc.tableName)
+		assert.Equal(q.T(), tc.expectedQuery, query)
+		assert.Equal(q.T(), tc.expectedArgs, args)
+	}
+}
+
+func (q *QueryBuilderTestSuite) TestPageQueryBuilder() {
+	sort := []entity2.SortKey{{Column: "country", Descending: true}, {Column: "id"}}
+	testCases := []struct {
+		filter        *entity2.Filter
+		sort          []entity2.SortKey
+		cursor        *entity2.Cursor
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			filter:        &entity2.Filter{Countries: []string{"UK"}},
+			sort:          entity2.DefaultSort,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND country IN (?) ORDER BY id LIMIT ?",
+			expectedArgs:  []interface{}{"UK", int64(11)},
+		},
+		{
+			filter:        &entity2.Filter{},
+			sort:          entity2.DefaultSort,
+			cursor:        &entity2.Cursor{Sort: entity2.DefaultSort, Values: []interface{}{int64(20)}},
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND id > ? ORDER BY id LIMIT ?",
+			expectedArgs:  []interface{}{int64(20), int64(11)},
+		},
+		{
+			// the users before the cursor are selected in the reverse order
+			filter:        &entity2.Filter{},
+			sort:          entity2.DefaultSort,
+			cursor:        &entity2.Cursor{Sort: entity2.DefaultSort, Values: []interface{}{int64(20)}, Backward: true},
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND id < ? ORDER BY id DESC LIMIT ?",
+			expectedArgs:  []interface{}{int64(20), int64(11)},
+		},
+		{
+			filter:        &entity2.Filter{NickName: dto.TextFilter{Value: "a", Match: dto.MatchContains}},
+			sort:          sort,
+			cursor:        &entity2.Cursor{Sort: sort, Values: []interface{}{"NL", int64(20)}},
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND nick_name LIKE ? ESCAPE '!' AND (country < ? OR (country = ? AND id > ?)) ORDER BY country DESC, id LIMIT ?",
+			expectedArgs:  []interface{}{"%a%", "NL", "NL", int64(20), int64(11)},
+		},
+		{
+			filter:        &entity2.Filter{},
+			sort:          sort,
+			cursor:        &entity2.Cursor{Sort: sort, Values: []interface{}{"NL", int64(20)}, Backward: true},
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND (country > ? OR (country = ? AND id < ?)) ORDER BY country, id DESC LIMIT ?",
+			expectedArgs:  []interface{}{"NL", "NL", int64(20), int64(11)},
+		},
+	}
+
+	for _, tc := range testCases {
+		query, args := PageQueryBuilder(tc.filter, "users", tc.sort, tc.cursor, 11)
+		assert.Equal(q.T(), tc.expectedQuery, query)
+		assert.Equal(q.T(), tc.expectedArgs, args)
+	}
+}
+
+func (q *QueryBuilderTestSuite) TestIDsQueryBuilder() {
+	testCases := []struct {
+		IDs           []int64
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			IDs:           []int64{3, 1, 2},
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND id IN (?, ?, ?)",
+			expectedArgs:  []interface{}{int64(3), int64(1), int64(2)},
+		},
+		{
+			IDs:           nil,
+			expectedQuery: "SELECT id, first_name, last_name, nick_name, email, country, created_at, updated_at, version FROM users WHERE deleted_at IS NULL AND 1 = 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		query, args := IDsQueryBuilder(tc.IDs, "users")
+		assert.Equal(q.T(), tc.expectedQuery, query)
+		assert.Equal(q.T(), tc.expectedArgs, args)
+	}
+}
+
+func (q *QueryBuilderTestSuite) TestAuditQueryBuilder() {
+	userID, actorID := int64(3), int64(7)
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		filter        *entity2.AuditFilter
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			filter:        &entity2.AuditFilter{},
+			expectedQuery: "SELECT id, user_id, action, actor_id, request_id, source_ip, changes, created_at FROM user_audit_log ORDER BY id DESC LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{int64(10), int64(10)},
+		},
+		{
+			filter:        &entity2.AuditFilter{UserID: &userID},
+			expectedQuery: "SELECT id, user_id, action, actor_id, request_id, source_ip, changes, created_at FROM user_audit_log WHERE user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+			expectedArgs:  []interface{}{userID, int64(10), int64(10)},
+		},
+		{
+			filter: &entity2.AuditFilter{ActorID: &actorID, Actions: []string{"user.updated", "user.deleted"}, CreatedAt: dto.TimeRange{Gte: &from}},
+			expectedQuery: "SELECT id, user_id, action, actor_id, request_id, source_ip, changes, created_at FROM user_audit_log " +
+				"WHERE actor_id = ? AND action IN (?, ?) AND created_at >= ? ORDER BY id DESC LIMIT ? OFFSET ?",
+			expectedArgs: []interface{}{actorID, "user.updated", "user.deleted", from, int64(10), int64(10)},
 		},
 	}
 
 	for _, tc := range testCases {
-		query := CountQueryBuilder(tc.filter, tc.tableName)
+		query, args := AuditQueryBuilder(tc.filter, "user_audit_log", 2, 10)
 		assert.Equal(q.T(), tc.expectedQuery, query)
+		assert.Equal(q.T(), tc.expectedArgs, args)
 	}
 }
 
@@ -97,50 +310,60 @@ func (q *QueryBuilderTestSuite) TestUpdateQueryBuilder() {
 		user          *entity2.User
 		tableName     string
 		expectedQuery string
+		expectedArgs  []interface{}
 	}{
 		{
 			user: &entity2.User{
 				ID:        1,
+				Version:   3,
 				FirstName: "test",
 			},
 			tableName:     "users",
-			expectedQuery: "UPDATE users SET first_name = \"test\" , updated_at = NOW() WHERE id = 1",
+			expectedQuery: "UPDATE users SET first_name = ?, updated_at = NOW(), version = version + 1 WHERE id = ? AND deleted_at IS NULL AND version = ?",
+			expectedArgs:  []interface{}{"test", int64(1), int64(3)},
 		},
 		{
 			user: &entity2.User{
 				ID:        1,
+				Version:   3,
 				FirstName: "test",
 				LastName:  "test",
 			},
 			tableName:     "users",
-			expectedQuery: "UPDATE users SET first_name = \"test\" , last_name = \"test\" , updated_at = NOW() WHERE id = 1",
+			expectedQuery: "UPDATE users SET first_name = ?, last_name = ?, updated_at = NOW(), version = version + 1 WHERE id = ? AND deleted_at IS NULL AND version = ?",
+			expectedArgs:  []interface{}{"test", "test", int64(1), int64(3)},
 		},
 		{
 			user: &entity2.User{
 				ID:        1,
+				Version:   3,
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
 			},
 			tableName:     "users",
-			expectedQuery: "UPDATE users SET first_name = \"test\" , last_name = \"test\" , nick_name = \"test\" , updated_at = NOW() WHERE id = 1",
+			expectedQuery: "UPDATE users SET first_name = ?, last_name = ?, nick_name = ?, updated_at = NOW(), version = version + 1 WHERE id = ? AND deleted_at IS NULL AND version = ?",
+			expectedArgs:  []interface{}{"test", "test", "test", int64(1), int64(3)},
 		},
 		{
 			user: &entity2.User{
 				ID:        1,
+				Version:   3,
 				FirstName: "test",
 				LastName:  "test",
 				NickName:  "test",
 				Country:   "UK",
 			},
 			tableName:     "users",
-			expectedQuery: "UPDATE users SET first_name = \"test\" , last_name = \"test\" , nick_name = \"test\" , country = \"UK\" , updated_at = NOW() WHERE id = 1",
+			expectedQuery: "UPDATE users SET first_name = ?, last_name = ?, nick_name = ?, country = ?, updated_at = NOW(), version = version + 1 WHERE id = ? AND deleted_at IS NULL AND version = ?",
+			expectedArgs:  []interface{}{"test", "test", "test", "UK", int64(1), int64(3)},
 		},
 	}
 
 	for _, tc := range testCases {
-		query := UpdateQueryBuilder(tc.user, tc.tableName)
+		query, args := UpdateQueryBuilder(tc.user, tc.tableName)
 		assert.Equal(q.T(), tc.expectedQuery, query)
+		assert.Equal(q.T(), tc.expectedArgs, args)
 	}
 }
 
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"strings"
+)
+
+// likeEscape - The escape character of the LIKE patterns.
+// A backslash is not used because its meaning in string literals depends on the sql mode of the server.
+const likeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_")
+
+// Condition - A part of the WHERE clause with the arguments of its placeholders
+type Condition struct {
+	sql  string
+	args []interface{}
+}
+
+// Eq - column = value
+func Eq(column string, value interface{}) Condition {
+	return Condition{sql: column + " = ?", args: []interface{}{value}}
+}
+
+// In - column IN (values...), an empty list matches nothing
+func In(column string, values ...interface{}) Condition {
+	if len(values) == 0 {
+		return Condition{sql: "1 = 0"}
+	}
+
+	return Condition{sql: column + " IN (" + placeholders(len(values)) + ")", args: values}
+}
+
+// Gt - column > value
+func Gt(column string, value interface{}) Condition {
+	return Condition{sql: column + " > ?", args: []interface{}{value}}
+}
+
+// Gte - column >= value
+func Gte(column string, value interface{}) Condition {
+	return Condition{sql: column + " >= ?", args: []interface{}{value}}
+}
+
+// Lt - column < value
+func Lt(column string, value interface{}) Condition {
+	return Condition{sql: column + " < ?", args: []interface{}{value}}
+}
+
+// Lte - column <= value
+func Lte(column string, value interface{}) Condition {
+	return Condition{sql: column + " <= ?", args: []interface{}{value}}
+}
+
+// IsNull - column IS NULL
+func IsNull(column string) Condition {
+	return Condition{sql: column + " IS NULL"}
+}
+
+// IsNotNull - column IS NOT NULL
+func IsNotNull(column string) Condition {
+	return Condition{sql: column + " IS NOT NULL"}
+}
+
+// Between - from <= column <= to
+func Between(column string, from, to interface{}) Condition {
+	return Condition{sql: column + " BETWEEN ? AND ?", args: []interface{}{from, to}}
+}
+
+// And - All the conditions must be met
+func And(conditions ...Condition) Condition {
+	return join(conditions, " AND ")
+}
+
+// Or - One of the conditions must be met
+func Or(conditions ...Condition) Condition {
+	return join(conditions, " OR ")
+}
+
+// Contains - The column contains the value, the wildcards in the value are matched literally
+func Contains(column, value string) Condition {
+	return like(column, "%"+EscapeLike(value)+"%")
+}
+
+// HasPrefix - The column starts with the value, the wildcards in the value are matched literally
+func HasPrefix(column, value string) Condition {
+	return like(column, EscapeLike(value)+"%")
+}
+
+// EscapeLike - Escapes the wildcards of the LIKE patterns
+func EscapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+func like(column, pattern string) Condition {
+	return Condition{sql: column + " LIKE ? ESCAPE '" + likeEscape + "'", args: []interface{}{pattern}}
+}
+
+// SelectBuilder - Builds parameterized SELECT queries
+type SelectBuilder struct {
+	table      string
+	columns    []string
+	conditions []Condition
+	orders     []string
+	limit      *int64
+	offset     *int64
+}
+
+func Select(table string, columns ...string) *SelectBuilder {
+	return &SelectBuilder{table: table, columns: columns}
+}
+
+// Where - Adds the conditions to the WHERE clause, all the conditions must be met
+func (b *SelectBuilder) Where(conditions ...Condition) *SelectBuilder {
+	b.conditions = append(b.conditions, conditions...)
+	return b
+}
+
+// OrderBy - Adds the column to the ORDER BY clause.
+// The column is not escaped, so it must never come from the user input.
+func (b *SelectBuilder) OrderBy(column string, descending bool) *SelectBuilder {
+	if descending {
+		column += " DESC"
+	}
+	b.orders = append(b.orders, column)
+	return b
+}
+
+func (b *SelectBuilder) Limit(limit int64) *SelectBuilder {
+	b.limit = &limit
+	return b
+}
+
+func (b *SelectBuilder) Offset(offset int64) *SelectBuilder {
+	b.offset = &offset
+	return b
+}
+
+// Build - Returns the query and the arguments of its placeholders
+func (b *SelectBuilder) Build() (string, []interface{}) {
+	query := "SELECT " + strings.Join(b.columns, ", ") + " FROM " + b.table
+
+	where, args := buildWhere(b.conditions)
+	query += where
+
+	if len(b.orders) > 0 {
+		query += " ORDER BY " + strings.Join(b.orders, ", ")
+	}
+
+	if b.limit != nil {
+		query += " LIMIT ?"
+		args = append(args, *b.limit)
+	}
+
+	if b.offset != nil {
+		query += " OFFSET ?"
+		args = append(args, *b.offset)
+	}
+
+	return query, args
+}
+
+// UpdateBuilder - Builds parameterized UPDATE queries
+type UpdateBuilder struct {
+	table      string
+	sets       []string
+	args       []interface{}
+	conditions []Condition
+}
+
+func Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{table: table}
+}
+
+// Set - Sets the column to the value
+func (b *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
+	b.sets = append(b.sets, column+" = ?")
+	b.args = append(b.args, value)
+	return b
+}
+
+// SetExpression - Sets the column to an sql expression, e.g. NOW().
+// The expression is not escaped, so it must never come from the user input.
+func (b *UpdateBuilder) SetExpression(column, expression string) *UpdateBuilder {
+	b.sets = append(b.sets, column+" = "+expression)
+	return b
+}
+
+// Where - Adds the conditions to the WHERE clause, all the conditions must be met
+func (b *UpdateBuilder) Where(conditions ...Condition) *UpdateBuilder {
+	b.conditions = append(b.conditions, conditions...)
+	return b
+}
+
+// Build - Returns the query and the arguments of its placeholders
+func (b *UpdateBuilder) Build() (string, []interface{}) {
+	query := "UPDATE " + b.table + " SET " + strings.Join(b.sets, ", ")
+
+	where, whereArgs := buildWhere(b.conditions)
+	query += where
+
+	args := make([]interface{}, 0, len(b.args)+len(whereArgs))
+	args = append(args, b.args...)
+	args = append(args, whereArgs...)
+
+	return query, args
+}
+
+func buildWhere(conditions []Condition) (string, []interface{}) {
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	clauses := make([]string, len(conditions))
+	var args []interface{}
+	for i, condition := range conditions {
+		clauses[i] = condition.sql
+		args = append(args, condition.args...)
+	}
+
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func join(conditions []Condition, separator string) Condition {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+
+	clauses := make([]string, len(conditions))
+	var args []interface{}
+	for i, condition := range conditions {
+		clauses[i] = condition.sql
+		args = append(args, condition.args...)
+	}
+
+	return Condition{sql: "(" + strings.Join(clauses, separator) + ")", args: args}
+}
+
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
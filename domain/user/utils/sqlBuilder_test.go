@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SQLBuilderTestSuite struct {
+	suite.Suite
+}
+
+func (s *SQLBuilderTestSuite) TestSelect() {
+	testCases := []struct {
+		builder       *SelectBuilder
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			builder:       Select("users", "id"),
+			expectedQuery: "SELECT id FROM users",
+		},
+		{
+			builder:       Select("users", "id").Where(In("id", int64(1), int64(2), int64(3))),
+			expectedQuery: "SELECT id FROM users WHERE id IN (?, ?, ?)",
+			expectedArgs:  []interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			builder:       Select("users", "id").Where(In("id")),
+			expectedQuery: "SELECT id FROM users WHERE 1 = 0",
+		},
+		{
+			builder:       Select("users", "id").Where(Gte("created_at", "2022-01-01"), Lt("created_at", "2023-01-01")),
+			expectedQuery: "SELECT id FROM users WHERE created_at >= ? AND created_at < ?",
+			expectedArgs:  []interface{}{"2022-01-01", "2023-01-01"},
+		},
+		{
+			builder:       Select("users", "id").Where(Between("id", 1, 10), Gt("id", 2), Lte("id", 9)),
+			expectedQuery: "SELECT id FROM users WHERE id BETWEEN ? AND ? AND id > ? AND id <= ?",
+			expectedArgs:  []interface{}{1, 10, 2, 9},
+		},
+		{
+			builder:       Select("users", "id", "email").Where(HasPrefix("email", "a_b")).OrderBy("created_at", true).OrderBy("id", false).Limit(5),
+			expectedQuery: "SELECT id, email FROM users WHERE email LIKE ? ESCAPE '!' ORDER BY created_at DESC, id LIMIT ?",
+			expectedArgs:  []interface{}{"a!_b%", int64(5)},
+		},
+		{
+			builder:       Select("users", "id").Where(Eq("country", "UK"), Or(Gt("a", 1), And(Eq("a", 1), Lt("id", 2)))),
+			expectedQuery: "SELECT id FROM users WHERE country = ? AND (a > ? OR (a = ? AND id < ?))",
+			expectedArgs:  []interface{}{"UK", 1, 1, 2},
+		},
+		{
+			builder:       Select("users", "id").Where(IsNull("deleted_at"), IsNotNull("email")),
+			expectedQuery: "SELECT id FROM users WHERE deleted_at IS NULL AND email IS NOT NULL",
+		},
+	}
+
+	for _, tc := range testCases {
+		query, args := tc.builder.Build()
+		assert.Equal(s.T(), tc.expectedQuery, query)
+		assert.Equal(s.T(), tc.expectedArgs, args)
+	}
+}
+
+func (s *SQLBuilderTestSuite) TestUpdate() {
+	query, args := Update("users").
+		Set("email", "test@gmail.com").
+		SetExpression("updated_at", "NOW()").
+		Where(Eq("id", int64(1))).
+		Build()
+
+	assert.Equal(s.T(), "UPDATE users SET email = ?, updated_at = NOW() WHERE id = ?", query)
+	assert.Equal(s.T(), []interface{}{"test@gmail.com", int64(1)}, args)
+}
+
+func (s *SQLBuilderTestSuite) TestEscapeLike() {
+	testCases := []struct {
+		value    string
+		expected string
+	}{
+		{value: "test", expected: "test"},
+		{value: "50%", expected: "50!%"},
+		{value: "a_b", expected: "a!_b"},
+		{value: "wow!", expected: "wow!!"},
+		{value: "\\%", expected: "\\!%"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(s.T(), tc.expected, EscapeLike(tc.value))
+	}
+}
+
+func TestSQLBuilderTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLBuilderTestSuite))
+}
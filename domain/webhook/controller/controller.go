@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"faceit/domain/constants"
+	"faceit/domain/webhook/dto"
+	"faceit/domain/webhook/service"
+	"faceit/infrastructure/server"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IWebhookController interface {
+	GetSubscriptions(c *gin.Context)
+	GetSubscription(c *gin.Context)
+	CreateSubscription(c *gin.Context)
+	UpdateSubscription(c *gin.Context)
+	RemoveSubscription(c *gin.Context)
+	GetDeliveries(c *gin.Context)
+	RetryDelivery(c *gin.Context)
+}
+
+type WebhookController struct {
+	service      service.IWebhookService
+	authenticate gin.HandlerFunc
+}
+
+// NewWebhookController - Creates a new webhook controller with dependency injection
+func NewWebhookController(service service.IWebhookService, authenticate gin.HandlerFunc) *WebhookController {
+	return &WebhookController{service: service, authenticate: authenticate}
+}
+
+// RegisterRoutes - Sets up the http routes to manage the webhook subscriptions
+func (w *WebhookController) RegisterRoutes(router *gin.Engine) {
+	webhooks := router.Group("/v1/webhooks", w.authenticate)
+	{
+		webhooks.GET("", w.GetSubscriptions)
+		webhooks.POST("", w.CreateSubscription)
+		webhooks.GET("/:id", w.GetSubscription)
+		webhooks.PUT("/:id", w.UpdateSubscription)
+		webhooks.DELETE("/:id", w.RemoveSubscription)
+		webhooks.GET("/:id/deliveries", w.GetDeliveries)
+		webhooks.POST("/:id/deliveries/:delivery/retry", w.RetryDelivery)
+	}
+}
+
+// GetSubscriptions - Handler to list the webhook subscriptions
+func (w *WebhookController) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := w.service.GetSubscriptions(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, subscriptions)
+}
+
+// GetSubscription - Handler to get a webhook subscription by its ID
+func (w *WebhookController) GetSubscription(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	subscription, err := w.service.GetSubscription(c.Request.Context(), ID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, subscription)
+}
+
+// CreateSubscription - Handler to subscribe an endpoint to the user events, the response contains the secret of the subscription
+func (w *WebhookController) CreateSubscription(c *gin.Context) {
+	var request subscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	subscription, err := w.service.CreateSubscription(c.Request.Context(), subscriptionDTOFromRequest(0, &request))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusCreated, subscription)
+}
+
+// UpdateSubscription - Handler to replace a webhook subscription
+func (w *WebhookController) UpdateSubscription(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var request subscriptionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	subscription, err := w.service.UpdateSubscription(c.Request.Context(), subscriptionDTOFromRequest(ID, &request))
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, subscription)
+}
+
+// RemoveSubscription - Handler to remove a webhook subscription and its deliveries
+func (w *WebhookController) RemoveSubscription(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := w.service.RemoveSubscription(c.Request.Context(), ID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, nil)
+}
+
+// GetDeliveries - Handler to list the latest deliveries of a subscription, filtered by the optional status and limit query parameters
+func (w *WebhookController) GetDeliveries(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	var limit int64
+	if c.Query("limit") != "" {
+		limit, err = strconv.ParseInt(c.Query("limit"), 10, 64)
+		if err != nil {
+			_ = c.Error(constants.NewValidationError("invalid query parameter", constants.FieldError{Field: "limit", Code: "invalid_type", Message: "must be an integer"}))
+			return
+		}
+	}
+
+	deliveries, err := w.service.GetDeliveries(c.Request.Context(), ID, c.Query("status"), limit)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, deliveries)
+}
+
+// RetryDelivery - Handler to put a dead delivery back in the queue
+func (w *WebhookController) RetryDelivery(c *gin.Context) {
+	ID, err := server.ParamInt64(c, "id")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	deliveryID, err := server.ParamInt64(c, "delivery")
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	if err := w.service.RetryDelivery(c.Request.Context(), ID, deliveryID); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	server.Response(c, http.StatusOK, nil)
+}
+
+func subscriptionDTOFromRequest(ID int64, request *subscriptionRequest) *dto.Subscription {
+	active := true
+	if request.Active != nil {
+		active = *request.Active
+	}
+
+	return &dto.Subscription{
+		ID:         ID,
+		URL:        request.URL,
+		EventTypes: request.EventTypes,
+		Secret:     request.Secret,
+		Active:     active,
+	}
+}
@@ -0,0 +1,10 @@
+package controller
+
+type subscriptionRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+	// Secret - Generated on create and kept on update if it's empty
+	Secret string `json:"secret"`
+	// Active - Defaults to true, the deliveries of an inactive subscription wait until it's activated again
+	Active *bool `json:"active"`
+}
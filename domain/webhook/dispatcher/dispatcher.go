@@ -0,0 +1,270 @@
+package dispatcher
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"faceit/domain/webhook/entity"
+	"faceit/domain/webhook/repository"
+	"faceit/infrastructure/database"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// lockName - Only the replica holding this lock posts the deliveries
+const lockName = "webhook_deliveries_dispatcher"
+
+// Config - The tuning of the dispatcher
+type Config struct {
+	// PollInterval - How often the pending deliveries are checked
+	PollInterval time.Duration
+	// BatchSize - The maximum number of deliveries read at once
+	BatchSize int64
+	// Timeout - How long an endpoint has to respond
+	Timeout time.Duration
+	// MaxAttempts - The number of failed attempts after which a delivery is dead
+	MaxAttempts int
+	// MinBackoff - The delay before retrying a delivery which failed for the first time, doubled on each failure
+	MinBackoff time.Duration
+	// MaxBackoff - The longest delay between two attempts of a delivery
+	MaxBackoff time.Duration
+	// Retention - How long the delivered deliveries are kept in the delivery log
+	Retention time.Duration
+	// AllowPrivateNetworks - Lets the endpoints be on loopback, link-local and private addresses, e.g. when the subscribers run in the same network
+	AllowPrivateNetworks bool
+}
+
+// Dispatcher - Posts the pending deliveries to the endpoints of their subscriptions.
+// The events of a user are posted to a subscription in the order they were enqueued, a failed delivery holds back the later ones
+// until it succeeds or dies. The subscriptions are posted to concurrently, so a slow endpoint doesn't hold back the others.
+type Dispatcher struct {
+	db          *sql.DB
+	repository  repository.IDeliveriesRepository
+	client      *http.Client
+	config      Config
+	lastCleanup time.Time
+}
+
+func NewDispatcher(db *sql.DB, repository repository.IDeliveriesRepository, config Config) *Dispatcher {
+	return &Dispatcher{
+		db:         db,
+		repository: repository,
+		client:     newClient(config),
+		config:     config,
+	}
+}
+
+// Run - Posts the deliveries until the context is cancelled.
+// Every replica runs the dispatcher, but only the one holding the database lock posts, the others take over when it stops.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	var lock *database.Lock
+	defer func() {
+		if lock != nil {
+			_ = lock.Release(context.Background())
+		}
+	}()
+
+	for {
+		lock = d.lead(ctx, lock)
+		if lock != nil {
+			if _, err := d.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to dispatch the webhook deliveries: %s", err)
+			}
+			d.cleanup(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead - Tries to take the lock if it's not held, and makes sure the held lock is still alive
+func (d *Dispatcher) lead(ctx context.Context, lock *database.Lock) *database.Lock {
+	if lock != nil {
+		if err := lock.Conn().PingContext(ctx); err == nil {
+			return lock
+		}
+
+		log.Println("lost the webhook dispatcher lock")
+		_ = lock.Release(ctx)
+	}
+
+	lock, err := database.AcquireLock(ctx, d.db, lockName, 0)
+	if err != nil {
+		if !errors.Is(err, database.ErrLockTimeout) && ctx.Err() == nil {
+			log.Printf("failed to acquire the webhook dispatcher lock: %s", err)
+		}
+		return nil
+	}
+
+	log.Println("dispatching the webhook deliveries")
+	return lock
+}
+
+// DispatchPending - Posts one batch of the pending deliveries and returns the number of the delivered ones
+func (d *Dispatcher) DispatchPending(ctx context.Context) (int, error) {
+	deliveries, err := d.repository.GetPendingDeliveries(ctx, time.Now(), d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// the deliveries of a subscription are posted one by one, in order
+	var subscriptionIDs []int64
+	bySubscription := make(map[int64][]*entity.Delivery)
+	for _, delivery := range deliveries {
+		if _, ok := bySubscription[delivery.SubscriptionID]; !ok {
+			subscriptionIDs = append(subscriptionIDs, delivery.SubscriptionID)
+		}
+		bySubscription[delivery.SubscriptionID] = append(bySubscription[delivery.SubscriptionID], delivery)
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	delivered := 0
+	var firstErr error
+	for _, subscriptionID := range subscriptionIDs {
+		wg.Add(1)
+		go func(deliveries []*entity.Delivery) {
+			defer wg.Done()
+
+			count, err := d.dispatchSubscription(ctx, deliveries)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			delivered += count
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}(bySubscription[subscriptionID])
+	}
+	wg.Wait()
+
+	return delivered, firstErr
+}
+
+// dispatchSubscription - Posts the deliveries of one subscription and returns the number of the delivered ones
+func (d *Dispatcher) dispatchSubscription(ctx context.Context, deliveries []*entity.Delivery) (int, error) {
+	now := time.Now()
+	delivered := 0
+	// the users with a delivery which can't be posted yet, their later deliveries must wait for it
+	blocked := make(map[int64]bool)
+	for _, delivery := range deliveries {
+		if blocked[delivery.UserID] {
+			continue
+		}
+
+		if delivery.NextAttemptAt.After(now) {
+			blocked[delivery.UserID] = true
+			continue
+		}
+
+		responseStatus, err := d.post(ctx, delivery)
+		if err != nil {
+			if ctx.Err() != nil {
+				return delivered, ctx.Err()
+			}
+
+			attempts := delivery.Attempts + 1
+			if attempts >= d.config.MaxAttempts {
+				log.Printf("webhook delivery %d of event %s died after %d attempts: %s", delivery.ID, delivery.EventID, attempts, err)
+				if err := d.repository.MarkDead(ctx, delivery.ID, responseStatus, err.Error()); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+
+			blocked[delivery.UserID] = true
+			nextAttemptAt := now.Add(d.backoff(attempts))
+			if err := d.repository.MarkFailed(ctx, delivery.ID, responseStatus, err.Error(), nextAttemptAt); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := d.repository.MarkDelivered(ctx, delivery.ID, responseStatus); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// post - Posts the signed payload of the delivery and returns the response status, any status other than 2xx is a failure
+func (d *Dispatcher) post(ctx context.Context, delivery *entity.Delivery) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := time.Now()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventIDHeader, delivery.EventID)
+	request.Header.Set(EventTypeHeader, delivery.EventType)
+	request.Header.Set(TimestampHeader, fmt.Sprint(timestamp.Unix()))
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+
+	defer func(body io.ReadCloser) {
+		_, _ = io.Copy(io.Discard, io.LimitReader(body, 64*1024))
+		_ = body.Close()
+	}(response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
+
+// backoff - The delay before the given attempt, doubled on each attempt up to the maximum
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.MinBackoff
+	for i := 1; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > d.config.MaxBackoff {
+		delay = d.config.MaxBackoff
+	}
+
+	return delay
+}
+
+// cleanup - Removes the deliveries delivered before the retention period, once an hour.
+// The dead deliveries are kept until their subscription is removed, so they can be retried.
+func (d *Dispatcher) cleanup(ctx context.Context) {
+	if d.config.Retention <= 0 || time.Since(d.lastCleanup) < time.Hour {
+		return
+	}
+
+	limit := d.config.BatchSize * 10
+	count, err := d.repository.DeleteDelivered(ctx, time.Now().Add(-d.config.Retention), limit)
+	if err != nil {
+		log.Printf("failed to clean up the webhook deliveries: %s", err)
+		return
+	}
+
+	// keep cleaning up on the next polls until everything expired is gone
+	if count < limit {
+		d.lastCleanup = time.Now()
+	}
+	if count > 0 {
+		log.Printf("removed %d delivered webhook deliveries", count)
+	}
+}
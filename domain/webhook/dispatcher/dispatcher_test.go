@@ -0,0 +1,243 @@
+package dispatcher
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"faceit/domain/webhook/entity"
+	repositoryMocks "faceit/mocks/domain/webhook/repository"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DispatcherTestSuite struct {
+	suite.Suite
+}
+
+var testConfig = Config{
+	PollInterval: 10 * time.Millisecond,
+	BatchSize:    100,
+	Timeout:      time.Second,
+	MaxAttempts:  3,
+	MinBackoff:   time.Second,
+	MaxBackoff:   time.Minute,
+	// the test endpoints listen on loopback
+	AllowPrivateNetworks: true,
+}
+
+// receiver - An endpoint which records the verified deliveries and responds with the status of their event
+type receiver struct {
+	mutex    sync.Mutex
+	received []string
+	statuses map[string]int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	payload, _ := io.ReadAll(request.Body)
+	err := Verify("secret", request.Header.Get(TimestampHeader), request.Header.Get(SignatureHeader), payload, time.Minute, time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	eventID := request.Header.Get(EventIDHeader)
+	r.mutex.Lock()
+	r.received = append(r.received, eventID)
+	r.mutex.Unlock()
+
+	status, ok := r.statuses[eventID]
+	if !ok {
+		status = http.StatusNoContent
+	}
+	w.WriteHeader(status)
+}
+
+func delivery(id, userID int64, attempts int, nextAttemptAt time.Time, url string) *entity.Delivery {
+	eventID := string(rune('a' + id))
+	return &entity.Delivery{
+		ID:             id,
+		SubscriptionID: 1,
+		EventID:        eventID,
+		EventType:      string(events.TypeUserUpdated),
+		UserID:         userID,
+		Payload:        []byte(`{"id":"` + eventID + `"}`),
+		Status:         entity.DeliveryPending,
+		Attempts:       attempts,
+		NextAttemptAt:  nextAttemptAt,
+		URL:            url,
+		Secret:         "secret",
+	}
+}
+
+func (d *DispatcherTestSuite) TestDispatchPending() {
+	endpoint := &receiver{statuses: map[string]int{"b": http.StatusInternalServerError, "e": http.StatusBadGateway}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	testCases := []struct {
+		deliveries        []*entity.Delivery
+		expectedReceived  []string
+		expectedDelivered []int64
+		expectedFailed    []int64
+		expectedDead      []int64
+	}{
+		{
+			deliveries:        []*entity.Delivery{delivery(1, 1, 0, time.Time{}, server.URL), delivery(2, 2, 0, time.Time{}, server.URL), delivery(3, 1, 0, time.Time{}, server.URL)},
+			expectedReceived:  []string{"b", "c"},
+			expectedDelivered: []int64{2},
+			// the later deliveries of the user wait for the failed one
+			expectedFailed: []int64{1},
+		},
+		{
+			// a delivery which is not due yet holds back the user, the other users are not affected
+			deliveries:        []*entity.Delivery{delivery(2, 1, 1, time.Now().Add(time.Hour), server.URL), delivery(3, 1, 0, time.Time{}, server.URL), delivery(5, 2, 0, time.Time{}, server.URL)},
+			expectedReceived:  []string{"f"},
+			expectedDelivered: []int64{5},
+		},
+		{
+			// the last attempt kills the delivery, which doesn't hold back the user anymore
+			deliveries:        []*entity.Delivery{delivery(4, 1, 2, time.Now().Add(-time.Second), server.URL), delivery(3, 1, 0, time.Time{}, server.URL)},
+			expectedReceived:  []string{"e", "d"},
+			expectedDelivered: []int64{3},
+			expectedDead:      []int64{4},
+		},
+	}
+
+	for _, tc := range testCases {
+		endpoint.received = nil
+		repositoryMock := repositoryMocks.IDeliveriesRepository{}
+		repositoryMock.On("GetPendingDeliveries", mock.Anything, mock.Anything, testConfig.BatchSize).Return(tc.deliveries, nil)
+		for _, id := range tc.expectedDelivered {
+			repositoryMock.On("MarkDelivered", mock.Anything, id, http.StatusNoContent).Return(nil).Once()
+		}
+		for _, id := range tc.expectedFailed {
+			repositoryMock.On("MarkFailed", mock.Anything, id, http.StatusInternalServerError, "webhook responded with status 500", mock.MatchedBy(func(nextAttemptAt time.Time) bool {
+				return nextAttemptAt.After(time.Now())
+			})).Return(nil).Once()
+		}
+		for _, id := range tc.expectedDead {
+			repositoryMock.On("MarkDead", mock.Anything, id, http.StatusBadGateway, "webhook responded with status 502").Return(nil).Once()
+		}
+
+		dispatcher := NewDispatcher(nil, &repositoryMock, testConfig)
+		delivered, err := dispatcher.DispatchPending(context.Background())
+		assert.NoError(d.T(), err)
+		assert.Equal(d.T(), len(tc.expectedDelivered), delivered)
+		assert.Equal(d.T(), tc.expectedReceived, endpoint.received)
+		repositoryMock.AssertExpectations(d.T())
+	}
+}
+
+func (d *DispatcherTestSuite) TestDispatchUnreachable() {
+	server := httptest.NewServer(&receiver{})
+	url := server.URL
+	server.Close()
+
+	repositoryMock := repositoryMocks.IDeliveriesRepository{}
+	repositoryMock.On("GetPendingDeliveries", mock.Anything, mock.Anything, testConfig.BatchSize).Return([]*entity.Delivery{delivery(1, 1, 0, time.Time{}, url)}, nil)
+	// no response was received
+	repositoryMock.On("MarkFailed", mock.Anything, int64(1), 0, mock.Anything, mock.Anything).Return(nil).Once()
+
+	dispatcher := NewDispatcher(nil, &repositoryMock, testConfig)
+	delivered, err := dispatcher.DispatchPending(context.Background())
+	assert.NoError(d.T(), err)
+	assert.Equal(d.T(), 0, delivered)
+	repositoryMock.AssertExpectations(d.T())
+}
+
+func (d *DispatcherTestSuite) TestDispatchSaturated() {
+	endpoint := &receiver{}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	// a dead endpoint has a full batch of deliveries backing off, next to a healthy subscription
+	var pending []*entity.Delivery
+	for i := int64(1); i <= testConfig.BatchSize; i++ {
+		pending = append(pending, delivery(i, i, 1, time.Now().Add(time.Hour), "http://dead.example.com"))
+	}
+	healthy := delivery(testConfig.BatchSize+1, 1, 0, time.Time{}, server.URL)
+	healthy.SubscriptionID = 2
+	pending = append(pending, healthy)
+
+	repositoryMock := repositoryMocks.IDeliveriesRepository{}
+	// the deliveries which are not due are left out of the batch, as the query does
+	repositoryMock.On("GetPendingDeliveries", mock.Anything, mock.Anything, testConfig.BatchSize).Return(func(_ context.Context, now time.Time, limit int64) []*entity.Delivery {
+		var due []*entity.Delivery
+		for _, delivery := range pending {
+			if !delivery.NextAttemptAt.After(now) && int64(len(due)) < limit {
+				due = append(due, delivery)
+			}
+		}
+		return due
+	}, nil)
+	repositoryMock.On("MarkDelivered", mock.Anything, healthy.ID, http.StatusNoContent).Return(nil).Once()
+
+	dispatcher := NewDispatcher(nil, &repositoryMock, testConfig)
+	delivered, err := dispatcher.DispatchPending(context.Background())
+	assert.NoError(d.T(), err)
+	assert.Equal(d.T(), 1, delivered)
+	assert.Equal(d.T(), []string{healthy.EventID}, endpoint.received)
+	repositoryMock.AssertExpectations(d.T())
+}
+
+func (d *DispatcherTestSuite) TestBackoff() {
+	testCases := []struct {
+		attempts      int
+		expectedDelay time.Duration
+	}{
+		{attempts: 1, expectedDelay: time.Second},
+		{attempts: 2, expectedDelay: 2 * time.Second},
+		{attempts: 4, expectedDelay: 8 * time.Second},
+		{attempts: 7, expectedDelay: time.Minute},
+	}
+
+	dispatcher := NewDispatcher(nil, nil, testConfig)
+	for _, tc := range testCases {
+		assert.Equal(d.T(), tc.expectedDelay, dispatcher.backoff(tc.attempts))
+	}
+}
+
+func (d *DispatcherTestSuite) TestRun() {
+	db, sqlMock := databaseMocks.NewDBMock()
+	repositoryMock := repositoryMocks.IDeliveriesRepository{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WithArgs(lockName, int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(1))
+	repositoryMock.On("GetPendingDeliveries", mock.Anything, mock.Anything, testConfig.BatchSize).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(nil, nil).Once()
+	sqlMock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+		WithArgs(lockName).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	dispatcher := NewDispatcher(db, &repositoryMock, testConfig)
+	dispatcher.Run(ctx)
+
+	repositoryMock.AssertExpectations(d.T())
+	assert.NoError(d.T(), sqlMock.ExpectationsWereMet())
+}
+
+func (d *DispatcherTestSuite) TestFanout() {
+	event := &events.Event{ID: "a", Type: events.TypeUserDeleted, UserID: 1}
+	repositoryMock := repositoryMocks.IDeliveriesRepository{}
+	repositoryMock.On("EnqueueDeliveries", mock.Anything, event).Return(int64(2), nil).Once()
+
+	assert.NoError(d.T(), NewFanout(&repositoryMock).Publish(context.Background(), event))
+	repositoryMock.AssertExpectations(d.T())
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
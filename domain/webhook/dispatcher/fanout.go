@@ -0,0 +1,27 @@
+package dispatcher
+
+import (
+	"context"
+	"faceit/domain/user/events"
+	"faceit/domain/webhook/repository"
+)
+
+// Fanout - Enqueues a delivery of every user event for each subscription of its type.
+// It is an events.IEventPublisher, so it's fed by the outbox relay with the same events as the event sink.
+type Fanout struct {
+	repository repository.IDeliveriesRepository
+}
+
+func NewFanout(repository repository.IDeliveriesRepository) *Fanout {
+	return &Fanout{repository: repository}
+}
+
+// Publish - Enqueues the deliveries of the event, publishing the same event again has no effect
+func (f *Fanout) Publish(ctx context.Context, event *events.Event) error {
+	_, err := f.repository.EnqueueDeliveries(ctx, event)
+	return err
+}
+
+func (f *Fanout) Close() error {
+	return nil
+}
@@ -0,0 +1,82 @@
+package dispatcher
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress - The endpoint of a subscription resolved to an address which is not on the public internet
+var ErrPrivateAddress = errors.New("the webhook address is not public")
+
+// sharedAddressSpace - The carrier-grade NAT range, which is not routed on the public internet either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP - Checks that the address is on the public internet, rather than e.g. loopback, link-local like the cloud metadata
+// services on 169.254.169.254, or a private network
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// IsPublicHost - Checks that the host of an endpoint isn't an address or a name which is known not to be public without resolving it.
+// The names are resolved when they are posted to, and the addresses they resolve to are checked then.
+func IsPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return IsPublicIP(ip)
+	}
+
+	return true
+}
+
+// newClient - The client posting the deliveries. The redirects are not followed, and unless the private networks are allowed,
+// the connections are only made to the public addresses. They are checked when connecting, after the names are resolved,
+// so an endpoint can't get around it by resolving to another address later.
+func newClient(config Config) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = publicOnly
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to the endpoints on behalf of the dispatcher, without the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// publicOnly - Refuses to connect to the addresses which are not public
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	return nil
+}
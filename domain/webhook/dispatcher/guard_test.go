@@ -0,0 +1,94 @@
+package dispatcher
+
+import (
+	"context"
+	"faceit/domain/webhook/entity"
+	repositoryMocks "faceit/mocks/domain/webhook/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type GuardTestSuite struct {
+	suite.Suite
+}
+
+func (g *GuardTestSuite) TestIsPublicHost() {
+	testCases := []struct {
+		host     string
+		expected bool
+	}{
+		{host: "billing.example.com", expected: true},
+		{host: "93.184.216.34", expected: true},
+		{host: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{host: "localhost", expected: false},
+		{host: "api.localhost.", expected: false},
+		{host: "127.0.0.1", expected: false},
+		{host: "::1", expected: false},
+		{host: "169.254.169.254", expected: false},
+		{host: "10.0.0.5", expected: false},
+		{host: "172.16.3.4", expected: false},
+		{host: "192.168.1.1", expected: false},
+		{host: "100.64.0.1", expected: false},
+		{host: "fd00::1", expected: false},
+		{host: "0.0.0.0", expected: false},
+		{host: "::ffff:127.0.0.1", expected: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(g.T(), tc.expected, IsPublicHost(tc.host), tc.host)
+	}
+}
+
+func (g *GuardTestSuite) TestPrivateAddress() {
+	endpoint := &receiver{}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	config := testConfig
+	config.AllowPrivateNetworks = false
+
+	// the endpoint resolves to a loopback address
+	repositoryMock := repositoryMocks.IDeliveriesRepository{}
+	repositoryMock.On("GetPendingDeliveries", mock.Anything, mock.Anything, config.BatchSize).Return([]*entity.Delivery{delivery(1, 1, 0, time.Time{}, server.URL)}, nil)
+	repositoryMock.On("MarkFailed", mock.Anything, int64(1), 0, mock.MatchedBy(func(reason string) bool {
+		return strings.Contains(reason, ErrPrivateAddress.Error())
+	}), mock.Anything).Return(nil).Once()
+
+	dispatcher := NewDispatcher(nil, &repositoryMock, config)
+	delivered, err := dispatcher.DispatchPending(context.Background())
+	assert.NoError(g.T(), err)
+	assert.Equal(g.T(), 0, delivered)
+	assert.Empty(g.T(), endpoint.received)
+	repositoryMock.AssertExpectations(g.T())
+}
+
+func (g *GuardTestSuite) TestRedirect() {
+	endpoint := &receiver{}
+	target := httptest.NewServer(endpoint)
+	defer target.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+
+	// the redirect is not followed, it's a failure like any other status than 2xx
+	repositoryMock := repositoryMocks.IDeliveriesRepository{}
+	repositoryMock.On("GetPendingDeliveries", mock.Anything, mock.Anything, testConfig.BatchSize).Return([]*entity.Delivery{delivery(1, 1, 0, time.Time{}, redirect.URL)}, nil)
+	repositoryMock.On("MarkFailed", mock.Anything, int64(1), http.StatusTemporaryRedirect, "webhook responded with status 307", mock.Anything).Return(nil).Once()
+
+	dispatcher := NewDispatcher(nil, &repositoryMock, testConfig)
+	delivered, err := dispatcher.DispatchPending(context.Background())
+	assert.NoError(g.T(), err)
+	assert.Equal(g.T(), 0, delivered)
+	assert.Empty(g.T(), endpoint.received)
+	repositoryMock.AssertExpectations(g.T())
+}
+
+func TestGuardTestSuite(t *testing.T) {
+	suite.Run(t, new(GuardTestSuite))
+}
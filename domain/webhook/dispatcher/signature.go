@@ -0,0 +1,68 @@
+package dispatcher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The headers of the deliveries, the receivers verify the signature with Verify or by following Sign
+const (
+	EventIDHeader   = "X-Event-ID"
+	EventTypeHeader = "X-Event-Type"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+var (
+	ErrInvalidSignature = fmt.Errorf("the webhook signature is invalid")
+	ErrExpiredTimestamp = fmt.Errorf("the webhook timestamp is outside the tolerance")
+)
+
+// Sign - Returns the signature header of the payload sent at the given time: sha256=<hex HMAC-SHA256 of "<unix timestamp>.<payload>">.
+// The timestamp is signed along with the payload, so a captured delivery can't be replayed later with a new timestamp.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	return signaturePrefix + hex.EncodeToString(mac(secret, strconv.FormatInt(timestamp.Unix(), 10), payload))
+}
+
+// Verify - Checks the signature and timestamp headers of a delivery received at now.
+// Deliveries signed more than tolerance apart from now are rejected to prevent replays.
+func Verify(secret, timestamp, signature string, payload []byte, tolerance time.Duration, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-tolerance)) || signedAt.After(now.Add(tolerance)) {
+		return ErrExpiredTimestamp
+	}
+
+	if len(signature) <= len(signaturePrefix) || signature[:len(signaturePrefix)] != signaturePrefix {
+		return ErrInvalidSignature
+	}
+
+	received, err := hex.DecodeString(signature[len(signaturePrefix):])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal(received, mac(secret, timestamp, payload)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func mac(secret, timestamp string, payload []byte) []byte {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write([]byte(timestamp))
+	hash.Write([]byte("."))
+	hash.Write(payload)
+	return hash.Sum(nil)
+}
@@ -0,0 +1,54 @@
+package dispatcher
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SignatureTestSuite struct {
+	suite.Suite
+}
+
+func (s *SignatureTestSuite) TestSign() {
+	// echo -n '1664625600.{"id":"a"}' | openssl dgst -sha256 -hmac secret
+	signature := Sign("secret", time.Unix(1664625600, 0), []byte(`{"id":"a"}`))
+	assert.Equal(s.T(), "sha256=218cc1a058c6b36555d6ef04dfcecd35b86c6873f759dbf5fb43bb24257b582a", signature)
+}
+
+func (s *SignatureTestSuite) TestVerify() {
+	now := time.Now()
+	payload := []byte(`{"id":"a"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("secret", now, payload)
+
+	testCases := []struct {
+		secret        string
+		timestamp     string
+		signature     string
+		payload       []byte
+		expectedError error
+	}{
+		{secret: "secret", timestamp: timestamp, signature: signature, payload: payload, expectedError: nil},
+		{secret: "another secret", timestamp: timestamp, signature: signature, payload: payload, expectedError: ErrInvalidSignature},
+		{secret: "secret", timestamp: timestamp, signature: signature, payload: []byte(`{"id":"b"}`), expectedError: ErrInvalidSignature},
+		{secret: "secret", timestamp: timestamp, signature: "sha256=zz", payload: payload, expectedError: ErrInvalidSignature},
+		{secret: "secret", timestamp: timestamp, signature: signature[len(signaturePrefix):], payload: payload, expectedError: ErrInvalidSignature},
+		{secret: "secret", timestamp: "yesterday", signature: signature, payload: payload, expectedError: ErrInvalidSignature},
+		// the timestamp is signed, so it can't be refreshed
+		{secret: "secret", timestamp: strconv.FormatInt(now.Unix()+1, 10), signature: signature, payload: payload, expectedError: ErrInvalidSignature},
+		{secret: "secret", timestamp: strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), signature: Sign("secret", now.Add(-time.Hour), payload), payload: payload, expectedError: ErrExpiredTimestamp},
+	}
+
+	for _, tc := range testCases {
+		err := Verify(tc.secret, tc.timestamp, tc.signature, tc.payload, 5*time.Minute, now)
+		assert.Equal(s.T(), tc.expectedError, err)
+	}
+}
+
+func TestSignatureTestSuite(t *testing.T) {
+	suite.Run(t, new(SignatureTestSuite))
+}
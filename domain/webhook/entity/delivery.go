@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+)
+
+// The states of a delivery, the pending deliveries are retried until they are delivered or die
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// DeliveryStatuses - All the states of a delivery
+var DeliveryStatuses = []string{DeliveryPending, DeliveryDelivered, DeliveryDead}
+
+// Delivery - A user event to be posted to a subscription
+type Delivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        string
+	EventType      string
+	UserID         int64
+	// Payload - The json encoded events.Event
+	Payload  []byte
+	Status   string
+	Attempts int
+	// LastError and ResponseStatus - The outcome of the last attempt, the status is zero if no response was received
+	LastError      string
+	ResponseStatus int
+	// NextAttemptAt - The delivery is not retried before this time, zero if it has never failed
+	NextAttemptAt time.Time
+	// DeliveredAt - Zero unless it is delivered
+	DeliveredAt time.Time
+	CreatedAt   time.Time
+	// URL and Secret - The endpoint of the subscription, only read with the pending deliveries
+	URL    string
+	Secret string
+}
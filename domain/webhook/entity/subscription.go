@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+)
+
+// Subscription - An HTTP endpoint the user events of the given types are posted to
+type Subscription struct {
+	ID         int64
+	URL        string
+	EventTypes []string
+	// Secret - The key the deliveries are signed with
+	Secret    string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"faceit/domain/constants"
+	"faceit/domain/user/events"
+	"faceit/domain/webhook/entity"
+	"fmt"
+	"time"
+)
+
+// maxLastErrorLength - The size of the last_error column
+const maxLastErrorLength = 1024
+
+type IDeliveriesRepository interface {
+	EnqueueDeliveries(ctx context.Context, event *events.Event) (int64, error)
+	GetPendingDeliveries(ctx context.Context, now time.Time, limit int64) ([]*entity.Delivery, error)
+	GetDeliveries(ctx context.Context, subscriptionID int64, status string, limit int64) ([]*entity.Delivery, error)
+	MarkDelivered(ctx context.Context, ID int64, responseStatus int) error
+	MarkFailed(ctx context.Context, ID int64, responseStatus int, reason string, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, ID int64, responseStatus int, reason string) error
+	RetryDead(ctx context.Context, subscriptionID, ID int64) error
+	DeleteDelivered(ctx context.Context, before time.Time, limit int64) (int64, error)
+}
+
+type DeliveriesRepository struct {
+	db *sql.DB
+}
+
+func NewDeliveriesRepository(db *sql.DB) *DeliveriesRepository {
+	return &DeliveriesRepository{db: db}
+}
+
+// EnqueueDeliveries - creates a delivery of the event for each active subscription of its type and returns how many were created.
+// Enqueueing the same event again has no effect.
+func (d *DeliveriesRepository) EnqueueDeliveries(ctx context.Context, event *events.Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	result, err := d.db.ExecContext(ctx, enqueueDeliveries, event.ID, string(event.Type), event.UserID, payload, string(event.Type))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPendingDeliveries - gets the deliveries of the active subscriptions which are neither delivered nor dead, in the order they were enqueued.
+// The users with a delivery which is not due at the given time are left out until it is.
+func (d *DeliveriesRepository) GetPendingDeliveries(ctx context.Context, now time.Time, limit int64) ([]*entity.Delivery, error) {
+	results, err := d.db.QueryContext(ctx, getPendingDeliveries, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending webhook deliveries: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var deliveries []*entity.Delivery
+	for results.Next() {
+		delivery := &entity.Delivery{Status: entity.DeliveryPending}
+		var nextAttemptAt sql.NullTime
+		if err := results.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.UserID,
+			&delivery.Payload,
+			&delivery.Attempts,
+			&nextAttemptAt,
+			&delivery.URL,
+			&delivery.Secret,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+		delivery.NextAttemptAt = nextAttemptAt.Time
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, results.Err()
+}
+
+// GetDeliveries - gets the latest deliveries of the subscription, optionally only the ones with the given status
+func (d *DeliveriesRepository) GetDeliveries(ctx context.Context, subscriptionID int64, status string, limit int64) ([]*entity.Delivery, error) {
+	query := getDeliveries
+	args := []interface{}{subscriptionID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	results, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	var deliveries []*entity.Delivery
+	for results.Next() {
+		delivery := new(entity.Delivery)
+		var nextAttemptAt, deliveredAt sql.NullTime
+		if err := results.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.UserID,
+			&delivery.Payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.LastError,
+			&delivery.ResponseStatus,
+			&nextAttemptAt,
+			&deliveredAt,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+		delivery.NextAttemptAt = nextAttemptAt.Time
+		delivery.DeliveredAt = deliveredAt.Time
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, results.Err()
+}
+
+// MarkDelivered - marks the delivery as delivered, so it is never posted again
+func (d *DeliveriesRepository) MarkDelivered(ctx context.Context, ID int64, responseStatus int) error {
+	if _, err := d.db.ExecContext(ctx, markDeliveryDelivered, responseStatus, time.Now().UTC(), ID); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed - records the failed attempt and postpones the next one
+func (d *DeliveriesRepository) MarkFailed(ctx context.Context, ID int64, responseStatus int, reason string, nextAttemptAt time.Time) error {
+	if _, err := d.db.ExecContext(ctx, markDeliveryFailed, truncate(reason), responseStatus, nextAttemptAt.UTC(), ID); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDead - records the last failed attempt and stops retrying the delivery
+func (d *DeliveriesRepository) MarkDead(ctx context.Context, ID int64, responseStatus int, reason string) error {
+	if _, err := d.db.ExecContext(ctx, markDeliveryDead, truncate(reason), responseStatus, ID); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery as dead: %w", err)
+	}
+
+	return nil
+}
+
+// RetryDead - puts the dead delivery of the subscription back in the queue with a fresh number of attempts
+func (d *DeliveriesRepository) RetryDead(ctx context.Context, subscriptionID, ID int64) error {
+	result, err := d.db.ExecContext(ctx, retryDeadDelivery, ID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		return constants.ErrDeadDeliveryNotFound
+	}
+
+	return nil
+}
+
+// DeleteDelivered - removes up to limit deliveries delivered before the given time and returns how many were removed
+func (d *DeliveriesRepository) DeleteDelivered(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	result, err := d.db.ExecContext(ctx, deleteDeliveredDeliveries, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered webhook deliveries: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	return count, nil
+}
+
+// truncate - fits the error into the last_error column
+func truncate(reason string) string {
+	if len(reason) > maxLastErrorLength {
+		return reason[:maxLastErrorLength]
+	}
+
+	return reason
+}
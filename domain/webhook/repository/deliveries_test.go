@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"faceit/domain/constants"
+	"faceit/domain/user/events"
+	"faceit/domain/webhook/entity"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeliveriesTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+func (d *DeliveriesTestSuite) TestEnqueueDeliveries() {
+	d.db, d.mock = databaseMocks.NewDBMock()
+	deliveriesRepository := NewDeliveriesRepository(d.db)
+
+	event := &events.Event{ID: "5f0c6a1e-8d4b-4c1f-9a57-2b8f1e0d3c44", Type: events.TypeUserCreated, UserID: 1}
+	payload, _ := json.Marshal(event)
+	d.mock.ExpectExec(regexp.QuoteMeta("INSERT IGNORE INTO webhook_deliveries (subscription_id, event_id, event_type, user_id, payload) SELECT s.id, ?, ?, ?, ? FROM webhook_subscriptions s")).
+		WithArgs(event.ID, "user.created", int64(1), payload, "user.created").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	count, err := deliveriesRepository.EnqueueDeliveries(context.Background(), event)
+	assert.Nil(d.T(), err)
+	assert.Equal(d.T(), int64(2), count)
+	assert.Nil(d.T(), d.mock.ExpectationsWereMet())
+}
+
+func (d *DeliveriesTestSuite) TestGetPendingDeliveries() {
+	d.db, d.mock = databaseMocks.NewDBMock()
+	deliveriesRepository := NewDeliveriesRepository(d.db)
+
+	nextAttemptAt := time.Now().Add(-time.Minute)
+	rows := d.mock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "user_id", "payload", "attempts", "next_attempt_at", "url", "secret"}).
+		AddRow(1, 1, "a", "user.created", 1, []byte(`{"id":"a"}`), 0, nil, "https://billing.example.com/hooks", "secret-1").
+		AddRow(2, 2, "a", "user.created", 1, []byte(`{"id":"a"}`), 3, nextAttemptAt, "https://crm.example.com/hooks", "secret-2")
+	now := time.Now()
+	// the users with a delivery which is not due are left out, so they can't fill the batch
+	notDue := regexp.QuoteMeta("AND NOT EXISTS (SELECT 1 FROM webhook_deliveries b WHERE b.subscription_id = d.subscription_id AND b.user_id = d.user_id AND b.status = 'pending' AND b.id <= d.id AND b.next_attempt_at > ?) ORDER BY d.id LIMIT ?")
+	d.mock.ExpectQuery("SELECT d.id, d.subscription_id, d.event_id, d.event_type, d.user_id, d.payload, d.attempts, d.next_attempt_at, s.url, s.secret FROM webhook_deliveries d .*"+notDue).
+		WithArgs(now.UTC(), int64(100)).
+		WillReturnRows(rows)
+
+	deliveries, err := deliveriesRepository.GetPendingDeliveries(context.Background(), now, 100)
+	assert.Nil(d.T(), err)
+	assert.Equal(d.T(), []*entity.Delivery{
+		{ID: 1, SubscriptionID: 1, EventID: "a", EventType: "user.created", UserID: 1, Payload: []byte(`{"id":"a"}`), Status: entity.DeliveryPending, URL: "https://billing.example.com/hooks", Secret: "secret-1"},
+		{ID: 2, SubscriptionID: 2, EventID: "a", EventType: "user.created", UserID: 1, Payload: []byte(`{"id":"a"}`), Status: entity.DeliveryPending, Attempts: 3, NextAttemptAt: nextAttemptAt, URL: "https://crm.example.com/hooks", Secret: "secret-2"},
+	}, deliveries)
+}
+
+func (d *DeliveriesTestSuite) TestGetDeliveries() {
+	testCases := []struct {
+		status        string
+		expectedQuery string
+	}{
+		{status: "", expectedQuery: "WHERE subscription_id = ? ORDER BY id DESC LIMIT ?"},
+		{status: entity.DeliveryDead, expectedQuery: "WHERE subscription_id = ? AND status = ? ORDER BY id DESC LIMIT ?"},
+	}
+
+	d.db, d.mock = databaseMocks.NewDBMock()
+	deliveriesRepository := NewDeliveriesRepository(d.db)
+
+	createdAt := time.Now()
+	for _, tc := range testCases {
+		rows := d.mock.NewRows([]string{"id", "subscription_id", "event_id", "event_type", "user_id", "payload", "status", "attempts", "last_error", "response_status", "next_attempt_at", "delivered_at", "created_at"}).
+			AddRow(1, 1, "a", "user.created", 1, []byte(`{"id":"a"}`), "dead", 10, "webhook responded with status 500", 500, createdAt, nil, createdAt)
+		query := d.mock.ExpectQuery(regexp.QuoteMeta(tc.expectedQuery))
+		if tc.status == "" {
+			query.WithArgs(int64(1), int64(50))
+		} else {
+			query.WithArgs(int64(1), tc.status, int64(50))
+		}
+		query.WillReturnRows(rows)
+
+		deliveries, err := deliveriesRepository.GetDeliveries(context.Background(), 1, tc.status, 50)
+		assert.Nil(d.T(), err)
+		assert.Equal(d.T(), []*entity.Delivery{{
+			ID: 1, SubscriptionID: 1, EventID: "a", EventType: "user.created", UserID: 1, Payload: []byte(`{"id":"a"}`),
+			Status: "dead", Attempts: 10, LastError: "webhook responded with status 500", ResponseStatus: 500,
+			NextAttemptAt: createdAt, CreatedAt: createdAt,
+		}}, deliveries)
+	}
+	assert.Nil(d.T(), d.mock.ExpectationsWereMet())
+}
+
+func (d *DeliveriesTestSuite) TestMarkDeliveries() {
+	d.db, d.mock = databaseMocks.NewDBMock()
+	deliveriesRepository := NewDeliveriesRepository(d.db)
+
+	nextAttemptAt := time.Now().Add(time.Minute)
+	d.mock.ExpectExec("UPDATE webhook_deliveries SET status = 'delivered'").
+		WithArgs(200, sqlmock.AnyArg(), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the error is cut to the size of the column
+	d.mock.ExpectExec("UPDATE webhook_deliveries SET attempts = attempts \\+ 1").
+		WithArgs(strings.Repeat("e", maxLastErrorLength), 503, nextAttemptAt.UTC(), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.mock.ExpectExec("UPDATE webhook_deliveries SET status = 'dead'").
+		WithArgs("connection refused", 0, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Nil(d.T(), deliveriesRepository.MarkDelivered(context.Background(), 1, 200))
+	assert.Nil(d.T(), deliveriesRepository.MarkFailed(context.Background(), 2, 503, strings.Repeat("e", maxLastErrorLength+10), nextAttemptAt))
+	assert.Nil(d.T(), deliveriesRepository.MarkDead(context.Background(), 3, 0, "connection refused"))
+	assert.Nil(d.T(), d.mock.ExpectationsWereMet())
+}
+
+func (d *DeliveriesTestSuite) TestRetryDead() {
+	testCases := []struct {
+		rowsAffected  int64
+		expectedError error
+	}{
+		{rowsAffected: 1, expectedError: nil},
+		// the delivery is unknown, belongs to another subscription or is not dead
+		{rowsAffected: 0, expectedError: constants.ErrDeadDeliveryNotFound},
+	}
+
+	d.db, d.mock = databaseMocks.NewDBMock()
+	deliveriesRepository := NewDeliveriesRepository(d.db)
+
+	for _, tc := range testCases {
+		d.mock.ExpectExec("UPDATE webhook_deliveries SET status = 'pending'").
+			WithArgs(int64(2), int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+		err := deliveriesRepository.RetryDead(context.Background(), 1, 2)
+		assert.Equal(d.T(), tc.expectedError, err)
+	}
+	assert.Nil(d.T(), d.mock.ExpectationsWereMet())
+}
+
+func (d *DeliveriesTestSuite) TestDeleteDelivered() {
+	d.db, d.mock = databaseMocks.NewDBMock()
+	deliveriesRepository := NewDeliveriesRepository(d.db)
+
+	before := time.Now()
+	d.mock.ExpectExec("DELETE FROM webhook_deliveries WHERE status = 'delivered'").
+		WithArgs(before.UTC(), int64(1000)).
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	count, err := deliveriesRepository.DeleteDelivered(context.Background(), before, 1000)
+	assert.Nil(d.T(), err)
+	assert.Equal(d.T(), int64(7), count)
+	assert.Nil(d.T(), d.mock.ExpectationsWereMet())
+}
+
+func TestDeliveriesTestSuite(t *testing.T) {
+	suite.Run(t, new(DeliveriesTestSuite))
+}
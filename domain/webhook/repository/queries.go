@@ -0,0 +1,45 @@
+package repository
+
+const (
+	subscriptionsTableName = "webhook_subscriptions"
+	eventTypesTableName    = "webhook_subscription_event_types"
+	deliveriesTableName    = "webhook_deliveries"
+)
+
+const (
+	getSubscriptions = `SELECT s.id, s.url, s.secret, s.active, s.created_at, s.updated_at, t.event_type FROM ` + subscriptionsTableName + ` s LEFT JOIN ` + eventTypesTableName + ` t ON t.subscription_id = s.id ORDER BY s.id, t.event_type`
+
+	getSubscription = `SELECT s.id, s.url, s.secret, s.active, s.created_at, s.updated_at, t.event_type FROM ` + subscriptionsTableName + ` s LEFT JOIN ` + eventTypesTableName + ` t ON t.subscription_id = s.id WHERE s.id = ? ORDER BY t.event_type`
+
+	createSubscription = `INSERT INTO ` + subscriptionsTableName + ` SET url = ?, secret = ?, active = ?`
+
+	updateSubscription = `UPDATE ` + subscriptionsTableName + ` SET url = ?, secret = ?, active = ?, updated_at = NOW() WHERE id = ?`
+
+	deleteSubscription = `DELETE FROM ` + subscriptionsTableName + ` WHERE id = ?`
+
+	createSubscriptionEventType = `INSERT INTO ` + eventTypesTableName + ` SET subscription_id = ?, event_type = ?`
+
+	deleteSubscriptionEventTypes = `DELETE FROM ` + eventTypesTableName + ` WHERE subscription_id = ?`
+)
+
+const (
+	// enqueueDeliveries - one delivery for each active subscription of the event type, an event is never enqueued twice for a subscription
+	enqueueDeliveries = `INSERT IGNORE INTO ` + deliveriesTableName + ` (subscription_id, event_id, event_type, user_id, payload) SELECT s.id, ?, ?, ?, ? FROM ` + subscriptionsTableName + ` s JOIN ` + eventTypesTableName + ` t ON t.subscription_id = s.id WHERE s.active = 1 AND t.event_type = ?`
+
+	// getPendingDeliveries - the deliveries of the inactive subscriptions wait until they are activated again.
+	// A delivery which is not due yet is skipped with the later deliveries of its user, which must wait for it,
+	// so the deliveries backing off never fill the batch and hold back the other subscriptions.
+	getPendingDeliveries = `SELECT d.id, d.subscription_id, d.event_id, d.event_type, d.user_id, d.payload, d.attempts, d.next_attempt_at, s.url, s.secret FROM ` + deliveriesTableName + ` d JOIN ` + subscriptionsTableName + ` s ON s.id = d.subscription_id WHERE d.status = 'pending' AND s.active = 1 AND NOT EXISTS (SELECT 1 FROM ` + deliveriesTableName + ` b WHERE b.subscription_id = d.subscription_id AND b.user_id = d.user_id AND b.status = 'pending' AND b.id <= d.id AND b.next_attempt_at > ?) ORDER BY d.id LIMIT ?`
+
+	getDeliveries = `SELECT id, subscription_id, event_id, event_type, user_id, payload, status, attempts, last_error, response_status, next_attempt_at, delivered_at, created_at FROM ` + deliveriesTableName + ` WHERE subscription_id = ?`
+
+	markDeliveryDelivered = `UPDATE ` + deliveriesTableName + ` SET status = 'delivered', attempts = attempts + 1, last_error = '', response_status = ?, delivered_at = ? WHERE id = ?`
+
+	markDeliveryFailed = `UPDATE ` + deliveriesTableName + ` SET attempts = attempts + 1, last_error = ?, response_status = ?, next_attempt_at = ? WHERE id = ?`
+
+	markDeliveryDead = `UPDATE ` + deliveriesTableName + ` SET status = 'dead', attempts = attempts + 1, last_error = ?, response_status = ? WHERE id = ?`
+
+	retryDeadDelivery = `UPDATE ` + deliveriesTableName + ` SET status = 'pending', attempts = 0, next_attempt_at = NULL WHERE id = ? AND subscription_id = ? AND status = 'dead'`
+
+	deleteDeliveredDeliveries = `DELETE FROM ` + deliveriesTableName + ` WHERE status = 'delivered' AND delivered_at < ? LIMIT ?`
+)
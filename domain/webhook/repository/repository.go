@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"faceit/domain/constants"
+	"faceit/domain/webhook/entity"
+	"fmt"
+)
+
+type ISubscriptionsRepository interface {
+	GetSubscriptions(ctx context.Context) ([]*entity.Subscription, error)
+	GetSubscription(ctx context.Context, ID int64) (*entity.Subscription, error)
+	CreateSubscription(ctx context.Context, subscription *entity.Subscription) error
+	UpdateSubscription(ctx context.Context, subscription *entity.Subscription) error
+	RemoveSubscription(ctx context.Context, ID int64) error
+}
+
+type SubscriptionsRepository struct {
+	db *sql.DB
+}
+
+func NewSubscriptionsRepository(db *sql.DB) *SubscriptionsRepository {
+	return &SubscriptionsRepository{db: db}
+}
+
+// GetSubscriptions - gets all the subscriptions with their event types
+func (s *SubscriptionsRepository) GetSubscriptions(ctx context.Context) ([]*entity.Subscription, error) {
+	results, err := s.db.QueryContext(ctx, getSubscriptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	return scanSubscriptions(results)
+}
+
+// GetSubscription - gets the subscription with the given ID and its event types
+func (s *SubscriptionsRepository) GetSubscription(ctx context.Context, ID int64) (*entity.Subscription, error) {
+	results, err := s.db.QueryContext(ctx, getSubscription, ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	defer func(results *sql.Rows) {
+		_ = results.Close()
+	}(results)
+
+	subscriptions, err := scanSubscriptions(results)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, constants.ErrWebhookNotFound
+	}
+
+	return subscriptions[0], nil
+}
+
+// CreateSubscription - creates the subscription with its event types and sets its ID
+func (s *SubscriptionsRepository) CreateSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	result, err := tx.ExecContext(ctx, createSubscription, subscription.URL, subscription.Secret, subscription.Active)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	subscription.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last inserted ID: %w", err)
+	}
+
+	if err := writeEventTypes(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSubscription - replaces the subscription and its event types
+func (s *SubscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func(tx *sql.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	result, err := tx.ExecContext(ctx, updateSubscription, subscription.URL, subscription.Secret, subscription.Active, subscription.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		return constants.ErrWebhookNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteSubscriptionEventTypes, subscription.ID); err != nil {
+		return fmt.Errorf("failed to remove event types of webhook subscription: %w", err)
+	}
+
+	if err := writeEventTypes(ctx, tx, subscription); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSubscription - removes the subscription, its event types and deliveries are removed with it
+func (s *SubscriptionsRepository) RemoveSubscription(ctx context.Context, ID int64) error {
+	result, err := s.db.ExecContext(ctx, deleteSubscription, ID)
+	if err != nil {
+		return fmt.Errorf("failed to remove webhook subscription: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get number of rows affected: %w", err)
+	}
+
+	if count == 0 {
+		return constants.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// writeEventTypes - writes the event types of the subscription within the transaction which writes the subscription
+func writeEventTypes(ctx context.Context, tx *sql.Tx, subscription *entity.Subscription) error {
+	for _, eventType := range subscription.EventTypes {
+		if _, err := tx.ExecContext(ctx, createSubscriptionEventType, subscription.ID, eventType); err != nil {
+			return fmt.Errorf("failed to subscribe to event type: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scanSubscriptions - groups the rows of the subscriptions joined with their event types by subscription
+func scanSubscriptions(results *sql.Rows) ([]*entity.Subscription, error) {
+	var subscriptions []*entity.Subscription
+	for results.Next() {
+		subscription := new(entity.Subscription)
+		var eventType sql.NullString
+		if err := results.Scan(
+			&subscription.ID,
+			&subscription.URL,
+			&subscription.Secret,
+			&subscription.Active,
+			&subscription.CreatedAt,
+			&subscription.UpdatedAt,
+			&eventType,
+		); err != nil {
+			return nil, fmt.Errorf("failed to read records from database: %w", err)
+		}
+
+		if len(subscriptions) == 0 || subscriptions[len(subscriptions)-1].ID != subscription.ID {
+			subscriptions = append(subscriptions, subscription)
+		}
+
+		if eventType.Valid {
+			subscription := subscriptions[len(subscriptions)-1]
+			subscription.EventTypes = append(subscription.EventTypes, eventType.String)
+		}
+	}
+
+	return subscriptions, results.Err()
+}
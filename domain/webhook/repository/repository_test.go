@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"faceit/domain/constants"
+	"faceit/domain/webhook/entity"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepositoryTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+var subscriptionColumns = []string{"id", "url", "secret", "active", "created_at", "updated_at", "event_type"}
+
+func (r *RepositoryTestSuite) TestGetSubscriptions() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	subscriptionsRepository := NewSubscriptionsRepository(r.db)
+
+	now := time.Now()
+	rows := r.mock.NewRows(subscriptionColumns).
+		AddRow(1, "https://billing.example.com/hooks", "secret-1", true, now, now, "user.created").
+		AddRow(1, "https://billing.example.com/hooks", "secret-1", true, now, now, "user.deleted").
+		AddRow(2, "https://crm.example.com/hooks", "secret-2", false, now, now, nil)
+	r.mock.ExpectQuery("SELECT s.id, s.url, s.secret, s.active, s.created_at, s.updated_at, t.event_type FROM webhook_subscriptions s LEFT JOIN webhook_subscription_event_types t").
+		WillReturnRows(rows)
+
+	subscriptions, err := subscriptionsRepository.GetSubscriptions(context.Background())
+	assert.Nil(r.T(), err)
+	assert.Equal(r.T(), []*entity.Subscription{
+		{ID: 1, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created", "user.deleted"}, Secret: "secret-1", Active: true, CreatedAt: now, UpdatedAt: now},
+		{ID: 2, URL: "https://crm.example.com/hooks", Secret: "secret-2", Active: false, CreatedAt: now, UpdatedAt: now},
+	}, subscriptions)
+}
+
+func (r *RepositoryTestSuite) TestGetSubscription() {
+	now := time.Now()
+	testCases := []struct {
+		ID                   int64
+		rows                 *sqlmock.Rows
+		expectedSubscription *entity.Subscription
+		expectedError        error
+	}{
+		{
+			ID:                   1,
+			rows:                 sqlmock.NewRows(subscriptionColumns).AddRow(1, "https://billing.example.com/hooks", "secret-1", true, now, now, "user.created"),
+			expectedSubscription: &entity.Subscription{ID: 1, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created"}, Secret: "secret-1", Active: true, CreatedAt: now, UpdatedAt: now},
+			expectedError:        nil,
+		},
+		{
+			ID:                   2,
+			rows:                 sqlmock.NewRows(subscriptionColumns),
+			expectedSubscription: nil,
+			expectedError:        constants.ErrWebhookNotFound,
+		},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	subscriptionsRepository := NewSubscriptionsRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectQuery("SELECT s.id, s.url, s.secret, s.active, s.created_at, s.updated_at, t.event_type FROM webhook_subscriptions s").
+			WithArgs(tc.ID).
+			WillReturnRows(tc.rows)
+		subscription, err := subscriptionsRepository.GetSubscription(context.Background(), tc.ID)
+		assert.Equal(r.T(), tc.expectedError, err)
+		assert.Equal(r.T(), tc.expectedSubscription, subscription)
+	}
+}
+
+func (r *RepositoryTestSuite) TestCreateSubscription() {
+	r.db, r.mock = databaseMocks.NewDBMock()
+	subscriptionsRepository := NewSubscriptionsRepository(r.db)
+
+	subscription := &entity.Subscription{URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created", "user.deleted"}, Secret: "secret-1", Active: true}
+	r.mock.ExpectBegin()
+	r.mock.ExpectExec("INSERT INTO webhook_subscriptions").
+		WithArgs(subscription.URL, subscription.Secret, subscription.Active).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	for _, eventType := range subscription.EventTypes {
+		r.mock.ExpectExec("INSERT INTO webhook_subscription_event_types").
+			WithArgs(int64(3), eventType).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	r.mock.ExpectCommit()
+
+	err := subscriptionsRepository.CreateSubscription(context.Background(), subscription)
+	assert.Nil(r.T(), err)
+	assert.Equal(r.T(), int64(3), subscription.ID)
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func (r *RepositoryTestSuite) TestUpdateSubscription() {
+	testCases := []struct {
+		subscription  *entity.Subscription
+		rowsAffected  int64
+		expectedError error
+	}{
+		{
+			subscription:  &entity.Subscription{ID: 1, URL: "https://billing.example.com/v2/hooks", EventTypes: []string{"user.updated"}, Secret: "secret-1", Active: false},
+			rowsAffected:  1,
+			expectedError: nil,
+		},
+		{
+			subscription:  &entity.Subscription{ID: 2, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.updated"}, Secret: "secret-1"},
+			rowsAffected:  0,
+			expectedError: constants.ErrWebhookNotFound,
+		},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	subscriptionsRepository := NewSubscriptionsRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectBegin()
+		r.mock.ExpectExec("UPDATE webhook_subscriptions SET url = \\?, secret = \\?, active = \\?").
+			WithArgs(tc.subscription.URL, tc.subscription.Secret, tc.subscription.Active, tc.subscription.ID).
+			WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+		if tc.expectedError == nil {
+			// the event types are replaced
+			r.mock.ExpectExec("DELETE FROM webhook_subscription_event_types").
+				WithArgs(tc.subscription.ID).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			r.mock.ExpectExec("INSERT INTO webhook_subscription_event_types").
+				WithArgs(tc.subscription.ID, "user.updated").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			r.mock.ExpectCommit()
+		} else {
+			r.mock.ExpectRollback()
+		}
+
+		err := subscriptionsRepository.UpdateSubscription(context.Background(), tc.subscription)
+		assert.Equal(r.T(), tc.expectedError, err)
+	}
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func (r *RepositoryTestSuite) TestRemoveSubscription() {
+	testCases := []struct {
+		ID            int64
+		rowsAffected  int64
+		expectedError error
+	}{
+		{ID: 1, rowsAffected: 1, expectedError: nil},
+		{ID: 2, rowsAffected: 0, expectedError: constants.ErrWebhookNotFound},
+	}
+
+	r.db, r.mock = databaseMocks.NewDBMock()
+	subscriptionsRepository := NewSubscriptionsRepository(r.db)
+
+	for _, tc := range testCases {
+		r.mock.ExpectExec("DELETE FROM webhook_subscriptions").
+			WithArgs(tc.ID).
+			WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+		err := subscriptionsRepository.RemoveSubscription(context.Background(), tc.ID)
+		assert.Equal(r.T(), tc.expectedError, err)
+	}
+	assert.Nil(r.T(), r.mock.ExpectationsWereMet())
+}
+
+func TestRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
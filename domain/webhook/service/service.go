@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"faceit/domain/constants"
+	rbacEntity "faceit/domain/rbac/entity"
+	rbacService "faceit/domain/rbac/service"
+	"faceit/domain/user/events"
+	"faceit/domain/webhook/dispatcher"
+	"faceit/domain/webhook/dto"
+	"faceit/domain/webhook/entity"
+	"faceit/domain/webhook/repository"
+	"faceit/domain/webhook/utils"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// minSecretLength - The secrets shorter than this are rejected, the generated ones are longer
+	minSecretLength = 16
+	// maxDeliveriesLimit - The most deliveries returned from the delivery log at once
+	maxDeliveriesLimit = 500
+)
+
+type IWebhookService interface {
+	GetSubscriptions(ctx context.Context) ([]*dto.Subscription, error)
+	GetSubscription(ctx context.Context, ID int64) (*dto.Subscription, error)
+	CreateSubscription(ctx context.Context, subscription *dto.Subscription) (*dto.Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *dto.Subscription) (*dto.Subscription, error)
+	RemoveSubscription(ctx context.Context, ID int64) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, status string, limit int64) ([]*dto.Delivery, error)
+	RetryDelivery(ctx context.Context, subscriptionID, deliveryID int64) error
+}
+
+type WebhookService struct {
+	subscriptions repository.ISubscriptionsRepository
+	deliveries    repository.IDeliveriesRepository
+	authorizer    rbacService.IAuthorizer
+	// allowPrivateNetworks - The urls can be on loopback, link-local and private addresses, the dispatcher must allow them too
+	allowPrivateNetworks bool
+}
+
+func NewWebhookService(subscriptions repository.ISubscriptionsRepository, deliveries repository.IDeliveriesRepository, authorizer rbacService.IAuthorizer, allowPrivateNetworks bool) *WebhookService {
+	return &WebhookService{subscriptions: subscriptions, deliveries: deliveries, authorizer: authorizer, allowPrivateNetworks: allowPrivateNetworks}
+}
+
+func (w *WebhookService) GetSubscriptions(ctx context.Context) ([]*dto.Subscription, error) {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return nil, err
+	}
+
+	subscriptionEntities, err := w.subscriptions.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionDTOs := make([]*dto.Subscription, len(subscriptionEntities))
+	for i, subscriptionEntity := range subscriptionEntities {
+		subscriptionDTOs[i] = utils.SubscriptionDTOFromEntity(subscriptionEntity)
+	}
+
+	return subscriptionDTOs, nil
+}
+
+func (w *WebhookService) GetSubscription(ctx context.Context, ID int64) (*dto.Subscription, error) {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return nil, err
+	}
+
+	subscriptionEntity, err := w.subscriptions.GetSubscription(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.SubscriptionDTOFromEntity(subscriptionEntity), nil
+}
+
+// CreateSubscription - Creates the subscription and returns it with its secret, a secret is generated if none is given
+func (w *WebhookService) CreateSubscription(ctx context.Context, subscription *dto.Subscription) (*dto.Subscription, error) {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return nil, err
+	}
+
+	if err := w.validate(subscription); err != nil {
+		return nil, err
+	}
+
+	subscriptionEntity := utils.SubscriptionEntityFromDTO(subscription)
+	subscriptionEntity.EventTypes = uniqueEventTypes(subscription.EventTypes)
+	if subscriptionEntity.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscriptionEntity.Secret = secret
+	}
+
+	if err := w.subscriptions.CreateSubscription(ctx, subscriptionEntity); err != nil {
+		return nil, err
+	}
+
+	// the timestamps are set by the database
+	createdSubscription, err := w.subscriptions.GetSubscription(ctx, subscriptionEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	createdSubscriptionDTO := utils.SubscriptionDTOFromEntity(createdSubscription)
+	createdSubscriptionDTO.Secret = createdSubscription.Secret
+	return createdSubscriptionDTO, nil
+}
+
+// UpdateSubscription - Replaces the subscription, the secret is kept unless a new one is given
+func (w *WebhookService) UpdateSubscription(ctx context.Context, subscription *dto.Subscription) (*dto.Subscription, error) {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return nil, err
+	}
+
+	if err := w.validate(subscription); err != nil {
+		return nil, err
+	}
+
+	foundSubscription, err := w.subscriptions.GetSubscription(ctx, subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptionEntity := utils.SubscriptionEntityFromDTO(subscription)
+	subscriptionEntity.EventTypes = uniqueEventTypes(subscription.EventTypes)
+	if subscriptionEntity.Secret == "" {
+		subscriptionEntity.Secret = foundSubscription.Secret
+	}
+
+	if err := w.subscriptions.UpdateSubscription(ctx, subscriptionEntity); err != nil {
+		return nil, err
+	}
+
+	updatedSubscription, err := w.subscriptions.GetSubscription(ctx, subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedSubscriptionDTO := utils.SubscriptionDTOFromEntity(updatedSubscription)
+	updatedSubscriptionDTO.Secret = subscription.Secret
+	return updatedSubscriptionDTO, nil
+}
+
+// RemoveSubscription - Removes the subscription along with its delivery log
+func (w *WebhookService) RemoveSubscription(ctx context.Context, ID int64) error {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return err
+	}
+
+	return w.subscriptions.RemoveSubscription(ctx, ID)
+}
+
+// GetDeliveries - Returns the latest deliveries of the subscription, the status is optional
+func (w *WebhookService) GetDeliveries(ctx context.Context, subscriptionID int64, status string, limit int64) ([]*dto.Delivery, error) {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return nil, err
+	}
+
+	if status != "" && !isDeliveryStatus(status) {
+		return nil, constants.ErrInvalidDeliveryStatus
+	}
+
+	if limit <= 0 || limit > maxDeliveriesLimit {
+		limit = maxDeliveriesLimit
+	}
+
+	// tell an unknown subscription apart from one without deliveries
+	if _, err := w.subscriptions.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveryEntities, err := w.deliveries.GetDeliveries(ctx, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryDTOs := make([]*dto.Delivery, len(deliveryEntities))
+	for i, deliveryEntity := range deliveryEntities {
+		deliveryDTOs[i] = utils.DeliveryDTOFromEntity(deliveryEntity)
+	}
+
+	return deliveryDTOs, nil
+}
+
+// RetryDelivery - Puts a dead delivery back in the queue
+func (w *WebhookService) RetryDelivery(ctx context.Context, subscriptionID, deliveryID int64) error {
+	if err := w.authorizer.Authorize(ctx, rbacEntity.PermissionWebhooksManage); err != nil {
+		return err
+	}
+
+	return w.deliveries.RetryDead(ctx, subscriptionID, deliveryID)
+}
+
+// validate - Checks the url, the event types and the secret of the subscription.
+// The hosts which are known not to be public are rejected here, the dispatcher checks the addresses the names resolve to when it posts.
+func (w *WebhookService) validate(subscription *dto.Subscription) error {
+	endpoint, err := url.Parse(subscription.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return constants.ErrInvalidWebhookURL
+	}
+
+	if !w.allowPrivateNetworks && !dispatcher.IsPublicHost(endpoint.Hostname()) {
+		return constants.ErrPrivateWebhookURL
+	}
+
+	if len(subscription.EventTypes) == 0 {
+		return constants.ErrInvalidEventType
+	}
+
+	for _, eventType := range subscription.EventTypes {
+		if !events.Type(eventType).IsValid() {
+			return constants.ErrInvalidEventType
+		}
+	}
+
+	if subscription.Secret != "" && len(subscription.Secret) < minSecretLength {
+		return constants.ErrWebhookSecretTooShort
+	}
+
+	return nil
+}
+
+// uniqueEventTypes - Removes the repeated event types, keeping their order
+func uniqueEventTypes(eventTypes []string) []string {
+	seen := make(map[string]bool, len(eventTypes))
+	unique := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !seen[eventType] {
+			seen[eventType] = true
+			unique = append(unique, eventType)
+		}
+	}
+
+	return unique
+}
+
+func isDeliveryStatus(status string) bool {
+	for _, deliveryStatus := range entity.DeliveryStatuses {
+		if status == deliveryStatus {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateSecret - Returns a random secret of 32 bytes
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	return hex.EncodeToString(secret), nil
+}
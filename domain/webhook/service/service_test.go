@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"faceit/domain/constants"
+	rbacEntity "faceit/domain/rbac/entity"
+	"faceit/domain/webhook/dto"
+	"faceit/domain/webhook/entity"
+	rbacMocks "faceit/mocks/domain/rbac/service"
+	mocks "faceit/mocks/domain/webhook/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTestSuite struct {
+	suite.Suite
+}
+
+func allowAll() *rbacMocks.IAuthorizer {
+	authorizerMock := rbacMocks.IAuthorizer{}
+	authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionWebhooksManage).Return(nil)
+	return &authorizerMock
+}
+
+func (s *ServiceTestSuite) TestAuthorization() {
+	authorizerMock := rbacMocks.IAuthorizer{}
+	authorizerMock.On("Authorize", mock.Anything, rbacEntity.PermissionWebhooksManage).Return(constants.ErrForbidden)
+
+	webhookService := NewWebhookService(&mocks.ISubscriptionsRepository{}, &mocks.IDeliveriesRepository{}, &authorizerMock, false)
+	_, err := webhookService.GetSubscriptions(context.Background())
+	assert.Equal(s.T(), constants.ErrForbidden, err)
+	_, err = webhookService.CreateSubscription(context.Background(), &dto.Subscription{URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created"}})
+	assert.Equal(s.T(), constants.ErrForbidden, err)
+	_, err = webhookService.GetDeliveries(context.Background(), 1, "", 0)
+	assert.Equal(s.T(), constants.ErrForbidden, err)
+	assert.Equal(s.T(), constants.ErrForbidden, webhookService.RetryDelivery(context.Background(), 1, 1))
+}
+
+func (s *ServiceTestSuite) TestCreateSubscription() {
+	testCases := []struct {
+		subscription         *dto.Subscription
+		allowPrivateNetworks bool
+		expectedEventTypes   []string
+		expectedSecret       string
+		expectedError        error
+	}{
+		{
+			subscription:       &dto.Subscription{URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created", "user.deleted", "user.created"}, Active: true},
+			expectedEventTypes: []string{"user.created", "user.deleted"},
+			expectedError:      nil,
+		},
+		{
+			subscription:       &dto.Subscription{URL: "http://crm.internal:8080/hooks", EventTypes: []string{"user.updated"}, Secret: "a-secret-of-the-partner", Active: true},
+			expectedEventTypes: []string{"user.updated"},
+			expectedSecret:     "a-secret-of-the-partner",
+			expectedError:      nil,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "ftp://billing.example.com/hooks", EventTypes: []string{"user.created"}},
+			expectedError: constants.ErrInvalidWebhookURL,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "/hooks", EventTypes: []string{"user.created"}},
+			expectedError: constants.ErrInvalidWebhookURL,
+		},
+		{
+			// the cloud metadata service
+			subscription:  &dto.Subscription{URL: "http://169.254.169.254/latest/meta-data", EventTypes: []string{"user.created"}},
+			expectedError: constants.ErrPrivateWebhookURL,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "http://localhost:8080/hooks", EventTypes: []string{"user.created"}},
+			expectedError: constants.ErrPrivateWebhookURL,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "http://[::1]/hooks", EventTypes: []string{"user.created"}},
+			expectedError: constants.ErrPrivateWebhookURL,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "https://10.0.0.5/hooks", EventTypes: []string{"user.created"}},
+			expectedError: constants.ErrPrivateWebhookURL,
+		},
+		{
+			subscription:         &dto.Subscription{URL: "https://10.0.0.5/hooks", EventTypes: []string{"user.created"}},
+			allowPrivateNetworks: true,
+			expectedEventTypes:   []string{"user.created"},
+			expectedError:        nil,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "https://billing.example.com/hooks", EventTypes: []string{"user.renamed"}},
+			expectedError: constants.ErrInvalidEventType,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "https://billing.example.com/hooks"},
+			expectedError: constants.ErrInvalidEventType,
+		},
+		{
+			subscription:  &dto.Subscription{URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created"}, Secret: "short"},
+			expectedError: constants.ErrWebhookSecretTooShort,
+		},
+	}
+
+	for _, tc := range testCases {
+		subscriptionsMock := mocks.ISubscriptionsRepository{}
+		var created *entity.Subscription
+		subscriptionsMock.On("CreateSubscription", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(1).(*entity.Subscription)
+			created.ID = 1
+		}).Return(nil)
+		subscriptionsMock.On("GetSubscription", mock.Anything, int64(1)).Return(func(context.Context, int64) *entity.Subscription {
+			created.CreatedAt = time.Now()
+			return created
+		}, nil)
+
+		webhookService := NewWebhookService(&subscriptionsMock, &mocks.IDeliveriesRepository{}, allowAll(), tc.allowPrivateNetworks)
+		subscription, err := webhookService.CreateSubscription(context.Background(), tc.subscription)
+		assert.Equal(s.T(), tc.expectedError, err)
+		if tc.expectedError != nil {
+			assert.Nil(s.T(), subscription)
+			subscriptionsMock.AssertNotCalled(s.T(), "CreateSubscription", mock.Anything, mock.Anything)
+			continue
+		}
+
+		assert.Equal(s.T(), int64(1), subscription.ID)
+		assert.Equal(s.T(), tc.expectedEventTypes, subscription.EventTypes)
+		assert.Equal(s.T(), created.Secret, subscription.Secret)
+		if tc.expectedSecret != "" {
+			assert.Equal(s.T(), tc.expectedSecret, subscription.Secret)
+		} else {
+			// a secret is generated
+			assert.Len(s.T(), subscription.Secret, 64)
+		}
+	}
+}
+
+func (s *ServiceTestSuite) TestUpdateSubscription() {
+	found := &entity.Subscription{ID: 1, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created"}, Secret: "the-current-secret", Active: true}
+	testCases := []struct {
+		subscription   *dto.Subscription
+		expectedSecret string
+		expectedError  error
+	}{
+		{
+			// the secret is kept and not returned
+			subscription:   &dto.Subscription{ID: 1, URL: "https://billing.example.com/v2/hooks", EventTypes: []string{"user.updated"}},
+			expectedSecret: "the-current-secret",
+			expectedError:  nil,
+		},
+		{
+			subscription:   &dto.Subscription{ID: 1, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.updated"}, Secret: "the-rotated-secret"},
+			expectedSecret: "the-rotated-secret",
+			expectedError:  nil,
+		},
+		{
+			subscription:  &dto.Subscription{ID: 2, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.updated"}},
+			expectedError: constants.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		subscriptionsMock := mocks.ISubscriptionsRepository{}
+		subscriptionsMock.On("GetSubscription", mock.Anything, int64(1)).Return(found, nil)
+		subscriptionsMock.On("GetSubscription", mock.Anything, int64(2)).Return(nil, constants.ErrWebhookNotFound)
+		subscriptionsMock.On("UpdateSubscription", mock.Anything, mock.MatchedBy(func(subscription *entity.Subscription) bool {
+			return subscription.Secret == tc.expectedSecret && subscription.URL == tc.subscription.URL
+		})).Return(nil)
+
+		webhookService := NewWebhookService(&subscriptionsMock, &mocks.IDeliveriesRepository{}, allowAll(), false)
+		subscription, err := webhookService.UpdateSubscription(context.Background(), tc.subscription)
+		assert.Equal(s.T(), tc.expectedError, err)
+		if tc.expectedError == nil {
+			assert.Equal(s.T(), tc.subscription.Secret, subscription.Secret)
+			subscriptionsMock.AssertCalled(s.T(), "UpdateSubscription", mock.Anything, mock.Anything)
+		}
+	}
+}
+
+func (s *ServiceTestSuite) TestGetSubscriptions() {
+	subscriptionsMock := mocks.ISubscriptionsRepository{}
+	subscriptionsMock.On("GetSubscriptions", mock.Anything).Return([]*entity.Subscription{
+		{ID: 1, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created"}, Secret: "the-current-secret", Active: true},
+	}, nil)
+
+	webhookService := NewWebhookService(&subscriptionsMock, &mocks.IDeliveriesRepository{}, allowAll(), false)
+	subscriptions, err := webhookService.GetSubscriptions(context.Background())
+	assert.Nil(s.T(), err)
+	// the secrets are never listed
+	assert.Equal(s.T(), []*dto.Subscription{
+		{ID: 1, URL: "https://billing.example.com/hooks", EventTypes: []string{"user.created"}, Active: true},
+	}, subscriptions)
+}
+
+func (s *ServiceTestSuite) TestGetDeliveries() {
+	testCases := []struct {
+		subscriptionID int64
+		status         string
+		limit          int64
+		expectedLimit  int64
+		expectedError  error
+	}{
+		{subscriptionID: 1, status: "", limit: 20, expectedLimit: 20, expectedError: nil},
+		{subscriptionID: 1, status: entity.DeliveryDead, limit: 0, expectedLimit: maxDeliveriesLimit, expectedError: nil},
+		{subscriptionID: 1, status: "", limit: 10000, expectedLimit: maxDeliveriesLimit, expectedError: nil},
+		{subscriptionID: 1, status: "lost", expectedError: constants.ErrInvalidDeliveryStatus},
+		{subscriptionID: 2, status: "", expectedError: constants.ErrWebhookNotFound},
+	}
+
+	for _, tc := range testCases {
+		subscriptionsMock := mocks.ISubscriptionsRepository{}
+		deliveriesMock := mocks.IDeliveriesRepository{}
+		subscriptionsMock.On("GetSubscription", mock.Anything, int64(1)).Return(&entity.Subscription{ID: 1}, nil)
+		subscriptionsMock.On("GetSubscription", mock.Anything, int64(2)).Return(nil, constants.ErrWebhookNotFound)
+		deliveriesMock.On("GetDeliveries", mock.Anything, tc.subscriptionID, tc.status, tc.expectedLimit).Return([]*entity.Delivery{
+			{ID: 1, SubscriptionID: 1, EventID: "a", Status: entity.DeliveryDelivered, DeliveredAt: time.Now()},
+		}, nil)
+
+		webhookService := NewWebhookService(&subscriptionsMock, &deliveriesMock, allowAll(), false)
+		deliveries, err := webhookService.GetDeliveries(context.Background(), tc.subscriptionID, tc.status, tc.limit)
+		assert.Equal(s.T(), tc.expectedError, err)
+		if tc.expectedError == nil {
+			assert.Len(s.T(), deliveries, 1)
+			assert.NotNil(s.T(), deliveries[0].DeliveredAt)
+			assert.Nil(s.T(), deliveries[0].NextAttemptAt)
+		}
+	}
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
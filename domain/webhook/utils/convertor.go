@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"faceit/domain/webhook/dto"
+	"faceit/domain/webhook/entity"
+	"time"
+)
+
+// SubscriptionDTOFromEntity - Converts the subscription without its secret
+func SubscriptionDTOFromEntity(entity *entity.Subscription) *dto.Subscription {
+	return &dto.Subscription{
+		ID:         entity.ID,
+		URL:        entity.URL,
+		EventTypes: entity.EventTypes,
+		Active:     entity.Active,
+		CreatedAt:  entity.CreatedAt,
+		UpdatedAt:  entity.UpdatedAt,
+	}
+}
+
+func SubscriptionEntityFromDTO(dto *dto.Subscription) *entity.Subscription {
+	return &entity.Subscription{
+		ID:         dto.ID,
+		URL:        dto.URL,
+		EventTypes: dto.EventTypes,
+		Secret:     dto.Secret,
+		Active:     dto.Active,
+	}
+}
+
+func DeliveryDTOFromEntity(entity *entity.Delivery) *dto.Delivery {
+	return &dto.Delivery{
+		ID:             entity.ID,
+		SubscriptionID: entity.SubscriptionID,
+		EventID:        entity.EventID,
+		EventType:      entity.EventType,
+		UserID:         entity.UserID,
+		Payload:        entity.Payload,
+		Status:         entity.Status,
+		Attempts:       entity.Attempts,
+		LastError:      entity.LastError,
+		ResponseStatus: entity.ResponseStatus,
+		NextAttemptAt:  optionalTime(entity.NextAttemptAt),
+		DeliveredAt:    optionalTime(entity.DeliveredAt),
+		CreatedAt:      entity.CreatedAt,
+	}
+}
+
+// optionalTime - Leaves the zero times out of the responses
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
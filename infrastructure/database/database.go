@@ -1,25 +1,16 @@
 package database
 
 import (
-	"context"
 	"database/sql"
-	_ "embed"
 	"fmt"
 	"log"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
-//go:embed migration/schema.up.sql
-var schemaUp string
-
-//go:embed migration/schema.down.sql
-var schemaDown string
-
 // IDatabase - The interface for the database driver
 type IDatabase interface {
 	Ping() error
-	Migrate(cmd string) error
 	Close() error
 }
 
@@ -49,21 +40,6 @@ func NewDatabase(dbUser, dbPassword, dbHost, dbPort, dbName, dbDriver string) (*
 	}, nil
 }
 
-// Migrate - Creates the tables if we pass `up` as the argument.
-// Removes the tables if we pass `down` as the argument.
-func (s *Database) Migrate(cmd string) error {
-	switch cmd {
-	case "up":
-		_, err := s.db.ExecContext(context.Background(), schemaUp)
-		return err
-	case "down":
-		_, err := s.db.ExecContext(context.Background(), schemaDown)
-		return err
-	default:
-		return fmt.Errorf("unknown command")
-	}
-}
-
 // Ping - Checks database health
 func (s *Database) Ping() error {
 	return s.db.Ping()
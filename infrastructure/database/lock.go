@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ErrLockTimeout - The lock is held by another session for longer than the timeout
+var ErrLockTimeout = fmt.Errorf("timed out waiting for the database lock")
+
+// Lock - A MySQL named lock held by a dedicated connection.
+// The lock is released by MySQL if the connection dies, so a crashed instance never keeps it.
+type Lock struct {
+	conn *sql.Conn
+	name string
+}
+
+// AcquireLock - Waits up to the timeout for the named lock and holds it on a dedicated connection
+func AcquireLock(ctx context.Context, db *sql.DB, name string, timeout time.Duration) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a connection for the lock: %w", err)
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, int64(timeout.Seconds())).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to acquire the lock %s: %w", name, err)
+	}
+
+	if acquired.Int64 != 1 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w: %s", ErrLockTimeout, name)
+	}
+
+	return &Lock{conn: conn, name: name}, nil
+}
+
+// Conn - The connection which holds the lock
+func (l *Lock) Conn() *sql.Conn {
+	return l.conn
+}
+
+// Release - Releases the lock and returns the connection to the pool
+func (l *Lock) Release(ctx context.Context) error {
+	defer func(conn *sql.Conn) {
+		_ = conn.Close()
+	}(l.conn)
+
+	if _, err := l.conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", l.name); err != nil {
+		return fmt.Errorf("failed to release the lock %s: %w", l.name, err)
+	}
+
+	return nil
+}
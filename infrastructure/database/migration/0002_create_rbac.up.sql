@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(32) NOT NULL PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT current_timestamp
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(32) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role, permission),
+    FOREIGN KEY (role) REFERENCES roles(name) ON DELETE CASCADE,
+    FOREIGN KEY (permission) REFERENCES permissions(name) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id INT(32) NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    PRIMARY KEY (user_id, role),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (role) REFERENCES roles(name) ON DELETE CASCADE
+);
+
+-- the permissions checked by the services
+INSERT IGNORE INTO permissions (name, description) VALUES
+    ('users:read', 'List the users'),
+    ('users:read:pii', 'See the names and emails of the users'),
+    ('users:update', 'Update the other users'),
+    ('users:delete', 'Remove the other users'),
+    ('roles:manage', 'Manage the roles and assign them to the users');
+
+-- built-in roles, more roles can be created through the API
+INSERT IGNORE INTO roles (name, description) VALUES
+    ('admin', 'Manages everyone'),
+    ('support', 'Supports the users without managing the roles');
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:read'),
+    ('admin', 'users:read:pii'),
+    ('admin', 'users:update'),
+    ('admin', 'users:delete'),
+    ('admin', 'roles:manage'),
+    ('support', 'users:read'),
+    ('support', 'users:update');
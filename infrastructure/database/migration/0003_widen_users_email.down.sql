@@ -0,0 +1 @@
+ALTER TABLE users MODIFY email VARCHAR(32) NOT NULL;
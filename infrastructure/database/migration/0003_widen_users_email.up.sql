@@ -0,0 +1,2 @@
+-- RFC 5321 allows addresses much longer than the original VARCHAR(32)
+ALTER TABLE users MODIFY email VARCHAR(255) NOT NULL;
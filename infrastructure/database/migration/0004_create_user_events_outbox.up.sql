@@ -0,0 +1,15 @@
+-- the events are written in the same transaction as the change of the user and relayed to the event sink afterwards
+CREATE TABLE IF NOT EXISTS user_events_outbox (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    event_id CHAR(36) NOT NULL,
+    user_id INT(32) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    next_attempt_at DATETIME(6) NULL,
+    delivered_at DATETIME(6) NULL,
+    created_at TIMESTAMP DEFAULT current_timestamp,
+    UNIQUE KEY user_events_outbox_event_id (event_id),
+    KEY user_events_outbox_pending (delivered_at, id)
+);
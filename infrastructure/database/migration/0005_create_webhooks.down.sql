@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscription_event_types;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DELETE FROM permissions WHERE name = 'webhooks:manage';
@@ -0,0 +1,44 @@
+-- the partner services subscribe to the user events with webhooks
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    -- kept in plaintext, the deliveries are signed with it
+    secret VARCHAR(255) NOT NULL,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT current_timestamp,
+    updated_at TIMESTAMP DEFAULT current_timestamp
+);
+
+CREATE TABLE IF NOT EXISTS webhook_subscription_event_types (
+    subscription_id BIGINT NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    PRIMARY KEY (subscription_id, event_type),
+    KEY webhook_subscription_event_types_event_type (event_type),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+);
+
+-- every user event is fanned out to a delivery per matching subscription, which is posted until it succeeds or dies
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    user_id INT(32) NOT NULL,
+    payload JSON NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    response_status INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(6) NULL,
+    delivered_at DATETIME(6) NULL,
+    created_at TIMESTAMP DEFAULT current_timestamp,
+    UNIQUE KEY webhook_deliveries_event (subscription_id, event_id),
+    KEY webhook_deliveries_status (status, id),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO permissions (name, description) VALUES
+    ('webhooks:manage', 'Manage the webhook subscriptions and see their deliveries');
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+    ('admin', 'webhooks:manage');
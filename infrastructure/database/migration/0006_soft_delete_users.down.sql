@@ -0,0 +1,6 @@
+-- the removed users would come back without the column
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+ALTER TABLE users
+    DROP KEY users_deleted_at,
+    DROP COLUMN deleted_at;
+DELETE FROM permissions WHERE name = 'users:restore';
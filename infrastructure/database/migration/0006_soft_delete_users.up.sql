@@ -0,0 +1,10 @@
+-- the removed users are kept until the purger deletes them after the retention period, so they can be restored
+ALTER TABLE users
+    ADD COLUMN deleted_at DATETIME(6) NULL,
+    ADD KEY users_deleted_at (deleted_at);
+
+INSERT IGNORE INTO permissions (name, description) VALUES
+    ('users:restore', 'List the removed users and restore them');
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:restore');
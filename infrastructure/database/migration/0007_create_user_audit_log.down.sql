@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_audit_log;
+DELETE FROM permissions WHERE name = 'users:audit';
@@ -0,0 +1,24 @@
+-- every change of a user is recorded with who made it and where the request came from, in the same transaction as the change
+CREATE TABLE IF NOT EXISTS user_audit_log (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id INT(32) NOT NULL,
+    -- the type of the event of the change, e.g. user.updated
+    action VARCHAR(32) NOT NULL,
+    -- NULL when no user made the change, e.g. a sign up or a purge
+    actor_id INT(32) NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    source_ip VARCHAR(45) NOT NULL DEFAULT '',
+    -- the before and after values of the changed fields, the secrets are redacted
+    changes JSON NULL,
+    created_at DATETIME(6) NOT NULL,
+    KEY user_audit_log_user (user_id, id),
+    KEY user_audit_log_actor (actor_id, id),
+    KEY user_audit_log_created_at (created_at)
+);
+
+INSERT IGNORE INTO permissions (name, description) VALUES
+    ('users:audit', 'Read the change history of the users and the audit log');
+
+INSERT IGNORE INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:audit'),
+    ('support', 'users:audit');
@@ -0,0 +1,3 @@
+-- every write of a user increments its version, so the updates based on an older version are rejected
+ALTER TABLE users
+    ADD COLUMN version BIGINT UNSIGNED NOT NULL DEFAULT 1;
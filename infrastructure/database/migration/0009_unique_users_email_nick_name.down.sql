@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP KEY users_live_email,
+    DROP KEY users_live_nick_name,
+    DROP COLUMN live_email,
+    DROP COLUMN live_nick_name;
@@ -0,0 +1,8 @@
+-- the email and the nickname are unique among the users which are not removed, a removed user keeps them until it's restored or purged.
+-- The generated columns are NULL for the removed users, and the unique keys allow any number of NULLs.
+-- The migration fails if the users already have duplicates, they have to be resolved first.
+ALTER TABLE users
+    ADD COLUMN live_email VARCHAR(255) AS (IF(deleted_at IS NULL, email, NULL)) STORED,
+    ADD COLUMN live_nick_name VARCHAR(32) AS (IF(deleted_at IS NULL, nick_name, NULL)) STORED,
+    ADD UNIQUE KEY users_live_email (live_email),
+    ADD UNIQUE KEY users_live_nick_name (live_nick_name);
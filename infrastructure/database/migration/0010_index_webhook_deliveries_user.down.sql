@@ -0,0 +1,2 @@
+ALTER TABLE webhook_deliveries
+    DROP KEY webhook_deliveries_user;
@@ -0,0 +1,3 @@
+-- the pending deliveries of a user which are held back by one backing off are found by this key
+ALTER TABLE webhook_deliveries
+    ADD KEY webhook_deliveries_user (subscription_id, user_id, status, next_attempt_at);
@@ -0,0 +1,5 @@
+ALTER TABLE user_events_outbox
+    DROP KEY user_events_outbox_user,
+    DROP KEY user_events_outbox_pending,
+    ADD KEY user_events_outbox_pending (delivered_at, id),
+    DROP COLUMN dead_at;
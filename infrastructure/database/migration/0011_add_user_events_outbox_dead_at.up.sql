@@ -0,0 +1,6 @@
+-- the events which fail max_attempts times are dead, so they don't hold back the later events of their user forever
+ALTER TABLE user_events_outbox
+    ADD COLUMN dead_at DATETIME(6) NULL AFTER delivered_at,
+    DROP KEY user_events_outbox_pending,
+    ADD KEY user_events_outbox_pending (delivered_at, dead_at, id),
+    ADD KEY user_events_outbox_user (user_id, delivered_at, dead_at, next_attempt_at);
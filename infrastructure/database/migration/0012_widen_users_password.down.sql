@@ -0,0 +1 @@
+ALTER TABLE users MODIFY password VARCHAR(32) NOT NULL;
@@ -0,0 +1,2 @@
+-- passwords are stored as encoded hashes which don't fit in the original VARCHAR(32)
+ALTER TABLE users MODIFY password VARCHAR(255) NOT NULL;
@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Files - The migrations shipped with the binary.
+// A migration is a pair of <version>_<name>.up.sql and <version>_<name>.down.sql files,
+// applied files must never be edited, a new version has to be added instead.
+//
+//go:embed *.sql
+var Files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration - One version of the schema
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Load - Reads the migrations from the sql files in the root of the source, ordered by version
+func Load(source fs.FS) ([]*Migration, error) {
+	fileNames, err := fs.Glob(source, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, fileName := range fileNames {
+		matches := fileNamePattern.FindStringSubmatch(path.Base(fileName))
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", fileName)
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", fileName)
+		}
+
+		content, err := fs.ReadFile(source, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the migration %s: %w", fileName, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
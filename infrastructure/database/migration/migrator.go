@@ -0,0 +1,268 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"faceit/infrastructure/database"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"time"
+)
+
+const (
+	lockName    = "schema_migrations"
+	lockTimeout = time.Minute
+)
+
+var (
+	ErrDatabaseAhead     = fmt.Errorf("the database has migrations unknown to this binary")
+	ErrChecksumMismatch  = fmt.Errorf("an applied migration was modified")
+	ErrPendingMigrations = fmt.Errorf("the database has pending migrations")
+	ErrIrreversible      = fmt.Errorf("the migration has no down file")
+)
+
+// IMigrator - Manages the versions of the database schema
+type IMigrator interface {
+	Status(ctx context.Context) ([]*Status, error)
+	Up(ctx context.Context) error
+	Down(ctx context.Context, version int64) error
+	Check(ctx context.Context) error
+}
+
+// Status - The state of a migration in the database
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified - The migration was changed after it had been applied
+	Modified bool
+	// Unknown - The migration is applied but doesn't exist in this binary
+	Unknown bool
+}
+
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Migrator - Applies the migrations while holding a database lock, so only one instance migrates at a time
+type Migrator struct {
+	db         *sql.DB
+	migrations []*Migration
+}
+
+func NewMigrator(db *sql.DB, source fs.FS) (*Migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Status - Lists the known and the applied migrations ordered by version
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	if _, err := m.db.ExecContext(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("failed to create the migrations table: %w", err)
+	}
+
+	applied, err := getApplied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*Status
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = record.checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		appliedAt := record.appliedAt
+		statuses = append(statuses, &Status{
+			Version:   record.version,
+			Name:      record.name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Up - Applies all the pending migrations in order
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[int64]*appliedMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			log.Printf("applying migration %d_%s", migration.Version, migration.Name)
+			// MySQL commits DDL statements implicitly, so a failed migration can't be rolled back
+			if _, err := conn.ExecContext(ctx, migration.Up); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s, the schema may be partially migrated: %w", migration.Version, migration.Name, err)
+			}
+
+			if _, err := conn.ExecContext(ctx, insertMigration, migration.Version, migration.Name, migration.Checksum); err != nil {
+				return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down - Reverts the applied migrations newer than the version, the newest first
+func (m *Migrator) Down(ctx context.Context, version int64) error {
+	if version < 0 {
+		return fmt.Errorf("invalid target version: %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[int64]*appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version <= version {
+				break
+			}
+
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("%w: %d_%s", ErrIrreversible, migration.Version, migration.Name)
+			}
+
+			log.Printf("reverting migration %d_%s", migration.Version, migration.Name)
+			if _, err := conn.ExecContext(ctx, migration.Down); err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s, the schema may be partially reverted: %w", migration.Version, migration.Name, err)
+			}
+
+			if _, err := conn.ExecContext(ctx, deleteMigration, migration.Version); err != nil {
+				return fmt.Errorf("failed to remove the record of migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Check - Makes sure the database schema is exactly the one this binary was built for
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			return fmt.Errorf("%w: version %d", ErrDatabaseAhead, status.Version)
+		case status.Modified:
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, status.Version, status.Name)
+		case !status.Applied:
+			return fmt.Errorf("%w: %d_%s", ErrPendingMigrations, status.Version, status.Name)
+		}
+	}
+
+	return nil
+}
+
+// withLock - Runs the migration function on the connection holding the lock
+// after making sure the applied migrations match the ones of this binary
+func (m *Migrator) withLock(ctx context.Context, migrate func(conn *sql.Conn, applied map[int64]*appliedMigration) error) (err error) {
+	lock, err := database.AcquireLock(ctx, m.db, lockName, lockTimeout)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if releaseErr := lock.Release(ctx); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	conn := lock.Conn()
+	if _, err := conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create the migrations table: %w", err)
+	}
+
+	applied, err := getApplied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+
+	return migrate(conn, applied)
+}
+
+// verify - Refuses to touch a database migrated by a newer binary or with modified migrations
+func (m *Migrator) verify(applied map[int64]*appliedMigration) error {
+	known := make(map[int64]*Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for version, record := range applied {
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: version %d", ErrDatabaseAhead, version)
+		}
+
+		if migration.Checksum != record.checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func getApplied(ctx context.Context, q querier) (map[int64]*appliedMigration, error) {
+	rows, err := q.QueryContext(ctx, getAppliedMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the applied migrations: %w", err)
+	}
+
+	defer func(rows *sql.Rows) {
+		_ = rows.Close()
+	}(rows)
+
+	applied := make(map[int64]*appliedMigration)
+	for rows.Next() {
+		record := new(appliedMigration)
+		if err := rows.Scan(&record.version, &record.name, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+		}
+		applied[record.version] = record
+	}
+
+	return applied, rows.Err()
+}
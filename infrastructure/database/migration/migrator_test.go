@@ -0,0 +1,278 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	databaseMocks "faceit/mocks/infrastructure/database"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MigratorTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+}
+
+var testFiles = fstest.MapFS{
+	"0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT);")},
+	"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	"0002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD email VARCHAR(255);")},
+	"0002_add_email.down.sql":    {Data: []byte("ALTER TABLE users DROP email;")},
+}
+
+func (m *MigratorTestSuite) TestLoad() {
+	testCases := []struct {
+		source           fstest.MapFS
+		expectedVersions []int64
+		expectedError    bool
+	}{
+		{
+			source:           testFiles,
+			expectedVersions: []int64{1, 2},
+		},
+		{
+			source: fstest.MapFS{
+				"0002_b.up.sql": {Data: []byte("SELECT 2;")},
+				"0010_c.up.sql": {Data: []byte("SELECT 10;")},
+				"0001_a.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			expectedVersions: []int64{1, 2, 10},
+		},
+		{
+			source: fstest.MapFS{
+				"create_users.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			expectedError: true,
+		},
+		{
+			source: fstest.MapFS{
+				"0001_a.up.sql": {Data: []byte("SELECT 1;")},
+				"0001_b.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			expectedError: true,
+		},
+		{
+			source: fstest.MapFS{
+				"0001_a.down.sql": {Data: []byte("SELECT 1;")},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		migrations, err := Load(tc.source)
+		if tc.expectedError {
+			assert.Error(m.T(), err)
+			continue
+		}
+
+		assert.NoError(m.T(), err)
+		var versions []int64
+		for _, migration := range migrations {
+			versions = append(versions, migration.Version)
+			assert.Len(m.T(), migration.Checksum, 64)
+		}
+		assert.Equal(m.T(), tc.expectedVersions, versions)
+	}
+}
+
+func (m *MigratorTestSuite) TestEmbeddedFiles() {
+	migrations, err := Load(Files)
+	assert.NoError(m.T(), err)
+
+	for i, migration := range migrations {
+		assert.Equal(m.T(), int64(i+1), migration.Version)
+		assert.NotEmpty(m.T(), migration.Down)
+	}
+}
+
+func (m *MigratorTestSuite) TestUp() {
+	migrations, err := Load(testFiles)
+	assert.NoError(m.T(), err)
+
+	testCases := []struct {
+		applied       [][]interface{}
+		expectedExecs []*Migration
+		expectedError error
+	}{
+		{
+			expectedExecs: migrations,
+		},
+		{
+			applied:       [][]interface{}{{int64(1), "create_users", migrations[0].Checksum}},
+			expectedExecs: migrations[1:],
+		},
+		{
+			applied: [][]interface{}{
+				{int64(1), "create_users", migrations[0].Checksum},
+				{int64(2), "add_email", migrations[1].Checksum},
+			},
+		},
+		{
+			applied: [][]interface{}{
+				{int64(1), "create_users", migrations[0].Checksum},
+				{int64(2), "add_email", migrations[1].Checksum},
+				{int64(3), "add_index", "checksum"},
+			},
+			expectedError: ErrDatabaseAhead,
+		},
+		{
+			applied:       [][]interface{}{{int64(1), "create_users", "checksum"}},
+			expectedError: ErrChecksumMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		m.db, m.mock = databaseMocks.NewDBMock()
+		migrator, err := NewMigrator(m.db, testFiles)
+		assert.NoError(m.T(), err)
+
+		m.expectLock()
+		m.expectApplied(tc.applied)
+		for _, migration := range tc.expectedExecs {
+			m.mock.ExpectExec(regexp.QuoteMeta(migration.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+			m.mock.ExpectExec(regexp.QuoteMeta(insertMigration)).
+				WithArgs(migration.Version, migration.Name, migration.Checksum).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		m.expectRelease()
+
+		err = migrator.Up(context.Background())
+		assert.ErrorIs(m.T(), err, tc.expectedError)
+		assert.NoError(m.T(), m.mock.ExpectationsWereMet())
+	}
+}
+
+func (m *MigratorTestSuite) TestDown() {
+	migrations, err := Load(testFiles)
+	assert.NoError(m.T(), err)
+
+	applied := [][]interface{}{
+		{int64(1), "create_users", migrations[0].Checksum},
+		{int64(2), "add_email", migrations[1].Checksum},
+	}
+
+	testCases := []struct {
+		version       int64
+		expectedExecs []*Migration
+	}{
+		{version: 2},
+		{version: 1, expectedExecs: []*Migration{migrations[1]}},
+		{version: 0, expectedExecs: []*Migration{migrations[1], migrations[0]}},
+	}
+
+	for _, tc := range testCases {
+		m.db, m.mock = databaseMocks.NewDBMock()
+		migrator, err := NewMigrator(m.db, testFiles)
+		assert.NoError(m.T(), err)
+
+		m.expectLock()
+		m.expectApplied(applied)
+		for _, migration := range tc.expectedExecs {
+			m.mock.ExpectExec(regexp.QuoteMeta(migration.Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+			m.mock.ExpectExec(regexp.QuoteMeta(deleteMigration)).
+				WithArgs(migration.Version).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		m.expectRelease()
+
+		err = migrator.Down(context.Background(), tc.version)
+		assert.NoError(m.T(), err)
+		assert.NoError(m.T(), m.mock.ExpectationsWereMet())
+	}
+}
+
+func (m *MigratorTestSuite) TestCheck() {
+	migrations, err := Load(testFiles)
+	assert.NoError(m.T(), err)
+
+	testCases := []struct {
+		applied       [][]interface{}
+		expectedError error
+	}{
+		{
+			applied: [][]interface{}{
+				{int64(1), "create_users", migrations[0].Checksum},
+				{int64(2), "add_email", migrations[1].Checksum},
+			},
+		},
+		{
+			applied:       [][]interface{}{{int64(1), "create_users", migrations[0].Checksum}},
+			expectedError: ErrPendingMigrations,
+		},
+		{
+			applied: [][]interface{}{
+				{int64(1), "create_users", migrations[0].Checksum},
+				{int64(2), "add_email", migrations[1].Checksum},
+				{int64(3), "add_index", "checksum"},
+			},
+			expectedError: ErrDatabaseAhead,
+		},
+		{
+			applied: [][]interface{}{
+				{int64(1), "create_users", migrations[0].Checksum},
+				{int64(2), "add_email", "checksum"},
+			},
+			expectedError: ErrChecksumMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		m.db, m.mock = databaseMocks.NewDBMock()
+		migrator, err := NewMigrator(m.db, testFiles)
+		assert.NoError(m.T(), err)
+
+		m.expectApplied(tc.applied)
+
+		err = migrator.Check(context.Background())
+		assert.ErrorIs(m.T(), err, tc.expectedError)
+	}
+}
+
+func (m *MigratorTestSuite) TestLockTimeout() {
+	m.db, m.mock = databaseMocks.NewDBMock()
+	migrator, err := NewMigrator(m.db, testFiles)
+	assert.NoError(m.T(), err)
+
+	m.mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WithArgs(lockName, int64(lockTimeout.Seconds())).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(0))
+
+	err = migrator.Up(context.Background())
+	assert.Error(m.T(), err)
+	assert.NoError(m.T(), m.mock.ExpectationsWereMet())
+}
+
+func (m *MigratorTestSuite) expectLock() {
+	m.mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).
+		WithArgs(lockName, int64(lockTimeout.Seconds())).
+		WillReturnRows(sqlmock.NewRows([]string{"acquired"}).AddRow(1))
+}
+
+func (m *MigratorTestSuite) expectRelease() {
+	m.mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+		WithArgs(lockName).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectApplied - expects the migrations table to be read, the rows are version, name and checksum
+func (m *MigratorTestSuite) expectApplied(applied [][]interface{}) {
+	m.mock.ExpectExec(regexp.QuoteMeta(createMigrationsTable)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+	for _, record := range applied {
+		rows.AddRow(record[0], record[1], record[2], time.Now())
+	}
+	m.mock.ExpectQuery(regexp.QuoteMeta(getAppliedMigrations)).WillReturnRows(rows)
+}
+
+func TestMigratorTestSuite(t *testing.T) {
+	suite.Run(t, new(MigratorTestSuite))
+}
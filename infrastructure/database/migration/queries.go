@@ -0,0 +1,18 @@
+package migration
+
+const (
+	migrationsTableName = "schema_migrations"
+
+	createMigrationsTable = `CREATE TABLE IF NOT EXISTS ` + migrationsTableName + ` (
+    version BIGINT NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+)`
+
+	getAppliedMigrations = `SELECT version, name, checksum, applied_at FROM ` + migrationsTableName + ` ORDER BY version`
+
+	insertMigration = `INSERT INTO ` + migrationsTableName + ` (version, name, checksum) VALUES (?, ?, ?)`
+
+	deleteMigration = `DELETE FROM ` + migrationsTableName + ` WHERE version = ?`
+)
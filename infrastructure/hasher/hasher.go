@@ -0,0 +1,91 @@
+package hasher
+
+import (
+	"crypto/subtle"
+	"fmt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// MaxPasswordLength - The longest password in bytes. bcrypt only uses the first 72 bytes,
+// so the longer passwords would match anything with the same beginning.
+const MaxPasswordLength = 72
+
+// IHasher - The interface for the password hasher
+type IHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (match bool, needsRehash bool, err error)
+}
+
+// algorithm - A single hashing algorithm that knows how to produce and verify its own encoded hashes
+type algorithm interface {
+	// Recognizes reports whether the encoded hash was produced by this algorithm
+	Recognizes(encodedHash string) bool
+	Hash(password string) (string, error)
+	Verify(password, encodedHash string) (bool, error)
+	// NeedsRehash reports whether the encoded hash was produced with different cost parameters than the current ones
+	NeedsRehash(encodedHash string) bool
+}
+
+// Hasher - Hashes new passwords with the preferred algorithm and verifies hashes of every supported algorithm,
+// so the algorithm or its cost parameters can be changed without invalidating the stored hashes.
+type Hasher struct {
+	preferred  algorithm
+	algorithms []algorithm
+}
+
+// NewHasher - Creates a new hasher which uses the given algorithm for new hashes
+func NewHasher(preferredAlgorithm string, argon2Params *Argon2Params, bcryptCost int) (*Hasher, error) {
+	argon2id, err := newArgon2id(argon2Params)
+	if err != nil {
+		return nil, err
+	}
+
+	bcrypt, err := newBcrypt(bcryptCost)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := &Hasher{algorithms: []algorithm{argon2id, bcrypt}}
+	switch preferredAlgorithm {
+	case AlgorithmArgon2id, "":
+		hasher.preferred = argon2id
+	case AlgorithmBcrypt:
+		hasher.preferred = bcrypt
+	default:
+		return nil, fmt.Errorf("unknown password hashing algorithm: %s", preferredAlgorithm)
+	}
+
+	return hasher, nil
+}
+
+// Hash - Hashes the password with the preferred algorithm and returns the encoded hash
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify - Checks the password against the encoded hash.
+// needsRehash is true when the password matches but the hash should be replaced with a fresh one,
+// either because it was produced by another algorithm or with outdated cost parameters.
+func (h *Hasher) Verify(password, encodedHash string) (bool, bool, error) {
+	for _, alg := range h.algorithms {
+		if !alg.Recognizes(encodedHash) {
+			continue
+		}
+
+		match, err := alg.Verify(password, encodedHash)
+		if err != nil || !match {
+			return false, false, err
+		}
+
+		return true, alg != h.preferred || alg.NeedsRehash(encodedHash), nil
+	}
+
+	// passwords stored before hashing was introduced are kept in plaintext,
+	// they are accepted once and then have to be replaced by a hash
+	match := subtle.ConstantTimeCompare([]byte(password), []byte(encodedHash)) == 1
+	return match, match, nil
+}
@@ -0,0 +1,130 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// cheap parameters to keep the tests fast
+var testArgon2Params = &Argon2Params{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type HasherTestSuite struct {
+	suite.Suite
+}
+
+func (h *HasherTestSuite) TestHash() {
+	testCases := []struct {
+		algorithm      string
+		expectedPrefix string
+	}{
+		{
+			algorithm:      AlgorithmArgon2id,
+			expectedPrefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+		{
+			algorithm:      AlgorithmBcrypt,
+			expectedPrefix: "$2a$04$",
+		},
+	}
+
+	for _, tc := range testCases {
+		hasher, err := NewHasher(tc.algorithm, testArgon2Params, 4)
+		assert.Nil(h.T(), err)
+
+		hash, err := hasher.Hash("pass")
+		assert.Nil(h.T(), err)
+		assert.True(h.T(), strings.HasPrefix(hash, tc.expectedPrefix), hash)
+
+		otherHash, err := hasher.Hash("pass")
+		assert.Nil(h.T(), err)
+		assert.NotEqual(h.T(), hash, otherHash, "hashes of the same password must be salted")
+	}
+}
+
+func (h *HasherTestSuite) TestVerify() {
+	argon2Hasher, err := NewHasher(AlgorithmArgon2id, testArgon2Params, 4)
+	assert.Nil(h.T(), err)
+	argon2Hash, err := argon2Hasher.Hash("pass")
+	assert.Nil(h.T(), err)
+
+	bcryptHasher, err := NewHasher(AlgorithmBcrypt, testArgon2Params, 4)
+	assert.Nil(h.T(), err)
+	bcryptHash, err := bcryptHasher.Hash("pass")
+	assert.Nil(h.T(), err)
+	longPassword := strings.Repeat("p", MaxPasswordLength)
+	longBcryptHash, err := bcryptHasher.Hash(longPassword)
+	assert.Nil(h.T(), err)
+
+	_, err = bcryptHasher.Hash(longPassword + "x")
+	assert.NotNil(h.T(), err)
+
+	strongerParams := *testArgon2Params
+	strongerParams.Iterations = 2
+	strongerHasher, err := NewHasher(AlgorithmArgon2id, &strongerParams, 4)
+	assert.Nil(h.T(), err)
+
+	testCases := []struct {
+		hasher              *Hasher
+		password            string
+		encodedHash         string
+		expectedMatch       bool
+		expectedNeedsRehash bool
+	}{
+		{hasher: argon2Hasher, password: "pass", encodedHash: argon2Hash, expectedMatch: true, expectedNeedsRehash: false},
+		{hasher: argon2Hasher, password: "wrong", encodedHash: argon2Hash, expectedMatch: false, expectedNeedsRehash: false},
+		{hasher: bcryptHasher, password: "pass", encodedHash: bcryptHash, expectedMatch: true, expectedNeedsRehash: false},
+		{hasher: bcryptHasher, password: "wrong", encodedHash: bcryptHash, expectedMatch: false, expectedNeedsRehash: false},
+		// bcrypt ignores everything after the first 72 bytes
+		{hasher: bcryptHasher, password: longPassword + "x", encodedHash: longBcryptHash, expectedMatch: false, expectedNeedsRehash: false},
+		{hasher: bcryptHasher, password: longPassword, encodedHash: longBcryptHash, expectedMatch: true, expectedNeedsRehash: false},
+		// the preferred algorithm has changed
+		{hasher: argon2Hasher, password: "pass", encodedHash: bcryptHash, expectedMatch: true, expectedNeedsRehash: true},
+		{hasher: bcryptHasher, password: "pass", encodedHash: argon2Hash, expectedMatch: true, expectedNeedsRehash: true},
+		// the cost parameters have changed
+		{hasher: strongerHasher, password: "pass", encodedHash: argon2Hash, expectedMatch: true, expectedNeedsRehash: true},
+		// legacy plaintext passwords
+		{hasher: argon2Hasher, password: "pass", encodedHash: "pass", expectedMatch: true, expectedNeedsRehash: true},
+		{hasher: argon2Hasher, password: "wrong", encodedHash: "pass", expectedMatch: false, expectedNeedsRehash: false},
+	}
+
+	for _, tc := range testCases {
+		match, needsRehash, err := tc.hasher.Verify(tc.password, tc.encodedHash)
+		assert.Nil(h.T(), err)
+		assert.Equal(h.T(), tc.expectedMatch, match)
+		assert.Equal(h.T(), tc.expectedNeedsRehash, needsRehash)
+	}
+}
+
+func (h *HasherTestSuite) TestVerifyMalformedHash() {
+	hasher, err := NewHasher(AlgorithmArgon2id, testArgon2Params, 4)
+	assert.Nil(h.T(), err)
+
+	match, needsRehash, err := hasher.Verify("pass", "$argon2id$v=19$m=1024$broken")
+	assert.NotNil(h.T(), err)
+	assert.False(h.T(), match)
+	assert.False(h.T(), needsRehash)
+}
+
+func (h *HasherTestSuite) TestNewHasher() {
+	_, err := NewHasher("md5", testArgon2Params, 4)
+	assert.NotNil(h.T(), err)
+
+	_, err = NewHasher(AlgorithmArgon2id, &Argon2Params{}, 4)
+	assert.NotNil(h.T(), err)
+
+	_, err = NewHasher(AlgorithmBcrypt, testArgon2Params, 100)
+	assert.NotNil(h.T(), err)
+}
+
+func TestHasherTestSuite(t *testing.T) {
+	suite.Run(t, new(HasherTestSuite))
+}
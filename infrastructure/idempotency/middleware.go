@@ -0,0 +1,231 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	authEntity "faceit/domain/auth/entity"
+	"faceit/domain/constants"
+	"faceit/infrastructure/server"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Header - The header of the idempotency key, the retries of a request send the same key
+	Header = "Idempotency-Key"
+	// ReplayedHeader - Set on the responses which are replayed from a former request with the same key
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// pollInterval - How often a request checks the key held by a concurrent request
+const pollInterval = 50 * time.Millisecond
+
+// validKey - The keys are generated by the clients, e.g. UUIDs, they are only accepted if they are plain
+var validKey = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,255}$`)
+
+// replayedHeaders - The headers of the response which are stored and replayed with it
+var replayedHeaders = []string{"Content-Type", "Location", "ETag", "Deprecation", "Link"}
+
+// Middleware - Makes the route idempotent for the requests with the Idempotency-Key header, the requests without it are served as usual.
+// The response of the first request with a key is stored for the TTL and replayed for its retries, and reusing the key with another
+// request is rejected with constants.ErrIdempotencyKeyReused. The concurrent requests with the same key are served one at a time.
+// The keys are scoped by the client, and the bodies larger than MaxBodySize are rejected with constants.ErrRequestTooLarge.
+// The server errors are not stored, so the request can be retried.
+func Middleware(store *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validKey.MatchString(key) {
+			_ = c.Error(constants.NewValidationError("invalid header", constants.FieldError{Field: Header, Code: "invalid_format", Message: "must be 1 to 255 letters, digits, ., _, : and -"}))
+			c.Abort()
+			return
+		}
+
+		// the body is read once more than the limit, to tell if it's larger
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, store.config.MaxBodySize+1))
+		if err == nil && int64(len(body)) > store.config.MaxBodySize {
+			err = constants.ErrRequestTooLarge
+		}
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		// the keys of the clients are kept apart, so a client can't collide with the key of another one
+		key = scope(c) + ":" + key
+		record, token, err := acquire(c.Request.Context(), store, key)
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		if record != nil {
+			replay(c, record, fingerprint)
+			return
+		}
+
+		defer func() {
+			if err := store.Unlock(key, token); err != nil {
+				log.Printf("failed to unlock idempotency key %s: %s", key, err)
+			}
+		}()
+		defer keepLocked(store, key, token)()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// the errors are written by server.ErrorHandler after this middleware returns, so they are written here to be stored
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			server.WriteProblem(c, server.NewProblem(c.Errors.Last().Err))
+		}
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		record = &Record{Fingerprint: fingerprint, Status: c.Writer.Status(), Header: map[string]string{}, Body: recorder.body.Bytes()}
+		for _, name := range replayedHeaders {
+			if value := c.Writer.Header().Get(name); value != "" {
+				record.Header[name] = value
+			}
+		}
+		if err := store.Save(key, record); err != nil {
+			// the response is already written, the retries are served again as if there were no key
+			log.Printf("failed to save the response of idempotency key %s: %s", key, err)
+		}
+	}
+}
+
+// acquire - Waits until the key is free, then returns either the record of the former request with the key,
+// or the token of the lock taken for this request. It gives up with constants.ErrIdempotencyKeyInUse after the LockTTL.
+func acquire(ctx context.Context, store *Store, key string) (*Record, string, error) {
+	deadline := time.Now().Add(store.config.LockTTL)
+	for {
+		record, err := store.Get(key)
+		if err != nil || record != nil {
+			return record, "", err
+		}
+
+		token, ok, err := store.Lock(key)
+		if err != nil {
+			return nil, "", err
+		}
+		if ok {
+			// the former request could have finished between reading its record and taking the lock
+			record, err := store.Get(key)
+			if err != nil || record != nil {
+				_ = store.Unlock(key, token)
+				return record, "", err
+			}
+			return nil, token, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, "", constants.ErrIdempotencyKeyInUse
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// scope - The client the keys belong to, the authenticated user or else the address of the client.
+// The address is only taken from the headers of the trusted proxies, so a client can't pose as another one.
+func scope(c *gin.Context) string {
+	if principal, ok := authEntity.PrincipalFromContext(c.Request.Context()); ok {
+		return "user:" + strconv.FormatInt(principal.UserID, 10)
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// keepLocked - Extends the lock of the key while its request is served, so a slow request doesn't lose it to a retry.
+// It returns the function which stops extending it.
+func keepLocked(store *Store, key, token string) func() {
+	if store.config.LockTTL <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(store.config.LockTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := store.Refresh(key, token); err != nil {
+					log.Printf("failed to refresh the lock of idempotency key %s: %s", key, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// replay - Writes the stored response if the record belongs to the same request
+func replay(c *gin.Context, record *Record, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		_ = c.Error(constants.ErrIdempotencyKeyReused)
+		c.Abort()
+		return
+	}
+
+	for name, value := range record.Header {
+		c.Header(name, value)
+	}
+	c.Header(ReplayedHeader, "true")
+	c.Data(record.Status, record.Header["Content-Type"], record.Body)
+	c.Abort()
+}
+
+// requestFingerprint - The hash of the method, the path and the body of the request
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder - Keeps a copy of the body written to the response
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
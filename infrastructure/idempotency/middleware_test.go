@@ -0,0 +1,207 @@
+package idempotency
+
+import (
+	"errors"
+	"faceit/domain/constants"
+	"faceit/infrastructure/server"
+	redisMocks "faceit/mocks/infrastructure/redis"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	redis  *miniredis.Miniredis
+	store  *Store
+	router *gin.Engine
+	calls  int32
+	err    error
+}
+
+func (m *MiddlewareTestSuite) SetupTest() {
+	m.redis = redisMocks.NewRedisMock()
+	client := redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{m.redis.Addr()}})
+	m.store = NewStore(client, Config{TTL: time.Hour, LockTTL: time.Second, MaxBodySize: 64})
+	m.calls, m.err = 0, nil
+
+	gin.SetMode(gin.TestMode)
+	m.router = gin.New()
+	m.router.Use(server.ErrorHandler())
+	m.router.POST("/v1/users", Middleware(m.store), func(c *gin.Context) {
+		calls := atomic.AddInt32(&m.calls, 1)
+		// the concurrent requests would both get here without the lock
+		time.Sleep(20 * time.Millisecond)
+		if m.err != nil {
+			_ = c.Error(m.err)
+			return
+		}
+
+		c.Header("Location", "/v1/users/7")
+		server.Response(c, http.StatusCreated, gin.H{"id": 7, "calls": calls})
+	})
+}
+
+func (m *MiddlewareTestSuite) TearDownTest() {
+	m.redis.Close()
+}
+
+func (m *MiddlewareTestSuite) serve(key, body string) *httptest.ResponseRecorder {
+	return m.serveFrom(testClient+":1234", key, body)
+}
+
+// testClient - The address of the client of the requests by default
+const testClient = "192.0.2.1"
+
+func (m *MiddlewareTestSuite) serveFrom(remoteAddr, key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(body))
+	request.RemoteAddr = remoteAddr
+	request.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		request.Header.Set(Header, key)
+	}
+	recorder := httptest.NewRecorder()
+	m.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (m *MiddlewareTestSuite) TestReplay() {
+	first := m.serve("key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusCreated, first.Code)
+	assert.Empty(m.T(), first.Header().Get(ReplayedHeader))
+
+	retry := m.serve("key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusCreated, retry.Code)
+	assert.Equal(m.T(), first.Body.String(), retry.Body.String())
+	assert.Equal(m.T(), "/v1/users/7", retry.Header().Get("Location"))
+	assert.Equal(m.T(), first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	assert.Equal(m.T(), "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(m.T(), int32(1), m.calls)
+
+	// another request can't reuse the key
+	reused := m.serve("key-1", `{"nick_name":"other"}`)
+	assert.Equal(m.T(), http.StatusUnprocessableEntity, reused.Code)
+	assert.Contains(m.T(), reused.Body.String(), "idempotency_key_reused")
+
+	// the requests without a key are served as usual
+	m.serve("", `{"nick_name":"test"}`)
+	m.serve("", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), int32(3), m.calls)
+
+	invalid := m.serve("key\nwith a new line", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusBadRequest, invalid.Code)
+	assert.Equal(m.T(), int32(3), m.calls)
+}
+
+func (m *MiddlewareTestSuite) TestErrors() {
+	// the client errors are replayed
+	m.err = constants.ErrUserExists
+	response := m.serve("key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusConflict, response.Code)
+	m.err = nil
+	response = m.serve("key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusConflict, response.Code)
+	assert.Equal(m.T(), "application/problem+json", response.Header().Get("Content-Type"))
+	assert.Equal(m.T(), int32(1), m.calls)
+
+	// the server errors are not, so the request can be retried
+	m.err = errors.New("connection refused")
+	response = m.serve("key-2", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusInternalServerError, response.Code)
+	m.err = nil
+	response = m.serve("key-2", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusCreated, response.Code)
+	assert.Equal(m.T(), int32(3), m.calls)
+}
+
+func (m *MiddlewareTestSuite) TestConcurrent() {
+	responses := make([]*httptest.ResponseRecorder, 5)
+	var wg sync.WaitGroup
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = m.serve("key-1", `{"nick_name":"test"}`)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(m.T(), int32(1), m.calls)
+	for _, response := range responses {
+		assert.Equal(m.T(), http.StatusCreated, response.Code)
+		assert.Equal(m.T(), responses[0].Body.String(), response.Body.String())
+	}
+}
+
+func (m *MiddlewareTestSuite) TestInUse() {
+	// a request holds the key for longer than the others wait for it
+	_, ok, err := m.store.Lock("ip:" + testClient + ":key-1")
+	m.Require().NoError(err)
+	m.Require().True(ok)
+
+	response := m.serve("key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusConflict, response.Code)
+	assert.Contains(m.T(), response.Body.String(), "idempotency_key_in_use")
+	assert.Equal(m.T(), int32(0), m.calls)
+}
+
+func (m *MiddlewareTestSuite) TestTooLarge() {
+	response := m.serve("key-1", `{"nick_name":"`+strings.Repeat("a", 64)+`"}`)
+	assert.Equal(m.T(), http.StatusRequestEntityTooLarge, response.Code)
+	assert.Contains(m.T(), response.Body.String(), "request_too_large")
+	assert.Equal(m.T(), int32(0), m.calls)
+
+	// the limit is only for the requests with a key
+	response = m.serve("", `{"nick_name":"`+strings.Repeat("a", 64)+`"}`)
+	assert.Equal(m.T(), http.StatusCreated, response.Code)
+}
+
+func (m *MiddlewareTestSuite) TestScope() {
+	// the same key of another client is another request rather than a reuse or a retry
+	first := m.serveFrom("192.0.2.1:1234", "key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), http.StatusCreated, first.Code)
+	other := m.serveFrom("198.51.100.2:1234", "key-1", `{"nick_name":"other"}`)
+	assert.Equal(m.T(), http.StatusCreated, other.Code)
+	assert.Empty(m.T(), other.Header().Get(ReplayedHeader))
+	assert.Equal(m.T(), int32(2), m.calls)
+
+	retry := m.serveFrom("192.0.2.1:4321", "key-1", `{"nick_name":"test"}`)
+	assert.Equal(m.T(), "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(m.T(), first.Body.String(), retry.Body.String())
+}
+
+func (m *MiddlewareTestSuite) TestKeepLocked() {
+	token, ok, err := m.store.Lock("key-1")
+	m.Require().NoError(err)
+	m.Require().True(ok)
+
+	stop := keepLocked(m.store, "key-1", token)
+	m.redis.FastForward(900 * time.Millisecond)
+	// the lock is extended before it expires
+	time.Sleep(500 * time.Millisecond)
+	stop()
+	assert.Equal(m.T(), time.Second, m.redis.TTL(lockKeyPrefix+"key-1"))
+
+	// a lock taken over by another request is not extended
+	m.redis.FastForward(2 * time.Second)
+	_, ok, err = m.store.Lock("key-1")
+	m.Require().NoError(err)
+	m.Require().True(ok)
+	m.redis.FastForward(500 * time.Millisecond)
+	m.Require().NoError(m.store.Refresh("key-1", token))
+	assert.Equal(m.T(), 500*time.Millisecond, m.redis.TTL(lockKeyPrefix+"key-1"))
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,138 @@
+package idempotency
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	recordKeyPrefix = "idempotency:record:"
+	lockKeyPrefix   = "idempotency:lock:"
+)
+
+// unlockScript - Deletes the lock only if it's still held with the token, so a request never releases the lock of another one
+// after its own lock has expired
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// refreshScript - Extends the lock only if it's still held with the token
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// defaultMaxBodySize - The largest body of a request with a key when MaxBodySize is not set
+const defaultMaxBodySize = 1 << 20
+
+// Config - How long the responses are kept and how long a request can hold its key
+type Config struct {
+	// TTL - How long the response of a request is replayed for the retries with the same key
+	TTL time.Duration
+	// LockTTL - The lock of a key expires after this in case its request never finishes, e.g. the replica dies.
+	// It's extended while the request is served, and the concurrent requests with the same key wait at most this long for it.
+	LockTTL time.Duration
+	// MaxBodySize - The requests with a larger body are rejected, as the body is read into the memory to be fingerprinted
+	MaxBodySize int64
+}
+
+// Record - The response of a request, stored under its idempotency key
+type Record struct {
+	// Fingerprint - The hash of the method, the path and the body of the request, the key can't be reused for another request
+	Fingerprint string            `json:"fingerprint"`
+	Status      int               `json:"status"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+// Store - Keeps the responses of the requests by their idempotency keys in Redis
+type Store struct {
+	redis  redis.UniversalClient
+	config Config
+}
+
+func NewStore(redis redis.UniversalClient, config Config) *Store {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultMaxBodySize
+	}
+
+	return &Store{redis: redis, config: config}
+}
+
+// Get - Returns the record of the key, or nil if there is none
+func (s *Store) Get(key string) (*Record, error) {
+	value, err := s.redis.Get(recordKeyPrefix + key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	record := &Record{}
+	if err := json.Unmarshal(value, record); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency record: %w", err)
+	}
+
+	return record, nil
+}
+
+// Save - Stores the record of the key for the TTL
+func (s *Store) Save(key string, record *Record) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency record: %w", err)
+	}
+
+	if err := s.redis.Set(recordKeyPrefix+key, value, s.config.TTL).Err(); err != nil {
+		return fmt.Errorf("failed to save idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// Lock - Takes the lock of the key for the LockTTL, ok is false if another request holds it.
+// The returned token releases the lock with Unlock.
+func (s *Store) Lock(key string) (token string, ok bool, err error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", false, fmt.Errorf("failed to generate idempotency lock token: %w", err)
+	}
+	token = hex.EncodeToString(b[:])
+
+	ok, err = s.redis.SetNX(lockKeyPrefix+key, token, s.config.LockTTL).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to lock idempotency key: %w", err)
+	}
+
+	return token, ok, nil
+}
+
+// Refresh - Extends the lock of the key for another LockTTL if it's still held with the token
+func (s *Store) Refresh(key, token string) error {
+	if err := refreshScript.Run(s.redis, []string{lockKeyPrefix + key}, token, s.config.LockTTL.Milliseconds()).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to refresh idempotency lock: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock - Releases the lock of the key if it's still held with the token
+func (s *Store) Unlock(key, token string) error {
+	if err := unlockScript.Run(s.redis, []string{lockKeyPrefix + key}, token).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to unlock idempotency key: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package requestmeta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// Header - The header which carries the request ID, the gRPC calls carry it in the metadata key of the same name in lowercase
+const Header = "X-Request-ID"
+
+// validRequestID - The request IDs given by the clients are only kept if they are short and plain, as they end up in the logs and the audit trail
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// Meta - Where a request comes from, it's recorded with the changes made by the request
+type Meta struct {
+	// RequestID - Correlates the changes and the logs of the request, it's given by the client or generated
+	RequestID string
+	// SourceIP - The address of the client
+	SourceIP string
+}
+
+type metaContextKey struct{}
+
+// ContextWithMeta - Returns a copy of the context which carries the meta of the request
+func ContextWithMeta(ctx context.Context, meta *Meta) context.Context {
+	return context.WithValue(ctx, metaContextKey{}, meta)
+}
+
+// MetaFromContext - Returns the meta of the request carried by the context, if any
+func MetaFromContext(ctx context.Context) (*Meta, bool) {
+	meta, ok := ctx.Value(metaContextKey{}).(*Meta)
+	return meta, ok && meta != nil
+}
+
+// RequestID - Returns the request ID given by the client if it's valid, otherwise a new random one
+func RequestID(given string) string {
+	if validRequestID.MatchString(given) {
+		return given
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// the request is not failed for its ID, it just can't be correlated
+		return ""
+	}
+
+	return hex.EncodeToString(b[:])
+}
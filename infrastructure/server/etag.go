@@ -0,0 +1,36 @@
+package server
+
+import (
+	"faceit/domain/constants"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag - Returns the strong entity tag of the version of a resource, e.g. "3"
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// IfMatch - Returns the version in the If-Match header, ok is false when the header is not given.
+// The version is 0 for *, which matches any version. Only a single entity tag of ETag can be given,
+// otherwise it returns a validation error.
+func IfMatch(c *gin.Context) (version int64, ok bool, err error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		return 0, false, nil
+	}
+	if value == "*" {
+		return 0, true, nil
+	}
+
+	if len(value) > 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		version, err = strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	}
+	if version <= 0 || err != nil {
+		return 0, false, constants.NewValidationError("invalid header", constants.FieldError{Field: "If-Match", Code: "invalid_format", Message: `must be the ETag of the resource, e.g. "3"`})
+	}
+
+	return version, true, nil
+}
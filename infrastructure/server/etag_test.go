@@ -0,0 +1,55 @@
+package server
+
+import (
+	"faceit/domain/constants"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ETagTestSuite struct {
+	suite.Suite
+}
+
+func (e *ETagTestSuite) TestIfMatch() {
+	testCases := []struct {
+		ifMatch         string
+		expectedVersion int64
+		expectedOK      bool
+		expectedErr     bool
+	}{
+		{ifMatch: ETag(3), expectedVersion: 3, expectedOK: true},
+		{ifMatch: ` "12" `, expectedVersion: 12, expectedOK: true},
+		{ifMatch: "*", expectedOK: true},
+		{ifMatch: ""},
+		{ifMatch: "3", expectedErr: true},
+		{ifMatch: `W/"3"`, expectedErr: true},
+		{ifMatch: `"3", "4"`, expectedErr: true},
+		{ifMatch: `"0"`, expectedErr: true},
+		{ifMatch: `""`, expectedErr: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testCases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPatch, "/", nil)
+		c.Request.Header.Set("If-Match", tc.ifMatch)
+
+		version, ok, err := IfMatch(c)
+		assert.Equal(e.T(), tc.expectedVersion, version, tc.ifMatch)
+		assert.Equal(e.T(), tc.expectedOK, ok, tc.ifMatch)
+		if tc.expectedErr {
+			assert.IsType(e.T(), &constants.ValidationError{}, err, tc.ifMatch)
+		} else {
+			assert.NoError(e.T(), err, tc.ifMatch)
+		}
+	}
+}
+
+func TestETagTestSuite(t *testing.T) {
+	suite.Run(t, new(ETagTestSuite))
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// IServices - The interface for the controllers which serve gRPC services
+type IServices interface {
+	RegisterServices(server *grpc.Server)
+}
+
+// GRPCServer - A gRPC server with the standard health checking protocol and reflection
+type GRPCServer struct {
+	server   *grpc.Server
+	health   *health.Server
+	listener net.Listener
+}
+
+// RunGRPC - Starts a gRPC server with the services of the given controllers, the interceptors are applied to all the calls after UnaryRequestMeta
+func RunGRPC(port string, interceptors []grpc.UnaryServerInterceptor, controllers ...IServices) (*GRPCServer, error) {
+	listener, err := net.Listen("tcp", port)
+	if err != nil {
+		return nil, err
+	}
+
+	interceptors = append([]grpc.UnaryServerInterceptor{UnaryRequestMeta}, interceptors...)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	for _, controller := range controllers {
+		controller.RegisterServices(server)
+	}
+
+	// every registered service is reported as serving, as well as the server as a whole with the empty service name
+	healthServer := health.NewServer()
+	for name := range server.GetServiceInfo() {
+		healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go func() {
+		log.Printf("Listening and serving gRPC on %s\n", listener.Addr())
+		if err := server.Serve(listener); err != nil {
+			log.Printf("grpc server stopped with err: %s \n", err)
+		}
+	}()
+
+	return &GRPCServer{server: server, health: healthServer, listener: listener}, nil
+}
+
+// Addr - The address the server listens on, which has the actual port when it was started on port 0
+func (g *GRPCServer) Addr() net.Addr {
+	return g.listener.Addr()
+}
+
+// Shutdown - Reports the services as not serving and waits for the running calls to finish.
+// The calls still running when the context is done are cancelled.
+func (g *GRPCServer) Shutdown(ctx context.Context) error {
+	g.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		g.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		g.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"faceit/domain/constants"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem - The RFC 7807 problem details of a failed request.
+// Code is the stable machine-readable code of the problem, and Errors has the problems of each field of the request.
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     string                 `json:"code"`
+	Errors   []constants.FieldError `json:"errors,omitempty"`
+}
+
+func init() {
+	// report the fields of the requests by their json or form names rather than the names of the struct fields
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form", "uri"} {
+				name := strings.Split(field.Tag.Get(tag), ",")[0]
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// ErrorHandler - Middleware which writes the last error added to the context by the handlers as a problem,
+// so the handlers only have to call c.Error and return.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		WriteProblem(c, NewProblem(c.Errors.Last().Err))
+	}
+}
+
+// NewProblem - Creates the problem of the error, the unknown errors are reported as internal errors without their details
+func NewProblem(err error) *Problem {
+	var domainErr *constants.Error
+	if errors.As(err, &domainErr) {
+		problem := newProblem(kindStatus(domainErr.Kind), domainErr.Code, domainErr.Message)
+		if domainErr.Field != "" {
+			problem.Errors = []constants.FieldError{{Field: domainErr.Field, Code: domainErr.Code, Message: domainErr.Message}}
+		}
+		return problem
+	}
+
+	var validationErr *constants.ValidationError
+	if errors.As(err, &validationErr) || bindingError(err, &validationErr) {
+		problem := newProblem(http.StatusBadRequest, "invalid_request", validationErr.Message)
+		problem.Errors = validationErr.Fields
+		return problem
+	}
+
+	log.Printf("internal error: %s\n", err)
+	return newProblem(http.StatusInternalServerError, "internal_error", "")
+}
+
+// WriteProblem - Writes the problem as the response and aborts the request
+func WriteProblem(c *gin.Context, problem *Problem) {
+	problem.Instance = c.Request.URL.Path
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// ParamInt64 - Returns the integer path parameter, or a validation error if it's not an integer
+func ParamInt64(c *gin.Context, name string) (int64, error) {
+	value, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, constants.NewValidationError("invalid path parameter", constants.FieldError{Field: name, Code: "invalid_type", Message: "must be an integer"})
+	}
+
+	return value, nil
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// kindStatus - Returns the http status of the kind of the domain errors
+func kindStatus(kind constants.Kind) int {
+	switch kind {
+	case constants.KindInvalid:
+		return http.StatusUnprocessableEntity
+	case constants.KindNotFound:
+		return http.StatusNotFound
+	case constants.KindConflict:
+		return http.StatusConflict
+	case constants.KindUnauthenticated:
+		return http.StatusUnauthorized
+	case constants.KindForbidden:
+		return http.StatusForbidden
+	case constants.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case constants.KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	case constants.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindingError - Converts the errors of binding the body or the query of the request to a validation error
+func bindingError(err error, validationErr **constants.ValidationError) bool {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+		numErr         *strconv.NumError
+	)
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]constants.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, constants.FieldError{Field: fieldName(fieldErr), Code: fieldErr.Tag(), Message: validationMessage(fieldErr)})
+		}
+		*validationErr = constants.NewValidationError("the request has invalid fields", fields...)
+	case errors.As(err, &typeErr):
+		*validationErr = constants.NewValidationError("the request has invalid fields",
+			constants.FieldError{Field: typeErr.Field, Code: "invalid_type", Message: fmt.Sprintf("must be a %s", typeErr.Type)})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		*validationErr = constants.NewValidationError("the request body is not valid json")
+	case errors.As(err, &numErr):
+		*validationErr = constants.NewValidationError(fmt.Sprintf("%q is not a valid number", numErr.Num))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// fieldName - Returns the path of the field in the request without the name of the request struct
+func fieldName(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+
+	return namespace
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "email":
+		return "must be an email address"
+	default:
+		return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"faceit/domain/constants"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProblemTestSuite struct {
+	suite.Suite
+}
+
+func (p *ProblemTestSuite) TestNewProblem() {
+	type request struct {
+		Name  string `json:"name" binding:"required"`
+		Limit int64  `form:"limit" binding:"max=10"`
+	}
+	bindErr := binding.Validator.ValidateStruct(&request{Limit: 11})
+	typeErr := json.Unmarshal([]byte(`{"name":1}`), &request{})
+
+	testCases := []struct {
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+		expectedErrors []constants.FieldError
+	}{
+		{err: constants.ErrUserNotFound, expectedStatus: http.StatusNotFound, expectedCode: "user_not_found", expectedDetail: "user not found"},
+		{err: fmt.Errorf("failed to get user: %w", constants.ErrUserExists), expectedStatus: http.StatusConflict, expectedCode: "user_exists", expectedDetail: "user already exists"},
+		{
+			err:            constants.ErrEmailExists,
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "user_exists",
+			expectedDetail: constants.ErrEmailExists.Message,
+			expectedErrors: []constants.FieldError{{Field: "email", Code: "user_exists", Message: constants.ErrEmailExists.Message}},
+		},
+		{err: constants.ErrHasNoChanges, expectedStatus: http.StatusUnprocessableEntity, expectedCode: "no_changes", expectedDetail: "the information has no changes"},
+		{err: constants.ErrUnauthenticated, expectedStatus: http.StatusUnauthorized, expectedCode: "unauthenticated", expectedDetail: "authentication required"},
+		{err: constants.ErrForbidden, expectedStatus: http.StatusForbidden, expectedCode: "forbidden", expectedDetail: "permission denied"},
+		{err: constants.ErrPreconditionFailed, expectedStatus: http.StatusPreconditionFailed, expectedCode: "precondition_failed", expectedDetail: constants.ErrPreconditionFailed.Message},
+		{err: constants.ErrPreconditionRequired, expectedStatus: http.StatusPreconditionRequired, expectedCode: "precondition_required", expectedDetail: constants.ErrPreconditionRequired.Message},
+		{err: constants.ErrRequestTooLarge, expectedStatus: http.StatusRequestEntityTooLarge, expectedCode: "request_too_large", expectedDetail: constants.ErrRequestTooLarge.Message},
+		{
+			err:            constants.ErrInvalidWebhookURL,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_webhook_url",
+			expectedDetail: constants.ErrInvalidWebhookURL.Message,
+			expectedErrors: []constants.FieldError{{Field: "url", Code: "invalid_webhook_url", Message: constants.ErrInvalidWebhookURL.Message}},
+		},
+		{
+			err:            bindErr,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+			expectedDetail: "the request has invalid fields",
+			expectedErrors: []constants.FieldError{{Field: "name", Code: "required", Message: "is required"}, {Field: "limit", Code: "max", Message: "must be at most 10"}},
+		},
+		{
+			err:            typeErr,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_request",
+			expectedDetail: "the request has invalid fields",
+			expectedErrors: []constants.FieldError{{Field: "name", Code: "invalid_type", Message: "must be a string"}},
+		},
+		{err: json.Unmarshal([]byte(`{"name"`), &request{}), expectedStatus: http.StatusBadRequest, expectedCode: "invalid_request", expectedDetail: "the request body is not valid json"},
+		{err: errors.New("dial tcp 127.0.0.1:3306: connect: connection refused"), expectedStatus: http.StatusInternalServerError, expectedCode: "internal_error"},
+	}
+
+	for _, tc := range testCases {
+		problem := NewProblem(tc.err)
+		assert.Equal(p.T(), "about:blank", problem.Type)
+		assert.Equal(p.T(), http.StatusText(tc.expectedStatus), problem.Title)
+		assert.Equal(p.T(), tc.expectedStatus, problem.Status)
+		assert.Equal(p.T(), tc.expectedCode, problem.Code)
+		assert.Equal(p.T(), tc.expectedDetail, problem.Detail)
+		assert.Equal(p.T(), tc.expectedErrors, problem.Errors)
+	}
+}
+
+func TestProblemTestSuite(t *testing.T) {
+	suite.Run(t, new(ProblemTestSuite))
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"faceit/infrastructure/requestmeta"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RequestMeta - A middleware which puts the request ID and the address of the client into the context of the request.
+// The request ID is taken from the X-Request-ID header or generated, and it's returned in the same header of the response.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		meta := &requestmeta.Meta{
+			RequestID: requestmeta.RequestID(c.GetHeader(requestmeta.Header)),
+			SourceIP:  c.ClientIP(),
+		}
+
+		c.Header(requestmeta.Header, meta.RequestID)
+		c.Request = c.Request.WithContext(requestmeta.ContextWithMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
+
+// UnaryRequestMeta - gRPC interceptor which puts the request ID and the address of the client into the context of the call.
+// The request ID is taken from the x-request-id metadata or generated, and it's returned in the header metadata of the response.
+func UnaryRequestMeta(ctx context.Context, request interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var given string
+	if values := md.Get(strings.ToLower(requestmeta.Header)); len(values) > 0 {
+		given = values[0]
+	}
+
+	meta := &requestmeta.Meta{RequestID: requestmeta.RequestID(given)}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		meta.SourceIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(meta.SourceIP); err == nil {
+			meta.SourceIP = host
+		}
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(requestmeta.Header), meta.RequestID))
+	return handler(requestmeta.ContextWithMeta(ctx, meta), request)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"faceit/infrastructure/requestmeta"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type RequestMetaTestSuite struct {
+	suite.Suite
+}
+
+func (r *RequestMetaTestSuite) TestRequestMeta() {
+	testCases := []struct {
+		requestID         string
+		expectedRequestID string
+	}{
+		{requestID: "7b3c2a-e1", expectedRequestID: "7b3c2a-e1"},
+		// too long or not plain, a new one is generated
+		{requestID: strings.Repeat("a", 65)},
+		{requestID: "id\nforged log line"},
+		{requestID: ""},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testCases {
+		var meta *requestmeta.Meta
+		router := gin.New()
+		router.Use(RequestMeta())
+		router.GET("/", func(c *gin.Context) {
+			meta, _ = requestmeta.MetaFromContext(c.Request.Context())
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = "203.0.113.7:51234"
+		request.Header.Set(requestmeta.Header, tc.requestID)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		r.Require().NotNil(meta)
+		assert.Equal(r.T(), "203.0.113.7", meta.SourceIP)
+		if tc.expectedRequestID != "" {
+			assert.Equal(r.T(), tc.expectedRequestID, meta.RequestID)
+		} else {
+			assert.Len(r.T(), meta.RequestID, 32)
+		}
+		assert.Equal(r.T(), meta.RequestID, recorder.Header().Get(requestmeta.Header))
+	}
+}
+
+// metaRoutes - Records the meta of the requests to /meta
+type metaRoutes struct {
+	meta *requestmeta.Meta
+}
+
+func (m *metaRoutes) RegisterRoutes(router *gin.Engine) {
+	router.GET("/meta", func(c *gin.Context) {
+		m.meta, _ = requestmeta.MetaFromContext(c.Request.Context())
+	})
+}
+
+func (r *RequestMetaTestSuite) TestSourceIP() {
+	testCases := []struct {
+		trustedProxies   []string
+		remoteAddr       string
+		forwardedFor     string
+		expectedSourceIP string
+	}{
+		// no proxy is trusted by default, so a client can't forge its address
+		{remoteAddr: "203.0.113.7:51234", forwardedFor: "198.51.100.1", expectedSourceIP: "203.0.113.7"},
+		{trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:51234", forwardedFor: "198.51.100.1", expectedSourceIP: "203.0.113.7"},
+		{trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "10.1.2.3:51234", forwardedFor: "198.51.100.1", expectedSourceIP: "198.51.100.1"},
+		{trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "10.1.2.3:51234", expectedSourceIP: "10.1.2.3"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testCases {
+		routes := &metaRoutes{}
+		router, err := newRouter(tc.trustedProxies, routes)
+		r.Require().NoError(err)
+
+		request := httptest.NewRequest(http.MethodGet, "/meta", nil)
+		request.RemoteAddr = tc.remoteAddr
+		if tc.forwardedFor != "" {
+			request.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			request.Header.Set("X-Real-IP", tc.forwardedFor)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		r.Require().NotNil(routes.meta)
+		assert.Equal(r.T(), tc.expectedSourceIP, routes.meta.SourceIP)
+	}
+
+	_, err := newRouter([]string{"not an address"})
+	assert.Error(r.T(), err)
+}
+
+func (r *RequestMetaTestSuite) TestUnaryRequestMeta() {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "7b3c2a-e1"))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51234}})
+
+	var meta *requestmeta.Meta
+	_, err := UnaryRequestMeta(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		meta, _ = requestmeta.MetaFromContext(ctx)
+		return nil, nil
+	})
+	r.Require().NoError(err)
+	assert.Equal(r.T(), &requestmeta.Meta{RequestID: "7b3c2a-e1", SourceIP: "203.0.113.7"}, meta)
+}
+
+func TestRequestMetaTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestMetaTestSuite))
+}
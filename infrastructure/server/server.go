@@ -0,0 +1,111 @@
+package server
+
+import (
+	"faceit/infrastructure/requestmeta"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// IRoutes - The interface for the controllers which serve HTTP routes
+type IRoutes interface {
+	RegisterRoutes(router *gin.Engine)
+}
+
+// Run - Starts the gin engine with the routes of the given controllers.
+// The address of the client is only taken from the X-Forwarded-For and X-Real-IP headers of the requests coming from the trusted proxies,
+// the other clients could forge it otherwise, e.g. in the audit trail.
+func Run(port string, trustedProxies []string, controllers ...IRoutes) (*http.Server, error) {
+	// init gin
+	gin.SetMode(gin.DebugMode)
+	router, err := newRouter(trustedProxies, controllers...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: we use http server to have graceful shutdown
+	server := &http.Server{
+		Addr:         port,
+		Handler:      router,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 20 * time.Second,
+		IdleTimeout:  10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Listening and serving HTTP on %s\n", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("gin sever stoped with err: %s \n", err)
+		}
+	}()
+
+	return server, nil
+}
+
+// newRouter - Creates the gin engine with the middlewares and the routes of the given controllers
+func newRouter(trustedProxies []string, controllers ...IRoutes) (*gin.Engine, error) {
+	router := gin.New()
+	// gin trusts all the proxies unless they are set
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	// gin middleware config
+	// Note: the middlewares must be registered before the routes, otherwise they are not applied to them
+	router.Use(gin.Recovery())
+	router.Use(cors.New(cors.Config{
+		AllowAllOrigins:  true,
+		AllowMethods:     []string{"GET", "POST", "PUT", "HEAD", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "X-Requested-With", "Authorization", "If-Match", "Idempotency-Key", requestmeta.Header},
+		ExposeHeaders:    []string{"Location", "Deprecation", "Link", "ETag", "Idempotent-Replayed", requestmeta.Header},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+	router.Use(RequestMeta())
+	router.Use(ErrorHandler())
+
+	for _, controller := range controllers {
+		controller.RegisterRoutes(router)
+	}
+
+	router.NoRoute(func(c *gin.Context) {
+		WriteProblem(c, newProblem(http.StatusNotFound, "route_not_found", fmt.Sprintf("Page not found: %s, method: %s", c.Request.URL, c.Request.Method)))
+	})
+
+	router.NoMethod(func(c *gin.Context) {
+		WriteProblem(c, newProblem(http.StatusMethodNotAllowed, "method_not_allowed", "Method not found"))
+	})
+
+	return router, nil
+}
+
+// Deprecated - A middleware which marks the route as deprecated and links to the route which replaces it
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		c.Next()
+	}
+}
+
+// Response - A simple helper function to prepare the response structure
+func Response(c *gin.Context, status int, payload interface{}) {
+	type Response struct {
+		Status  int         `json:"status"`
+		Payload interface{} `json:"payload"`
+	}
+
+	response := Response{
+		Status:  status,
+		Payload: payload,
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Status(status)
+
+	c.JSON(status, response)
+}
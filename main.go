@@ -2,17 +2,42 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"faceit/config"
+	authController "faceit/domain/auth/controller"
+	authService "faceit/domain/auth/service"
+	"faceit/domain/auth/token"
+	rbacController "faceit/domain/rbac/controller"
+	rbacRepository "faceit/domain/rbac/repository"
+	rbacService "faceit/domain/rbac/service"
 	"faceit/domain/user/controller"
+	"faceit/domain/user/events"
+	"faceit/domain/user/events/sink"
+	"faceit/domain/user/purger"
+	"faceit/domain/user/relay"
 	"faceit/domain/user/repository"
+	"faceit/domain/user/search"
 	"faceit/domain/user/service"
+	"faceit/domain/user/utils"
+	webhookController "faceit/domain/webhook/controller"
+	"faceit/domain/webhook/dispatcher"
+	webhookRepository "faceit/domain/webhook/repository"
+	webhookService "faceit/domain/webhook/service"
 	"faceit/infrastructure/database"
+	"faceit/infrastructure/database/migration"
+	"faceit/infrastructure/hasher"
+	"faceit/infrastructure/idempotency"
 	"faceit/infrastructure/redis"
+	"faceit/infrastructure/server"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -37,11 +62,131 @@ func main() {
 		log.Fatalf("failed to get database ping: %s", err)
 	}
 
-	// create tables if they don't exist
-	if err := store.Migrate("up"); err != nil {
+	migrator, err := migration.NewMigrator(store.DB(), migration.Files)
+	if err != nil {
+		log.Fatalf("failed to load the migrations: %s", err)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(context.Background(), migrator, os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %s", err)
+		}
+		return
+	}
+
+	// never start against a schema newer than this binary or with missing migrations
+	if conf.Database.AutoMigrate {
+		err = migrator.Up(context.Background())
+	} else {
+		err = migrator.Check(context.Background())
+	}
+	if err != nil {
 		log.Fatalf("failed to migrate the schemas: %s", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "search" {
+		if err := runSearchCommand(context.Background(), conf, repository.NewUserRepository(store.DB()), repository.NewOutboxRepository(store.DB()), os.Args[2:]); err != nil {
+			log.Fatalf("search: %s", err)
+		}
+		return
+	}
+
+	eventPublisher, err := newEventPublisher(conf)
+	if err != nil {
+		log.Fatalf("failed to initialize the %s event sink: %s", conf.Events.Sink, err)
+	}
+
+	passwordHasher, err := hasher.NewHasher(
+		conf.Password.Algorithm,
+		&hasher.Argon2Params{
+			Memory:      conf.Password.Argon2.Memory,
+			Iterations:  conf.Password.Argon2.Iterations,
+			Parallelism: conf.Password.Argon2.Parallelism,
+			SaltLength:  conf.Password.Argon2.SaltLength,
+			KeyLength:   conf.Password.Argon2.KeyLength,
+		},
+		conf.Password.BcryptCost,
+	)
+	if err != nil {
+		log.Fatalf("failed to initialize password hasher: %s", err)
+	}
+
+	usersRepo := repository.NewUserRepository(store.DB())
+	rolesRepo := rbacRepository.NewRolesRepository(store.DB())
+	authorizer := rbacService.NewAuthorizer(rolesRepo)
+	rbacSvc := rbacService.NewRBACService(rolesRepo, usersRepo, authorizer)
+
+	subscriptionsRepo := webhookRepository.NewSubscriptionsRepository(store.DB())
+	deliveriesRepo := webhookRepository.NewDeliveriesRepository(store.DB())
+	webhookDispatcher := dispatcher.NewDispatcher(store.DB(), deliveriesRepo, dispatcher.Config{
+		PollInterval: time.Duration(conf.Webhooks.PollInterval) * time.Millisecond,
+		BatchSize:    conf.Webhooks.BatchSize,
+		Timeout:      time.Duration(conf.Webhooks.Timeout) * time.Millisecond,
+		MaxAttempts:  conf.Webhooks.MaxAttempts,
+		MinBackoff:   time.Duration(conf.Webhooks.MinBackoff) * time.Millisecond,
+		MaxBackoff:   time.Duration(conf.Webhooks.MaxBackoff) * time.Second,
+		Retention:    time.Duration(conf.Webhooks.Retention) * time.Hour,
+
+		AllowPrivateNetworks: conf.Webhooks.AllowPrivateNetworks,
+	})
+
+	outboxRepo := repository.NewOutboxRepository(store.DB())
+	searchIndex, err := openSearchIndex(context.Background(), conf, usersRepo, outboxRepo)
+	if err != nil {
+		log.Fatalf("failed to initialize the search index: %s", err)
+	}
+	// every replica keeps its own index in sync from the outbox, apart from the relay, which only runs in one of them
+	searchFollower := search.NewFollower(searchIndex, usersRepo, outboxRepo, search.FollowerConfig{
+		PollInterval: time.Duration(conf.Search.PollInterval) * time.Millisecond,
+		BatchSize:    conf.Search.BatchSize,
+		Settle:       time.Duration(conf.Search.Settle) * time.Second,
+	})
+
+	// the relay feeds the webhook subscriptions with the same events as the event sink
+	eventPublisher = sink.NewMultiPublisher(eventPublisher, dispatcher.NewFanout(deliveriesRepo))
+	eventsRelay := relay.NewRelay(store.DB(), outboxRepo, eventPublisher, relay.Config{
+		PollInterval: time.Duration(conf.Outbox.PollInterval) * time.Millisecond,
+		BatchSize:    conf.Outbox.BatchSize,
+		MinBackoff:   time.Duration(conf.Outbox.MinBackoff) * time.Millisecond,
+		MaxBackoff:   time.Duration(conf.Outbox.MaxBackoff) * time.Second,
+		MaxAttempts:  conf.Outbox.MaxAttempts,
+		Retention:    time.Duration(conf.Outbox.Retention) * time.Hour,
+	})
+	usersPurger := purger.NewPurger(usersRepo, purger.Config{
+		PollInterval: time.Duration(conf.Purge.PollInterval) * time.Second,
+		BatchSize:    conf.Purge.BatchSize,
+		Retention:    time.Duration(conf.Purge.Retention) * time.Hour,
+	})
+	cursorSecret := []byte(conf.Pages.CursorSecret)
+	if len(cursorSecret) == 0 {
+		log.Println("no cursor secret is configured, the cursors are only valid until the service restarts")
+		cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(cursorSecret); err != nil {
+			log.Fatalf("failed to generate the cursor secret: %s", err)
+		}
+	}
+	usersService := service.NewUserService(usersRepo, passwordHasher, authorizer, utils.NewCursorCodec(cursorSecret), searchIndex)
+	privateKey := []byte(conf.Auth.PrivateKey)
+	if conf.Auth.PrivateKeyFile != "" {
+		privateKey, err = os.ReadFile(conf.Auth.PrivateKeyFile)
+		if err != nil {
+			log.Fatalf("failed to read the private key: %s", err)
+		}
+	}
+
+	tokenManager, err := token.NewManager(
+		conf.Auth.Algorithm,
+		privateKey,
+		conf.Auth.Issuer,
+		time.Duration(conf.Auth.TokenTTL)*time.Second,
+	)
+	if err != nil {
+		log.Fatalf("failed to initialize token manager: %s", err)
+	}
+
+	authSvc := authService.NewAuthService(usersService, rbacSvc, tokenManager)
+	authCtrl := authController.NewAuthController(authSvc)
+	// the idempotency keys are shared by all the replicas, so they are kept in redis whatever the event sink is
 	redisConn, err := redis.NewRedis(
 		conf.Redis.DSN,
 		conf.Redis.InternalPoolTimeout,
@@ -50,14 +195,56 @@ func main() {
 		conf.Redis.WriteTimeout,
 	)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("failed to initialize the idempotency keys: %s", err)
+	}
+	idempotencyKeys := idempotency.NewStore(redisConn.Conn(), idempotency.Config{
+		TTL:         time.Duration(conf.Idempotency.TTL) * time.Hour,
+		LockTTL:     time.Duration(conf.Idempotency.LockTTL) * time.Second,
+		MaxBodySize: conf.Idempotency.MaxBodySize,
+	})
+	usersController := controller.NewUserController(usersService, store, authCtrl.Authenticate, idempotency.Middleware(idempotencyKeys))
+	auditCtrl := controller.NewAuditController(service.NewAuditService(repository.NewAuditRepository(store.DB()), authorizer), authCtrl.Authenticate)
+	rbacCtrl := rbacController.NewRBACController(rbacSvc, authorizer, authCtrl.Authenticate)
+	webhookSvc := webhookService.NewWebhookService(subscriptionsRepo, deliveriesRepo, authorizer, conf.Webhooks.AllowPrivateNetworks)
+	webhookCtrl := webhookController.NewWebhookController(webhookSvc, authCtrl.Authenticate)
+
+	httpServer, err := server.Run(conf.Service.Port, conf.Service.TrustedProxies, usersController, auditCtrl, authCtrl, rbacCtrl, webhookCtrl)
+	if err != nil {
+		log.Fatalf("failed to start the HTTP server: %s", err)
+	}
+	grpcServer, err := server.RunGRPC(
+		conf.Service.GRPCPort,
+		[]grpc.UnaryServerInterceptor{authCtrl.UnaryAuthenticate},
+		controller.NewUsersGRPCController(usersService),
+	)
+	if err != nil {
+		log.Fatalf("failed to start the gRPC server: %s", err)
 	}
 
-	usersRepo := repository.NewUserRepository(store.DB(), redisConn.Conn())
-	usersService := service.NewUserService(usersRepo)
-	usersController := controller.NewUserController(usersService, store)
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		eventsRelay.Run(relayCtx)
+	}()
 
-	server := usersController.Run(conf.Service.Port)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		webhookDispatcher.Run(relayCtx)
+	}()
+
+	purgerDone := make(chan struct{})
+	go func() {
+		defer close(purgerDone)
+		usersPurger.Run(relayCtx)
+	}()
+
+	searchDone := make(chan struct{})
+	go func() {
+		defer close(searchDone)
+		searchFollower.Run(relayCtx)
+	}()
 
 	waitForOsSignal()
 	log.Println("Shutting down server...")
@@ -66,13 +253,167 @@ func main() {
 	// the request it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
+	if err := httpServer.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
+	if err := grpcServer.Shutdown(ctx); err != nil {
+		log.Println("gRPC server forced to shutdown:", err)
+	}
+
+	// the pending events and deliveries stay in the database and are sent after the restart or by another replica
+	stopRelay()
+	<-relayDone
+	<-dispatcherDone
+	<-purgerDone
+	<-searchDone
+	if err := eventPublisher.Close(); err != nil {
+		log.Printf("failed to close the event sink: %s", err)
+	}
+	if err := searchIndex.Close(); err != nil {
+		log.Printf("failed to close the search index: %s", err)
+	}
 
 	log.Println("Server exiting")
 }
 
+// newEventPublisher - Connects to the sink the user events are published to
+func newEventPublisher(conf *config.Configs) (events.IEventPublisher, error) {
+	switch conf.Events.Sink {
+	case "redis":
+		redisConn, err := redis.NewRedis(
+			conf.Redis.DSN,
+			conf.Redis.InternalPoolTimeout,
+			conf.Redis.IdleTimeout,
+			conf.Redis.ReadTimeout,
+			conf.Redis.WriteTimeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return sink.NewRedisPublisher(redisConn.Conn(), sink.RedisConfig{
+			Stream:     conf.Events.Redis.Stream,
+			MaxLen:     conf.Events.Redis.MaxLen,
+			LegacyList: conf.Events.Redis.LegacyList,
+		}), nil
+	case "nats":
+		return sink.NewNATSPublisher(sink.NATSConfig{
+			URL:           conf.Events.NATS.URL,
+			SubjectPrefix: conf.Events.NATS.SubjectPrefix,
+			Timeout:       time.Duration(conf.Events.NATS.Timeout) * time.Millisecond,
+		})
+	case "webhook":
+		if conf.Events.Webhook.URL == "" {
+			return nil, fmt.Errorf("the webhook url is not set")
+		}
+
+		return sink.NewWebhookPublisher(sink.WebhookConfig{
+			URL:     conf.Events.Webhook.URL,
+			Timeout: time.Duration(conf.Events.Webhook.Timeout) * time.Millisecond,
+		}), nil
+	case "file":
+		return sink.NewFilePublisher(conf.Events.File.Path)
+	default:
+		return nil, fmt.Errorf("unknown event sink %q", conf.Events.Sink)
+	}
+}
+
+// openSearchIndex - Opens the search index, or builds it from the database if it's missing,
+// or if it's been out of sync for so long the events it missed may have been removed from the outbox
+func openSearchIndex(ctx context.Context, conf *config.Configs, usersRepo repository.IUsersRepository, outboxRepo repository.IOutboxRepository) (*search.Index, error) {
+	path := conf.Search.Path
+	settle := time.Duration(conf.Search.Settle) * time.Second
+	if search.Exists(path) {
+		index, err := search.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		position, err := index.Position()
+		if err != nil {
+			_ = index.Close()
+			return nil, err
+		}
+		if !search.IsStale(position, time.Duration(conf.Outbox.Retention)*time.Hour, settle) {
+			return index, nil
+		}
+
+		log.Printf("the search index %s is out of date", path)
+		if err := index.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("building the search index %s from the database", path)
+	count, err := search.Rebuild(ctx, path, usersRepo, outboxRepo, settle)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("indexed %d users", count)
+
+	return search.Open(path)
+}
+
+// runSearchCommand - Handles `search rebuild`, which must be run while the service is stopped as the index is locked while it's open
+func runSearchCommand(ctx context.Context, conf *config.Configs, usersRepo repository.IUsersRepository, outboxRepo repository.IOutboxRepository, args []string) error {
+	if len(args) != 1 || args[0] != "rebuild" {
+		return fmt.Errorf("usage: search rebuild")
+	}
+
+	count, err := search.Rebuild(ctx, conf.Search.Path, usersRepo, outboxRepo, time.Duration(conf.Search.Settle)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("indexed %d users in %s", count, conf.Search.Path)
+	return nil
+}
+
+// runMigrateCommand - Handles `migrate status`, `migrate up` and `migrate down <version>`
+func runMigrateCommand(ctx context.Context, migrator migration.IMigrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate status|up|down <version>")
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Unknown:
+				state = "unknown to this binary"
+			case status.Modified:
+				state = "modified after being applied"
+			case status.Applied:
+				state = "applied at " + status.AppliedAt.Format(time.RFC3339)
+			}
+			log.Printf("%04d_%s: %s", status.Version, status.Name, state)
+		}
+
+		return nil
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: migrate down <version>")
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+
+		return migrator.Down(ctx, version)
+	default:
+		return fmt.Errorf("unknown command %q, usage: migrate status|up|down <version>", args[0])
+	}
+}
+
 func waitForOsSignal() {
 	osSignal := make(chan os.Signal, 1)
 	signal.Notify(osSignal, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
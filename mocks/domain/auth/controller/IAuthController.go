@@ -0,0 +1,38 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuthController is an autogenerated mock type for the IAuthController type
+type IAuthController struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: c
+func (_m *IAuthController) Authenticate(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Login provides a mock function with given fields: c
+func (_m *IAuthController) Login(c *gin.Context) {
+	_m.Called(c)
+}
+
+type mockConstructorTestingTNewIAuthController interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIAuthController creates a new instance of IAuthController. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIAuthController(t mockConstructorTestingTNewIAuthController) *IAuthController {
+	mock := &IAuthController{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,77 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "faceit/domain/auth/dto"
+	entity "faceit/domain/auth/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuthService is an autogenerated mock type for the IAuthService type
+type IAuthService struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: ctx, accessToken
+func (_m *IAuthService) Authenticate(ctx context.Context, accessToken string) (*entity.Principal, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 *entity.Principal
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Principal); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Principal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Login provides a mock function with given fields: ctx, login, password
+func (_m *IAuthService) Login(ctx context.Context, login string, password string) (*dto.Token, error) {
+	ret := _m.Called(ctx, login, password)
+
+	var r0 *dto.Token
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.Token); ok {
+		r0 = rf(ctx, login, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.Token)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, login, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIAuthService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIAuthService creates a new instance of IAuthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIAuthService(t mockConstructorTestingTNewIAuthService) *IAuthService {
+	mock := &IAuthService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,81 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	token "faceit/domain/auth/token"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IManager is an autogenerated mock type for the IManager type
+type IManager struct {
+	mock.Mock
+}
+
+// Issue provides a mock function with given fields: userID, nickName, roles
+func (_m *IManager) Issue(userID int64, nickName string, roles []string) (string, time.Time, error) {
+	ret := _m.Called(userID, nickName, roles)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(int64, string, []string) string); ok {
+		r0 = rf(userID, nickName, roles)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 time.Time
+	if rf, ok := ret.Get(1).(func(int64, string, []string) time.Time); ok {
+		r1 = rf(userID, nickName, roles)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(int64, string, []string) error); ok {
+		r2 = rf(userID, nickName, roles)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Parse provides a mock function with given fields: accessToken
+func (_m *IManager) Parse(accessToken string) (*token.Claims, error) {
+	ret := _m.Called(accessToken)
+
+	var r0 *token.Claims
+	if rf, ok := ret.Get(0).(func(string) *token.Claims); ok {
+		r0 = rf(accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*token.Claims)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIManager interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIManager creates a new instance of IManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIManager(t mockConstructorTestingTNewIManager) *IManager {
+	mock := &IManager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,63 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IRBACController is an autogenerated mock type for the IRBACController type
+type IRBACController struct {
+	mock.Mock
+}
+
+// AssignRole provides a mock function with given fields: c
+func (_m *IRBACController) AssignRole(c *gin.Context) {
+	_m.Called(c)
+}
+
+// CreateRole provides a mock function with given fields: c
+func (_m *IRBACController) CreateRole(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetPermissions provides a mock function with given fields: c
+func (_m *IRBACController) GetPermissions(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetRoles provides a mock function with given fields: c
+func (_m *IRBACController) GetRoles(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetUserRoles provides a mock function with given fields: c
+func (_m *IRBACController) GetUserRoles(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RemoveRole provides a mock function with given fields: c
+func (_m *IRBACController) RemoveRole(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RevokeRole provides a mock function with given fields: c
+func (_m *IRBACController) RevokeRole(c *gin.Context) {
+	_m.Called(c)
+}
+
+type mockConstructorTestingTNewIRBACController interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIRBACController creates a new instance of IRBACController. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIRBACController(t mockConstructorTestingTNewIRBACController) *IRBACController {
+	mock := &IRBACController{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,199 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "faceit/domain/rbac/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IRolesRepository is an autogenerated mock type for the IRolesRepository type
+type IRolesRepository struct {
+	mock.Mock
+}
+
+// AssignRole provides a mock function with given fields: ctx, userID, role
+func (_m *IRolesRepository) AssignRole(ctx context.Context, userID int64, role string) error {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateRole provides a mock function with given fields: ctx, role
+func (_m *IRolesRepository) CreateRole(ctx context.Context, role *entity.Role) error {
+	ret := _m.Called(ctx, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Role) error); ok {
+		r0 = rf(ctx, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPermissions provides a mock function with given fields: ctx
+func (_m *IRolesRepository) GetPermissions(ctx context.Context) ([]*entity.Permission, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*entity.Permission
+	if rf, ok := ret.Get(0).(func(context.Context) []*entity.Permission); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Permission)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRole provides a mock function with given fields: ctx, name
+func (_m *IRolesRepository) GetRole(ctx context.Context, name string) (*entity.Role, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *entity.Role
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Role); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRoles provides a mock function with given fields: ctx
+func (_m *IRolesRepository) GetRoles(ctx context.Context) ([]*entity.Role, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*entity.Role
+	if rf, ok := ret.Get(0).(func(context.Context) []*entity.Role); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserRoles provides a mock function with given fields: ctx, userID
+func (_m *IRolesRepository) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HasPermission provides a mock function with given fields: ctx, roles, permission
+func (_m *IRolesRepository) HasPermission(ctx context.Context, roles []string, permission string) (bool, error) {
+	ret := _m.Called(ctx, roles, permission)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, []string, string) bool); ok {
+		r0 = rf(ctx, roles, permission)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string, string) error); ok {
+		r1 = rf(ctx, roles, permission)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveRole provides a mock function with given fields: ctx, name
+func (_m *IRolesRepository) RemoveRole(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeRole provides a mock function with given fields: ctx, userID, role
+func (_m *IRolesRepository) RevokeRole(ctx context.Context, userID int64, role string) error {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewIRolesRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIRolesRepository creates a new instance of IRolesRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIRolesRepository(t mockConstructorTestingTNewIRolesRepository) *IRolesRepository {
+	mock := &IRolesRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,92 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuthorizer is an autogenerated mock type for the IAuthorizer type
+type IAuthorizer struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: ctx, permission
+func (_m *IAuthorizer) Authorize(ctx context.Context, permission string) error {
+	ret := _m.Called(ctx, permission)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AuthorizeCredentials provides a mock function with given fields: ctx, userID
+func (_m *IAuthorizer) AuthorizeCredentials(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AuthorizeUser provides a mock function with given fields: ctx, userID, permission
+func (_m *IAuthorizer) AuthorizeUser(ctx context.Context, userID int64, permission string) error {
+	ret := _m.Called(ctx, userID, permission)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, permission)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// HasPermission provides a mock function with given fields: ctx, permission
+func (_m *IAuthorizer) HasPermission(ctx context.Context, permission string) (bool, error) {
+	ret := _m.Called(ctx, permission)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, permission)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, permission)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIAuthorizer interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIAuthorizer creates a new instance of IAuthorizer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIAuthorizer(t mockConstructorTestingTNewIAuthorizer) *IAuthorizer {
+	mock := &IAuthorizer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
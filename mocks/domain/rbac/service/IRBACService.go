@@ -0,0 +1,164 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "faceit/domain/rbac/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IRBACService is an autogenerated mock type for the IRBACService type
+type IRBACService struct {
+	mock.Mock
+}
+
+// AssignRole provides a mock function with given fields: ctx, userID, role
+func (_m *IRBACService) AssignRole(ctx context.Context, userID int64, role string) error {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateRole provides a mock function with given fields: ctx, role
+func (_m *IRBACService) CreateRole(ctx context.Context, role *dto.Role) (*dto.Role, error) {
+	ret := _m.Called(ctx, role)
+
+	var r0 *dto.Role
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.Role) *dto.Role); ok {
+		r0 = rf(ctx, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.Role) error); ok {
+		r1 = rf(ctx, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPermissions provides a mock function with given fields: ctx
+func (_m *IRBACService) GetPermissions(ctx context.Context) ([]*dto.Permission, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*dto.Permission
+	if rf, ok := ret.Get(0).(func(context.Context) []*dto.Permission); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.Permission)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRoles provides a mock function with given fields: ctx
+func (_m *IRBACService) GetRoles(ctx context.Context) ([]*dto.Role, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*dto.Role
+	if rf, ok := ret.Get(0).(func(context.Context) []*dto.Role); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.Role)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserRoles provides a mock function with given fields: ctx, userID
+func (_m *IRBACService) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveRole provides a mock function with given fields: ctx, name
+func (_m *IRBACService) RemoveRole(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeRole provides a mock function with given fields: ctx, userID, role
+func (_m *IRBACService) RevokeRole(ctx context.Context, userID int64, role string) error {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewIRBACService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIRBACService creates a new instance of IRBACService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIRBACService(t mockConstructorTestingTNewIRBACService) *IRBACService {
+	mock := &IRBACService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,38 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuditController is an autogenerated mock type for the IAuditController type
+type IAuditController struct {
+	mock.Mock
+}
+
+// History provides a mock function with given fields: c
+func (_m *IAuditController) History(c *gin.Context) {
+	_m.Called(c)
+}
+
+// List provides a mock function with given fields: c
+func (_m *IAuditController) List(c *gin.Context) {
+	_m.Called(c)
+}
+
+type mockConstructorTestingTNewIAuditController interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIAuditController creates a new instance of IAuditController. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIAuditController(t mockConstructorTestingTNewIAuditController) *IAuditController {
+	mock := &IAuditController{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
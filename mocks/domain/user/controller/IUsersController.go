@@ -12,13 +12,48 @@ type IUsersController struct {
 	mock.Mock
 }
 
+// BatchGet provides a mock function with given fields: c
+func (_m *IUsersController) BatchGet(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Create provides a mock function with given fields: c
 func (_m *IUsersController) Create(c *gin.Context) {
 	_m.Called(c)
 }
 
-// Get provides a mock function with given fields: c
-func (_m *IUsersController) Get(c *gin.Context) {
+// CreateDeprecated provides a mock function with given fields: c
+func (_m *IUsersController) CreateDeprecated(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetByID provides a mock function with given fields: c
+func (_m *IUsersController) GetByID(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetDeprecated provides a mock function with given fields: c
+func (_m *IUsersController) GetDeprecated(c *gin.Context) {
+	_m.Called(c)
+}
+
+// List provides a mock function with given fields: c
+func (_m *IUsersController) List(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ListDeleted provides a mock function with given fields: c
+func (_m *IUsersController) ListDeleted(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Lookup provides a mock function with given fields: c
+func (_m *IUsersController) Lookup(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Patch provides a mock function with given fields: c
+func (_m *IUsersController) Patch(c *gin.Context) {
 	_m.Called(c)
 }
 
@@ -27,8 +62,18 @@ func (_m *IUsersController) Remove(c *gin.Context) {
 	_m.Called(c)
 }
 
-// Update provides a mock function with given fields: c
-func (_m *IUsersController) Update(c *gin.Context) {
+// Replace provides a mock function with given fields: c
+func (_m *IUsersController) Replace(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Restore provides a mock function with given fields: c
+func (_m *IUsersController) Restore(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UpdateDeprecated provides a mock function with given fields: c
+func (_m *IUsersController) UpdateDeprecated(c *gin.Context) {
 	_m.Called(c)
 }
 
@@ -0,0 +1,58 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	events "faceit/domain/user/events"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IEventPublisher is an autogenerated mock type for the IEventPublisher type
+type IEventPublisher struct {
+	mock.Mock
+}
+
+// Close provides a mock function with given fields:
+func (_m *IEventPublisher) Close() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Publish provides a mock function with given fields: ctx, event
+func (_m *IEventPublisher) Publish(ctx context.Context, event *events.Event) error {
+	ret := _m.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *events.Event) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewIEventPublisher interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIEventPublisher creates a new instance of IEventPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIEventPublisher(t mockConstructorTestingTNewIEventPublisher) *IEventPublisher {
+	mock := &IEventPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
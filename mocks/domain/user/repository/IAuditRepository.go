@@ -0,0 +1,53 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "faceit/domain/user/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuditRepository is an autogenerated mock type for the IAuditRepository type
+type IAuditRepository struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, filter, page, pageSize
+func (_m *IAuditRepository) Get(ctx context.Context, filter *entity.AuditFilter, page int64, pageSize int64) ([]*entity.AuditEntry, error) {
+	ret := _m.Called(ctx, filter, page, pageSize)
+
+	var r0 []*entity.AuditEntry
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuditFilter, int64, int64) []*entity.AuditEntry); ok {
+		r0 = rf(ctx, filter, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.AuditEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.AuditFilter, int64, int64) error); ok {
+		r1 = rf(ctx, filter, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIAuditRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIAuditRepository creates a new instance of IAuditRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIAuditRepository(t mockConstructorTestingTNewIAuditRepository) *IAuditRepository {
+	mock := &IAuditRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,162 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "faceit/domain/user/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IOutboxRepository is an autogenerated mock type for the IOutboxRepository type
+type IOutboxRepository struct {
+	mock.Mock
+}
+
+// DeleteDelivered provides a mock function with given fields: ctx, before, limit
+func (_m *IOutboxRepository) DeleteDelivered(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) int64); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEventsAfter provides a mock function with given fields: ctx, afterID, settle, limit
+func (_m *IOutboxRepository) GetEventsAfter(ctx context.Context, afterID int64, settle time.Duration, limit int64) ([]*entity.OutboxEvent, error) {
+	ret := _m.Called(ctx, afterID, settle, limit)
+
+	var r0 []*entity.OutboxEvent
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Duration, int64) []*entity.OutboxEvent); ok {
+		r0 = rf(ctx, afterID, settle, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.OutboxEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Duration, int64) error); ok {
+		r1 = rf(ctx, afterID, settle, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastSettledEventID provides a mock function with given fields: ctx, settle
+func (_m *IOutboxRepository) GetLastSettledEventID(ctx context.Context, settle time.Duration) (int64, error) {
+	ret := _m.Called(ctx, settle)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) int64); ok {
+		r0 = rf(ctx, settle)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, settle)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingEvents provides a mock function with given fields: ctx, now, limit
+func (_m *IOutboxRepository) GetPendingEvents(ctx context.Context, now time.Time, limit int64) ([]*entity.OutboxEvent, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	var r0 []*entity.OutboxEvent
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) []*entity.OutboxEvent); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.OutboxEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkDead provides a mock function with given fields: ctx, ID, reason
+func (_m *IOutboxRepository) MarkDead(ctx context.Context, ID int64, reason string) error {
+	ret := _m.Called(ctx, ID, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, ID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkDelivered provides a mock function with given fields: ctx, ID
+func (_m *IOutboxRepository) MarkDelivered(ctx context.Context, ID int64) error {
+	ret := _m.Called(ctx, ID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkFailed provides a mock function with given fields: ctx, ID, reason, nextAttemptAt
+func (_m *IOutboxRepository) MarkFailed(ctx context.Context, ID int64, reason string, nextAttemptAt time.Time) error {
+	ret := _m.Called(ctx, ID, reason, nextAttemptAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, ID, reason, nextAttemptAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewIOutboxRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIOutboxRepository creates a new instance of IOutboxRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIOutboxRepository(t mockConstructorTestingTNewIOutboxRepository) *IOutboxRepository {
+	mock := &IOutboxRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -5,8 +5,11 @@ package mocks
 import (
 	context "context"
 	entity "faceit/domain/user/entity"
+	events "faceit/domain/user/events"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // IUsersRepository is an autogenerated mock type for the IUsersRepository type
@@ -14,13 +17,13 @@ type IUsersRepository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: ctx, user
-func (_m *IUsersRepository) Create(ctx context.Context, user *entity.User) (*entity.User, error) {
-	ret := _m.Called(ctx, user)
+// Create provides a mock function with given fields: ctx, user, event
+func (_m *IUsersRepository) Create(ctx context.Context, user *entity.User, event *events.Event) (*entity.User, error) {
+	ret := _m.Called(ctx, user, event)
 
 	var r0 *entity.User
-	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) *entity.User); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, *events.Event) *entity.User); ok {
+		r0 = rf(ctx, user, event)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*entity.User)
@@ -28,8 +31,8 @@ func (_m *IUsersRepository) Create(ctx context.Context, user *entity.User) (*ent
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *entity.User) error); ok {
-		r1 = rf(ctx, user)
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.User, *events.Event) error); ok {
+		r1 = rf(ctx, user, event)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -37,13 +40,13 @@ func (_m *IUsersRepository) Create(ctx context.Context, user *entity.User) (*ent
 	return r0, r1
 }
 
-// Get provides a mock function with given fields: ctx, filter, page, pageSize
-func (_m *IUsersRepository) Get(ctx context.Context, filter *entity.Filter, page int64, pageSize int64) ([]*entity.User, error) {
-	ret := _m.Called(ctx, filter, page, pageSize)
+// Get provides a mock function with given fields: ctx, filter, sort, page, pageSize
+func (_m *IUsersRepository) Get(ctx context.Context, filter *entity.Filter, sort []entity.SortKey, page int64, pageSize int64) ([]*entity.User, error) {
+	ret := _m.Called(ctx, filter, sort, page, pageSize)
 
 	var r0 []*entity.User
-	if rf, ok := ret.Get(0).(func(context.Context, *entity.Filter, int64, int64) []*entity.User); ok {
-		r0 = rf(ctx, filter, page, pageSize)
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Filter, []entity.SortKey, int64, int64) []*entity.User); ok {
+		r0 = rf(ctx, filter, sort, page, pageSize)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*entity.User)
@@ -51,8 +54,8 @@ func (_m *IUsersRepository) Get(ctx context.Context, filter *entity.Filter, page
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *entity.Filter, int64, int64) error); ok {
-		r1 = rf(ctx, filter, page, pageSize)
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.Filter, []entity.SortKey, int64, int64) error); ok {
+		r1 = rf(ctx, filter, sort, page, pageSize)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -106,6 +109,29 @@ func (_m *IUsersRepository) GetByID(ctx context.Context, ID int64) (*entity.User
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ctx, IDs
+func (_m *IUsersRepository) GetByIDs(ctx context.Context, IDs []int64) ([]*entity.User, error) {
+	ret := _m.Called(ctx, IDs)
+
+	var r0 []*entity.User
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*entity.User); ok {
+		r0 = rf(ctx, IDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, IDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByNickName provides a mock function with given fields: ctx, nickName
 func (_m *IUsersRepository) GetByNickName(ctx context.Context, nickName string) (*entity.User, error) {
 	ret := _m.Called(ctx, nickName)
@@ -150,13 +176,103 @@ func (_m *IUsersRepository) GetCount(ctx context.Context, filter *entity.Filter)
 	return r0, r1
 }
 
-// PublishUserChangeEvent provides a mock function with given fields: userID
-func (_m *IUsersRepository) PublishUserChangeEvent(userID int64) error {
-	ret := _m.Called(userID)
+// GetDeleted provides a mock function with given fields: ctx, page, pageSize
+func (_m *IUsersRepository) GetDeleted(ctx context.Context, page int64, pageSize int64) ([]*entity.User, error) {
+	ret := _m.Called(ctx, page, pageSize)
+
+	var r0 []*entity.User
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*entity.User); ok {
+		r0 = rf(ctx, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeletedCount provides a mock function with given fields: ctx
+func (_m *IUsersRepository) GetDeletedCount(ctx context.Context) (uint64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context) uint64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExpiredIDs provides a mock function with given fields: ctx, deletedBefore, limit
+func (_m *IUsersRepository) GetExpiredIDs(ctx context.Context, deletedBefore time.Time, limit int64) ([]int64, error) {
+	ret := _m.Called(ctx, deletedBefore, limit)
+
+	var r0 []int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) []int64); ok {
+		r0 = rf(ctx, deletedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, deletedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPage provides a mock function with given fields: ctx, filter, sort, cursor, limit
+func (_m *IUsersRepository) GetPage(ctx context.Context, filter *entity.Filter, sort []entity.SortKey, cursor *entity.Cursor, limit int64) ([]*entity.User, error) {
+	ret := _m.Called(ctx, filter, sort, cursor, limit)
+
+	var r0 []*entity.User
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Filter, []entity.SortKey, *entity.Cursor, int64) []*entity.User); ok {
+		r0 = rf(ctx, filter, sort, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *entity.Filter, []entity.SortKey, *entity.Cursor, int64) error); ok {
+		r1 = rf(ctx, filter, sort, cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, ID, deletedBefore, event
+func (_m *IUsersRepository) Purge(ctx context.Context, ID int64, deletedBefore time.Time, event *events.Event) error {
+	ret := _m.Called(ctx, ID, deletedBefore, event)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int64) error); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, *events.Event) error); ok {
+		r0 = rf(ctx, ID, deletedBefore, event)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -164,13 +280,41 @@ func (_m *IUsersRepository) PublishUserChangeEvent(userID int64) error {
 	return r0
 }
 
-// Remove provides a mock function with given fields: ctx, ID
-func (_m *IUsersRepository) Remove(ctx context.Context, ID int64) error {
-	ret := _m.Called(ctx, ID)
+// Remove provides a mock function with given fields: ctx, ID, event
+func (_m *IUsersRepository) Remove(ctx context.Context, ID int64, event *events.Event) error {
+	ret := _m.Called(ctx, ID, event)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(ctx, ID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *events.Event) error); ok {
+		r0 = rf(ctx, ID, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Restore provides a mock function with given fields: ctx, ID, event
+func (_m *IUsersRepository) Restore(ctx context.Context, ID int64, event *events.Event) error {
+	ret := _m.Called(ctx, ID, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *events.Event) error); ok {
+		r0 = rf(ctx, ID, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, user, event
+func (_m *IUsersRepository) Update(ctx context.Context, user *entity.User, event *events.Event) error {
+	ret := _m.Called(ctx, user, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.User, *events.Event) error); ok {
+		r0 = rf(ctx, user, event)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -178,13 +322,13 @@ func (_m *IUsersRepository) Remove(ctx context.Context, ID int64) error {
 	return r0
 }
 
-// Update provides a mock function with given fields: ctx, user
-func (_m *IUsersRepository) Update(ctx context.Context, user *entity.User) error {
-	ret := _m.Called(ctx, user)
+// UpdatePassword provides a mock function with given fields: ctx, ID, version, passwordHash
+func (_m *IUsersRepository) UpdatePassword(ctx context.Context, ID int64, version int64, passwordHash string) error {
+	ret := _m.Called(ctx, ID, version, passwordHash)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *entity.User) error); ok {
-		r0 = rf(ctx, user)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) error); ok {
+		r0 = rf(ctx, ID, version, passwordHash)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,102 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "faceit/domain/user/entity"
+	search "faceit/domain/user/search"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IIndex is an autogenerated mock type for the IIndex type
+type IIndex struct {
+	mock.Mock
+}
+
+// Close provides a mock function with given fields:
+func (_m *IIndex) Close() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ID
+func (_m *IIndex) Delete(ID int64) error {
+	ret := _m.Called(ID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Index provides a mock function with given fields: user
+func (_m *IIndex) Index(user *entity.User) error {
+	ret := _m.Called(user)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*entity.User) error); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: text, fields, limit
+func (_m *IIndex) Search(text string, fields []string, limit int) ([]search.Hit, uint64, error) {
+	ret := _m.Called(text, fields, limit)
+
+	var r0 []search.Hit
+	if rf, ok := ret.Get(0).(func(string, []string, int) []search.Hit); ok {
+		r0 = rf(text, fields, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]search.Hit)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(string, []string, int) uint64); ok {
+		r1 = rf(text, fields, limit)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, []string, int) error); ok {
+		r2 = rf(text, fields, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+type mockConstructorTestingTNewIIndex interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIIndex creates a new instance of IIndex. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIIndex(t mockConstructorTestingTNewIIndex) *IIndex {
+	mock := &IIndex{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,76 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "faceit/domain/user/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IAuditService is an autogenerated mock type for the IAuditService type
+type IAuditService struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, filter, page, pageSize
+func (_m *IAuditService) Get(ctx context.Context, filter *dto.AuditFilter, page int64, pageSize int64) ([]*dto.AuditEntry, error) {
+	ret := _m.Called(ctx, filter, page, pageSize)
+
+	var r0 []*dto.AuditEntry
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.AuditFilter, int64, int64) []*dto.AuditEntry); ok {
+		r0 = rf(ctx, filter, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.AuditEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.AuditFilter, int64, int64) error); ok {
+		r1 = rf(ctx, filter, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistory provides a mock function with given fields: ctx, userID, page, pageSize
+func (_m *IAuditService) GetHistory(ctx context.Context, userID int64, page int64, pageSize int64) ([]*dto.AuditEntry, error) {
+	ret := _m.Called(ctx, userID, page, pageSize)
+
+	var r0 []*dto.AuditEntry
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) []*dto.AuditEntry); ok {
+		r0 = rf(ctx, userID, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.AuditEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(ctx, userID, page, pageSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIAuditService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIAuditService creates a new instance of IAuditService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIAuditService(t mockConstructorTestingTNewIAuditService) *IAuditService {
+	mock := &IAuditService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -14,6 +14,29 @@ type IUserService struct {
 	mock.Mock
 }
 
+// Authenticate provides a mock function with given fields: ctx, login, password
+func (_m *IUserService) Authenticate(ctx context.Context, login string, password string) (*dto.User, error) {
+	ret := _m.Called(ctx, login, password)
+
+	var r0 *dto.User
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *dto.User); ok {
+		r0 = rf(ctx, login, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, login, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, user, password
 func (_m *IUserService) Create(ctx context.Context, user *dto.User, password string) (*dto.User, error) {
 	ret := _m.Called(ctx, user, password)
@@ -37,13 +60,13 @@ func (_m *IUserService) Create(ctx context.Context, user *dto.User, password str
 	return r0, r1
 }
 
-// Get provides a mock function with given fields: ctx, filter, page, pageSize
-func (_m *IUserService) Get(ctx context.Context, filter *dto.Filter, page int64, pageSize int64) ([]*dto.User, uint64, error) {
-	ret := _m.Called(ctx, filter, page, pageSize)
+// Get provides a mock function with given fields: ctx, filter, sort, page, pageSize
+func (_m *IUserService) Get(ctx context.Context, filter *dto.Filter, sort []dto.SortKey, page int64, pageSize int64) ([]*dto.User, uint64, error) {
+	ret := _m.Called(ctx, filter, sort, page, pageSize)
 
 	var r0 []*dto.User
-	if rf, ok := ret.Get(0).(func(context.Context, *dto.Filter, int64, int64) []*dto.User); ok {
-		r0 = rf(ctx, filter, page, pageSize)
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.Filter, []dto.SortKey, int64, int64) []*dto.User); ok {
+		r0 = rf(ctx, filter, sort, page, pageSize)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*dto.User)
@@ -51,15 +74,93 @@ func (_m *IUserService) Get(ctx context.Context, filter *dto.Filter, page int64,
 	}
 
 	var r1 uint64
-	if rf, ok := ret.Get(1).(func(context.Context, *dto.Filter, int64, int64) uint64); ok {
-		r1 = rf(ctx, filter, page, pageSize)
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.Filter, []dto.SortKey, int64, int64) uint64); ok {
+		r1 = rf(ctx, filter, sort, page, pageSize)
 	} else {
 		r1 = ret.Get(1).(uint64)
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, *dto.Filter, int64, int64) error); ok {
-		r2 = rf(ctx, filter, page, pageSize)
+	if rf, ok := ret.Get(2).(func(context.Context, *dto.Filter, []dto.SortKey, int64, int64) error); ok {
+		r2 = rf(ctx, filter, sort, page, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetByEmail provides a mock function with given fields: ctx, email
+func (_m *IUserService) GetByEmail(ctx context.Context, email string) (*dto.User, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 *dto.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByID provides a mock function with given fields: ctx, id
+func (_m *IUserService) GetByID(ctx context.Context, id int64) (*dto.User, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *dto.User
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *dto.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByIDs provides a mock function with given fields: ctx, IDs
+func (_m *IUserService) GetByIDs(ctx context.Context, IDs []int64) ([]*dto.User, []int64, error) {
+	ret := _m.Called(ctx, IDs)
+
+	var r0 []*dto.User
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []*dto.User); ok {
+		r0 = rf(ctx, IDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.User)
+		}
+	}
+
+	var r1 []int64
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) []int64); ok {
+		r1 = rf(ctx, IDs)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]int64)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, []int64) error); ok {
+		r2 = rf(ctx, IDs)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -67,6 +168,82 @@ func (_m *IUserService) Get(ctx context.Context, filter *dto.Filter, page int64,
 	return r0, r1, r2
 }
 
+// GetByNickName provides a mock function with given fields: ctx, nickName
+func (_m *IUserService) GetByNickName(ctx context.Context, nickName string) (*dto.User, error) {
+	ret := _m.Called(ctx, nickName)
+
+	var r0 *dto.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) *dto.User); ok {
+		r0 = rf(ctx, nickName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nickName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeleted provides a mock function with given fields: ctx, page, pageSize
+func (_m *IUserService) GetDeleted(ctx context.Context, page int64, pageSize int64) ([]*dto.User, uint64, error) {
+	ret := _m.Called(ctx, page, pageSize)
+
+	var r0 []*dto.User
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*dto.User); ok {
+		r0 = rf(ctx, page, pageSize)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.User)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) uint64); ok {
+		r1 = rf(ctx, page, pageSize)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = rf(ctx, page, pageSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetPage provides a mock function with given fields: ctx, filter, sort, cursor, pageSize, withCount
+func (_m *IUserService) GetPage(ctx context.Context, filter *dto.Filter, sort []dto.SortKey, cursor string, pageSize int64, withCount bool) (*dto.Page, error) {
+	ret := _m.Called(ctx, filter, sort, cursor, pageSize, withCount)
+
+	var r0 *dto.Page
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.Filter, []dto.SortKey, string, int64, bool) *dto.Page); ok {
+		r0 = rf(ctx, filter, sort, cursor, pageSize, withCount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.Page)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.Filter, []dto.SortKey, string, int64, bool) error); ok {
+		r1 = rf(ctx, filter, sort, cursor, pageSize, withCount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Remove provides a mock function with given fields: ctx, id
 func (_m *IUserService) Remove(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
@@ -81,6 +258,43 @@ func (_m *IUserService) Remove(ctx context.Context, id int64) error {
 	return r0
 }
 
+// Restore provides a mock function with given fields: ctx, id
+func (_m *IUserService) Restore(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: ctx, text, limit
+func (_m *IUserService) Search(ctx context.Context, text string, limit int) (*dto.SearchResult, error) {
+	ret := _m.Called(ctx, text, limit)
+
+	var r0 *dto.SearchResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *dto.SearchResult); ok {
+		r0 = rf(ctx, text, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.SearchResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, text, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, user, password
 func (_m *IUserService) Update(ctx context.Context, user *dto.User, password string) error {
 	ret := _m.Called(ctx, user, password)
@@ -0,0 +1,63 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	gin "github.com/gin-gonic/gin"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IWebhookController is an autogenerated mock type for the IWebhookController type
+type IWebhookController struct {
+	mock.Mock
+}
+
+// CreateSubscription provides a mock function with given fields: c
+func (_m *IWebhookController) CreateSubscription(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetDeliveries provides a mock function with given fields: c
+func (_m *IWebhookController) GetDeliveries(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetSubscription provides a mock function with given fields: c
+func (_m *IWebhookController) GetSubscription(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetSubscriptions provides a mock function with given fields: c
+func (_m *IWebhookController) GetSubscriptions(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RemoveSubscription provides a mock function with given fields: c
+func (_m *IWebhookController) RemoveSubscription(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RetryDelivery provides a mock function with given fields: c
+func (_m *IWebhookController) RetryDelivery(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UpdateSubscription provides a mock function with given fields: c
+func (_m *IWebhookController) UpdateSubscription(c *gin.Context) {
+	_m.Called(c)
+}
+
+type mockConstructorTestingTNewIWebhookController interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIWebhookController creates a new instance of IWebhookController. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIWebhookController(t mockConstructorTestingTNewIWebhookController) *IWebhookController {
+	mock := &IWebhookController{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
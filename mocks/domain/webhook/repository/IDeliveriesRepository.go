@@ -0,0 +1,177 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	events "faceit/domain/user/events"
+	entity "faceit/domain/webhook/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IDeliveriesRepository is an autogenerated mock type for the IDeliveriesRepository type
+type IDeliveriesRepository struct {
+	mock.Mock
+}
+
+// DeleteDelivered provides a mock function with given fields: ctx, before, limit
+func (_m *IDeliveriesRepository) DeleteDelivered(ctx context.Context, before time.Time, limit int64) (int64, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) int64); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnqueueDeliveries provides a mock function with given fields: ctx, event
+func (_m *IDeliveriesRepository) EnqueueDeliveries(ctx context.Context, event *events.Event) (int64, error) {
+	ret := _m.Called(ctx, event)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, *events.Event) int64); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *events.Event) error); ok {
+		r1 = rf(ctx, event)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeliveries provides a mock function with given fields: ctx, subscriptionID, status, limit
+func (_m *IDeliveriesRepository) GetDeliveries(ctx context.Context, subscriptionID int64, status string, limit int64) ([]*entity.Delivery, error) {
+	ret := _m.Called(ctx, subscriptionID, status, limit)
+
+	var r0 []*entity.Delivery
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64) []*entity.Delivery); ok {
+		r0 = rf(ctx, subscriptionID, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Delivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int64) error); ok {
+		r1 = rf(ctx, subscriptionID, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingDeliveries provides a mock function with given fields: ctx, now, limit
+func (_m *IDeliveriesRepository) GetPendingDeliveries(ctx context.Context, now time.Time, limit int64) ([]*entity.Delivery, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	var r0 []*entity.Delivery
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) []*entity.Delivery); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Delivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkDead provides a mock function with given fields: ctx, ID, responseStatus, reason
+func (_m *IDeliveriesRepository) MarkDead(ctx context.Context, ID int64, responseStatus int, reason string) error {
+	ret := _m.Called(ctx, ID, responseStatus, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, string) error); ok {
+		r0 = rf(ctx, ID, responseStatus, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkDelivered provides a mock function with given fields: ctx, ID, responseStatus
+func (_m *IDeliveriesRepository) MarkDelivered(ctx context.Context, ID int64, responseStatus int) error {
+	ret := _m.Called(ctx, ID, responseStatus)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) error); ok {
+		r0 = rf(ctx, ID, responseStatus)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkFailed provides a mock function with given fields: ctx, ID, responseStatus, reason, nextAttemptAt
+func (_m *IDeliveriesRepository) MarkFailed(ctx context.Context, ID int64, responseStatus int, reason string, nextAttemptAt time.Time) error {
+	ret := _m.Called(ctx, ID, responseStatus, reason, nextAttemptAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, string, time.Time) error); ok {
+		r0 = rf(ctx, ID, responseStatus, reason, nextAttemptAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RetryDead provides a mock function with given fields: ctx, subscriptionID, ID
+func (_m *IDeliveriesRepository) RetryDead(ctx context.Context, subscriptionID int64, ID int64) error {
+	ret := _m.Called(ctx, subscriptionID, ID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, subscriptionID, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewIDeliveriesRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIDeliveriesRepository creates a new instance of IDeliveriesRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIDeliveriesRepository(t mockConstructorTestingTNewIDeliveriesRepository) *IDeliveriesRepository {
+	mock := &IDeliveriesRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,118 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	entity "faceit/domain/webhook/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ISubscriptionsRepository is an autogenerated mock type for the ISubscriptionsRepository type
+type ISubscriptionsRepository struct {
+	mock.Mock
+}
+
+// CreateSubscription provides a mock function with given fields: ctx, subscription
+func (_m *ISubscriptionsRepository) CreateSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	ret := _m.Called(ctx, subscription)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Subscription) error); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSubscription provides a mock function with given fields: ctx, ID
+func (_m *ISubscriptionsRepository) GetSubscription(ctx context.Context, ID int64) (*entity.Subscription, error) {
+	ret := _m.Called(ctx, ID)
+
+	var r0 *entity.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *entity.Subscription); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptions provides a mock function with given fields: ctx
+func (_m *ISubscriptionsRepository) GetSubscriptions(ctx context.Context) ([]*entity.Subscription, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*entity.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context) []*entity.Subscription); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*entity.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSubscription provides a mock function with given fields: ctx, ID
+func (_m *ISubscriptionsRepository) RemoveSubscription(ctx context.Context, ID int64) error {
+	ret := _m.Called(ctx, ID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSubscription provides a mock function with given fields: ctx, subscription
+func (_m *ISubscriptionsRepository) UpdateSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	ret := _m.Called(ctx, subscription)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Subscription) error); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+type mockConstructorTestingTNewISubscriptionsRepository interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewISubscriptionsRepository creates a new instance of ISubscriptionsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewISubscriptionsRepository(t mockConstructorTestingTNewISubscriptionsRepository) *ISubscriptionsRepository {
+	mock := &ISubscriptionsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
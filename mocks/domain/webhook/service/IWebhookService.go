@@ -0,0 +1,173 @@
+// Code generated by mockery v2.14.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	dto "faceit/domain/webhook/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IWebhookService is an autogenerated mock type for the IWebhookService type
+type IWebhookService struct {
+	mock.Mock
+}
+
+// CreateSubscription provides a mock function with given fields: ctx, subscription
+func (_m *IWebhookService) CreateSubscription(ctx context.Context, subscription *dto.Subscription) (*dto.Subscription, error) {
+	ret := _m.Called(ctx, subscription)
+
+	var r0 *dto.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.Subscription) *dto.Subscription); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.Subscription) error); ok {
+		r1 = rf(ctx, subscription)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeliveries provides a mock function with given fields: ctx, subscriptionID, status, limit
+func (_m *IWebhookService) GetDeliveries(ctx context.Context, subscriptionID int64, status string, limit int64) ([]*dto.Delivery, error) {
+	ret := _m.Called(ctx, subscriptionID, status, limit)
+
+	var r0 []*dto.Delivery
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, int64) []*dto.Delivery); ok {
+		r0 = rf(ctx, subscriptionID, status, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.Delivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, int64) error); ok {
+		r1 = rf(ctx, subscriptionID, status, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscription provides a mock function with given fields: ctx, ID
+func (_m *IWebhookService) GetSubscription(ctx context.Context, ID int64) (*dto.Subscription, error) {
+	ret := _m.Called(ctx, ID)
+
+	var r0 *dto.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *dto.Subscription); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptions provides a mock function with given fields: ctx
+func (_m *IWebhookService) GetSubscriptions(ctx context.Context) ([]*dto.Subscription, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*dto.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context) []*dto.Subscription); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*dto.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveSubscription provides a mock function with given fields: ctx, ID
+func (_m *IWebhookService) RemoveSubscription(ctx context.Context, ID int64) error {
+	ret := _m.Called(ctx, ID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RetryDelivery provides a mock function with given fields: ctx, subscriptionID, deliveryID
+func (_m *IWebhookService) RetryDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) error {
+	ret := _m.Called(ctx, subscriptionID, deliveryID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, subscriptionID, deliveryID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSubscription provides a mock function with given fields: ctx, subscription
+func (_m *IWebhookService) UpdateSubscription(ctx context.Context, subscription *dto.Subscription) (*dto.Subscription, error) {
+	ret := _m.Called(ctx, subscription)
+
+	var r0 *dto.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, *dto.Subscription) *dto.Subscription); ok {
+		r0 = rf(ctx, subscription)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*dto.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *dto.Subscription) error); ok {
+		r1 = rf(ctx, subscription)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+type mockConstructorTestingTNewIWebhookService interface {
+	mock.TestingT
+	Cleanup(func())
+}
+
+// NewIWebhookService creates a new instance of IWebhookService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+func NewIWebhookService(t mockConstructorTestingTNewIWebhookService) *IWebhookService {
+	mock := &IWebhookService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}